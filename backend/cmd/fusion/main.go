@@ -13,13 +13,22 @@ import (
 
 	"github.com/0x2E/fusion/internal/config"
 	"github.com/0x2E/fusion/internal/handler"
+	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"github.com/0x2E/fusion/internal/pkg/secretbox"
 	"github.com/0x2E/fusion/internal/pull"
+	"github.com/0x2E/fusion/internal/resanitize"
+	"github.com/0x2E/fusion/internal/retention"
 	"github.com/0x2E/fusion/internal/store"
+	"github.com/0x2E/fusion/internal/websub"
 	"github.com/gin-gonic/gin"
 	"github.com/mattn/go-isatty"
 	"golang.org/x/sync/errgroup"
 )
 
+// jobDrainTimeout bounds how long shutdown waits for background jobs
+// (refresh-all, imports) before cancelling them.
+const jobDrainTimeout = 20 * time.Second
+
 func main() {
 	if err := run(); err != nil {
 		slog.Error("fatal", "error", err)
@@ -33,6 +42,7 @@ func run() error {
 		return err
 	}
 	setupLogger(cfg)
+	httpc.SetUserAgent(cfg.UserAgent)
 	gin.SetMode(gin.ReleaseMode)
 
 	st, err := store.New(cfg.DBPath)
@@ -41,8 +51,23 @@ func run() error {
 	}
 	defer st.Close()
 
-	puller := pull.New(st, cfg)
-	h, err := handler.New(st, cfg, puller)
+	secrets, err := secretbox.Load(cfg.SecretKey, cfg.SecretKeyFile)
+	if err != nil {
+		return err
+	}
+	st.SetSecretBox(secrets)
+
+	// One subscriber serves the puller (subscribe), the handler (callbacks)
+	// and lease renewal, so they share its state.
+	var subscriber *websub.Subscriber
+	if cfg.PublicURL != "" {
+		subscriber = websub.New(st, cfg)
+	}
+
+	puller := pull.New(st, cfg, subscriber)
+	pruner := retention.New(st, cfg)
+	resanitizer := resanitize.New(st)
+	h, err := handler.New(st, cfg, puller, subscriber)
 	if err != nil {
 		return err
 	}
@@ -57,6 +82,9 @@ func run() error {
 		WriteTimeout:      30 * time.Second,
 		IdleTimeout:       60 * time.Second,
 	}
+	// Shutdown waits for active connections, and event streams only end when
+	// their subscription closes.
+	srv.RegisterOnShutdown(puller.Events().Close)
 
 	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -78,6 +106,29 @@ func run() error {
 		return nil
 	})
 
+	g.Go(func() error {
+		if err := pruner.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		if err := resanitizer.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("re-sanitize job failed", "error", err)
+		}
+		return nil
+	})
+
+	if subscriber != nil {
+		g.Go(func() error {
+			if err := subscriber.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
+			return nil
+		})
+	}
+
 	g.Go(func() error {
 		<-ctx.Done()
 		slog.Info("shutting down")
@@ -88,6 +139,13 @@ func run() error {
 			slog.Error("failed to shutdown server", "error", err)
 		}
 
+		// No new jobs arrive once the server is down; let running ones finish.
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), jobDrainTimeout)
+		defer drainCancel()
+		if err := h.Shutdown(drainCtx); err != nil {
+			slog.Warn("cancelled unfinished background jobs", "error", err)
+		}
+
 		return nil
 	})
 
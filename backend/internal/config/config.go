@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -17,12 +18,40 @@ type Config struct {
 	CORSAllowedOrigins []string // Allowed Origins for CORS. Empty means allow all.
 	TrustedProxies     []string // Trusted reverse proxies for client IP resolution. Empty disables proxy trust.
 	AllowPrivateFeeds  bool     // Allow pulling private/localhost feed URLs.
+	PublicURL          string   // Externally reachable base URL; enables WebSub push subscriptions when set.
+
+	UserAgent string // User-Agent for outgoing requests (default: fusion/1.0 (+https://github.com/0x2E/fusion))
+
+	SecretKey     string // Key encrypting per-feed credentials at rest; generated and kept in SecretKeyFile when empty
+	SecretKeyFile string // Generated key location (default: secret.key next to the database)
+
+	MediaProxy       bool   // Serve item images/videos through /api/media (default: false)
+	MediaProxySecret string // HMAC key for proxied URLs; generated and kept in MediaCacheDir when empty
+	MediaCacheDir    string // Disk cache for proxied media (default: media-cache next to the database)
+	MediaCacheSize   int    // Max cache size in MiB (default: 512)
+	MediaMaxSize     int    // Max size of one media file in MiB (default: 20)
 
 	PullInterval    int // Pull interval in seconds (default: 1800 = 30 min)
 	PullTimeout     int // Request timeout in seconds (default: 30)
 	PullConcurrency int // Max concurrent pulls (default: 10)
 	PullMaxBackoff  int // Global max scheduling delay in seconds (default: 172800 = 48 hours)
 
+	PullAdaptive    bool // Derive each feed's interval from its publish history (default: false)
+	PullMinInterval int  // Lower bound for adaptive intervals in seconds (default: 300 = 5 min)
+	PullMaxInterval int  // Upper bound for adaptive intervals in seconds (default: 86400 = 24 hours)
+
+	PullRedirectThreshold int // Consecutive checks ending at the same permanent redirect before the feed link is updated (default: 3)
+	PullStartupJitter     int // Spread feeds overdue at startup over this many seconds (default: 300 = 5 min)
+	PullHostConcurrency   int // Max concurrent requests to one host (default: 2)
+	PullHostDelay         int // Min seconds between request starts to one host (default: 1)
+
+	JobWorkers int // Max background jobs (refresh-all, imports, initial pulls) running at once (default: 2)
+
+	RetentionDays          int // Prune read items older than N days; 0 keeps forever (default: 0)
+	RetentionMaxItems      int // Prune read items beyond the newest N per feed; 0 is unlimited (default: 0)
+	RetentionInterval      int // Seconds between retention runs (default: 86400 = 24 hours)
+	RetentionTombstoneDays int // Days to keep GUIDs of pruned or rule-dropped items after pulls stop returning them (default: 180)
+
 	LoginRateLimit int // Max failed login attempts per window (default: 10)
 	LoginWindow    int // Login rate limit window in seconds (default: 60)
 	LoginBlock     int // Login block duration in seconds (default: 300)
@@ -97,6 +126,62 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	pullAdaptive, err := getEnvBool("FUSION_PULL_ADAPTIVE", false)
+	if err != nil {
+		return nil, err
+	}
+	pullMinInterval, err := getEnvInt("FUSION_PULL_MIN_INTERVAL", 300, 1)
+	if err != nil {
+		return nil, err
+	}
+	pullMaxInterval, err := getEnvInt("FUSION_PULL_MAX_INTERVAL", 86400, 1)
+	if err != nil {
+		return nil, err
+	}
+	if pullMinInterval > pullMaxInterval {
+		return nil, fmt.Errorf("FUSION_PULL_MIN_INTERVAL must not exceed FUSION_PULL_MAX_INTERVAL")
+	}
+	pullRedirectThreshold, err := getEnvInt("FUSION_PULL_REDIRECT_THRESHOLD", 3, 1)
+	if err != nil {
+		return nil, err
+	}
+	pullStartupJitter, err := getEnvInt("FUSION_PULL_STARTUP_JITTER", 300, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pullHostConcurrency, err := getEnvInt("FUSION_PULL_HOST_CONCURRENCY", 2, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	pullHostDelay, err := getEnvInt("FUSION_PULL_HOST_DELAY", 1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	jobWorkers, err := getEnvInt("FUSION_JOB_WORKERS", 2, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	retentionDays, err := getEnvInt("FUSION_RETENTION_DAYS", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	retentionMaxItems, err := getEnvInt("FUSION_RETENTION_MAX_ITEMS", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	retentionInterval, err := getEnvInt("FUSION_RETENTION_INTERVAL", 86400, 60)
+	if err != nil {
+		return nil, err
+	}
+	retentionTombstoneDays, err := getEnvInt("FUSION_RETENTION_TOMBSTONE_DAYS", 180, 1)
+	if err != nil {
+		return nil, err
+	}
+
 	loginRateLimit, err := getEnvInt("FUSION_LOGIN_RATE_LIMIT", 10, 1)
 	if err != nil {
 		return nil, err
@@ -118,6 +203,32 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	publicURL, err := parsePublicURL(getEnvString("FUSION_PUBLIC_URL", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FUSION_PUBLIC_URL: %w", err)
+	}
+
+	mediaProxy, err := getEnvBool("FUSION_MEDIA_PROXY", false)
+	if err != nil {
+		return nil, err
+	}
+	mediaCacheSize, err := getEnvInt("FUSION_MEDIA_CACHE_SIZE", 512, 1)
+	if err != nil {
+		return nil, err
+	}
+	mediaMaxSize, err := getEnvInt("FUSION_MEDIA_MAX_SIZE", 20, 1)
+	if err != nil {
+		return nil, err
+	}
+	if mediaMaxSize > mediaCacheSize {
+		return nil, fmt.Errorf("FUSION_MEDIA_MAX_SIZE must not exceed FUSION_MEDIA_CACHE_SIZE")
+	}
+
+	userAgent := strings.TrimSpace(getEnvString("FUSION_USER_AGENT", ""))
+	if strings.ContainsAny(userAgent, "\r\n") {
+		return nil, fmt.Errorf("FUSION_USER_AGENT must be a single line")
+	}
+
 	logLevel := os.Getenv("FUSION_LOG_LEVEL")
 	if logLevel == "" {
 		logLevel = "INFO"
@@ -129,22 +240,43 @@ func Load() (*Config, error) {
 	}
 
 	return &Config{
-		DBPath:             dbPath,
-		Password:           password,
-		Port:               parsedPort,
-		FeverUsername:      getEnvString("FUSION_FEVER_USERNAME", "fusion"),
-		CORSAllowedOrigins: corsAllowedOrigins,
-		TrustedProxies:     trustedProxies,
-		AllowPrivateFeeds:  allowPrivateFeeds,
-		PullInterval:       pullInterval,
-		PullTimeout:        pullTimeout,
-		PullConcurrency:    pullConcurrency,
-		PullMaxBackoff:     pullMaxBackoff,
-		LoginRateLimit:     loginRateLimit,
-		LoginWindow:        loginWindow,
-		LoginBlock:         loginBlock,
-		LogLevel:           logLevel,
-		LogFormat:          logFormat,
+		DBPath:                 dbPath,
+		Password:               password,
+		Port:                   parsedPort,
+		FeverUsername:          getEnvString("FUSION_FEVER_USERNAME", "fusion"),
+		CORSAllowedOrigins:     corsAllowedOrigins,
+		TrustedProxies:         trustedProxies,
+		AllowPrivateFeeds:      allowPrivateFeeds,
+		PublicURL:              publicURL,
+		UserAgent:              userAgent,
+		SecretKey:              getEnvString("FUSION_SECRET_KEY", ""),
+		SecretKeyFile:          getEnvString("FUSION_SECRET_KEY_FILE", filepath.Join(filepath.Dir(dbPath), "secret.key")),
+		MediaProxy:             mediaProxy,
+		MediaProxySecret:       getEnvString("FUSION_MEDIA_PROXY_SECRET", ""),
+		MediaCacheDir:          getEnvString("FUSION_MEDIA_CACHE_DIR", filepath.Join(filepath.Dir(dbPath), "media-cache")),
+		MediaCacheSize:         mediaCacheSize,
+		MediaMaxSize:           mediaMaxSize,
+		PullInterval:           pullInterval,
+		PullTimeout:            pullTimeout,
+		PullConcurrency:        pullConcurrency,
+		PullMaxBackoff:         pullMaxBackoff,
+		PullAdaptive:           pullAdaptive,
+		PullMinInterval:        pullMinInterval,
+		PullMaxInterval:        pullMaxInterval,
+		PullRedirectThreshold:  pullRedirectThreshold,
+		PullStartupJitter:      pullStartupJitter,
+		PullHostConcurrency:    pullHostConcurrency,
+		PullHostDelay:          pullHostDelay,
+		JobWorkers:             jobWorkers,
+		RetentionDays:          retentionDays,
+		RetentionMaxItems:      retentionMaxItems,
+		RetentionInterval:      retentionInterval,
+		RetentionTombstoneDays: retentionTombstoneDays,
+		LoginRateLimit:         loginRateLimit,
+		LoginWindow:            loginWindow,
+		LoginBlock:             loginBlock,
+		LogLevel:               logLevel,
+		LogFormat:              logFormat,
 
 		OIDCIssuer:       os.Getenv("FUSION_OIDC_ISSUER"),
 		OIDCClientID:     os.Getenv("FUSION_OIDC_CLIENT_ID"),
@@ -216,6 +348,24 @@ func parsePort(val string) (int, error) {
 	return strconv.Atoi(port)
 }
 
+// parsePublicURL validates an absolute http(s) base URL and strips the
+// trailing slash. An empty value is allowed.
+func parsePublicURL(val string) (string, error) {
+	if val == "" {
+		return "", nil
+	}
+
+	parsed, err := url.Parse(val)
+	if err != nil {
+		return "", err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("must be an absolute http(s) URL")
+	}
+
+	return strings.TrimRight(val, "/"), nil
+}
+
 func parseCSVEnv(val string) []string {
 	if strings.TrimSpace(val) == "" {
 		return nil
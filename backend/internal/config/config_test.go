@@ -107,3 +107,197 @@ func TestLoadRejectsInvalidFusionPort(t *testing.T) {
 		t.Fatalf("expected error to mention invalid FUSION_PORT, got %v", err)
 	}
 }
+
+func TestLoadRetentionSettings(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.RetentionDays != 0 || cfg.RetentionMaxItems != 0 || cfg.RetentionInterval != 86400 || cfg.RetentionTombstoneDays != 180 {
+		t.Fatalf("unexpected retention defaults: days=%d max_items=%d interval=%d tombstone_days=%d", cfg.RetentionDays, cfg.RetentionMaxItems, cfg.RetentionInterval, cfg.RetentionTombstoneDays)
+	}
+
+	t.Setenv("FUSION_RETENTION_DAYS", "30")
+	t.Setenv("FUSION_RETENTION_MAX_ITEMS", "500")
+	t.Setenv("FUSION_RETENTION_TOMBSTONE_DAYS", "90")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.RetentionDays != 30 || cfg.RetentionMaxItems != 500 || cfg.RetentionTombstoneDays != 90 {
+		t.Fatalf("unexpected retention settings: days=%d max_items=%d tombstone_days=%d", cfg.RetentionDays, cfg.RetentionMaxItems, cfg.RetentionTombstoneDays)
+	}
+
+	t.Setenv("FUSION_RETENTION_DAYS", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for negative FUSION_RETENTION_DAYS")
+	}
+}
+
+func TestLoadAdaptivePullSettings(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.PullAdaptive || cfg.PullMinInterval != 300 || cfg.PullMaxInterval != 86400 {
+		t.Fatalf("unexpected adaptive defaults: enabled=%v min=%d max=%d", cfg.PullAdaptive, cfg.PullMinInterval, cfg.PullMaxInterval)
+	}
+
+	t.Setenv("FUSION_PULL_ADAPTIVE", "true")
+	t.Setenv("FUSION_PULL_MIN_INTERVAL", "600")
+	t.Setenv("FUSION_PULL_MAX_INTERVAL", "300")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error when min interval exceeds max interval")
+	}
+}
+
+func TestLoadPullRedirectThreshold(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.PullRedirectThreshold != 3 {
+		t.Fatalf("PullRedirectThreshold = %d, want default 3", cfg.PullRedirectThreshold)
+	}
+
+	t.Setenv("FUSION_PULL_REDIRECT_THRESHOLD", "0")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for FUSION_PULL_REDIRECT_THRESHOLD below 1")
+	}
+}
+
+func TestLoadPullStartupJitter(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.PullStartupJitter != 300 {
+		t.Fatalf("PullStartupJitter = %d, want default 300", cfg.PullStartupJitter)
+	}
+
+	t.Setenv("FUSION_PULL_STARTUP_JITTER", "0")
+	if cfg, err := Load(); err != nil || cfg.PullStartupJitter != 0 {
+		t.Fatalf("expected 0 to disable jitter, got %v, %v", cfg, err)
+	}
+
+	t.Setenv("FUSION_PULL_STARTUP_JITTER", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for negative FUSION_PULL_STARTUP_JITTER")
+	}
+}
+
+func TestLoadPublicURL(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+	t.Setenv("FUSION_PUBLIC_URL", "https://reader.example.com/")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.PublicURL != "https://reader.example.com" {
+		t.Fatalf("PublicURL = %q, want trailing slash trimmed", cfg.PublicURL)
+	}
+
+	t.Setenv("FUSION_PUBLIC_URL", "reader.example.com")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for relative FUSION_PUBLIC_URL")
+	}
+}
+
+func TestLoadUserAgent(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+	t.Setenv("FUSION_USER_AGENT", " fusion/2.0 (+https://reader.example.com/contact) ")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.UserAgent != "fusion/2.0 (+https://reader.example.com/contact)" {
+		t.Fatalf("UserAgent = %q", cfg.UserAgent)
+	}
+
+	t.Setenv("FUSION_USER_AGENT", "fusion\r\nX-Evil: 1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for multi-line FUSION_USER_AGENT")
+	}
+}
+
+func TestLoadSecretKeySettings(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+	t.Setenv("FUSION_DB_PATH", "/data/fusion.db")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.SecretKey != "" || cfg.SecretKeyFile != "/data/secret.key" {
+		t.Fatalf("unexpected secret key defaults: key=%q file=%q", cfg.SecretKey, cfg.SecretKeyFile)
+	}
+}
+
+func TestLoadMediaProxySettings(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+	t.Setenv("FUSION_DB_PATH", "/data/fusion.db")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.MediaProxy || cfg.MediaCacheDir != "/data/media-cache" || cfg.MediaCacheSize != 512 || cfg.MediaMaxSize != 20 {
+		t.Fatalf("unexpected media defaults: enabled=%v dir=%q cache=%d max=%d", cfg.MediaProxy, cfg.MediaCacheDir, cfg.MediaCacheSize, cfg.MediaMaxSize)
+	}
+
+	t.Setenv("FUSION_MEDIA_CACHE_SIZE", "10")
+	t.Setenv("FUSION_MEDIA_MAX_SIZE", "20")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error when max media size exceeds the cache size")
+	}
+}
+
+func TestLoadPullHostLimits(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.PullHostConcurrency != 2 || cfg.PullHostDelay != 1 {
+		t.Fatalf("host limits = %d, %d; want defaults 2, 1", cfg.PullHostConcurrency, cfg.PullHostDelay)
+	}
+
+	t.Setenv("FUSION_PULL_HOST_CONCURRENCY", "4")
+	t.Setenv("FUSION_PULL_HOST_DELAY", "0")
+	if cfg, err := Load(); err != nil || cfg.PullHostConcurrency != 4 || cfg.PullHostDelay != 0 {
+		t.Fatalf("expected overrides 4, 0, got %v, %v", cfg, err)
+	}
+
+	t.Setenv("FUSION_PULL_HOST_CONCURRENCY", "0")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for FUSION_PULL_HOST_CONCURRENCY below 1")
+	}
+}
+
+func TestLoadJobWorkers(t *testing.T) {
+	t.Setenv("FUSION_PASSWORD", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.JobWorkers != 2 {
+		t.Fatalf("JobWorkers = %d, want default 2", cfg.JobWorkers)
+	}
+
+	t.Setenv("FUSION_JOB_WORKERS", "0")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for FUSION_JOB_WORKERS below 1")
+	}
+}
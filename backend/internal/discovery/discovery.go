@@ -0,0 +1,122 @@
+// Package discovery maps URLs of well-known services to the feeds they
+// publish and keeps the candidates that parse as feeds. Generic discovery of
+// page links is left to feedfinder.
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	maxBodyBytes = 5 << 20
+	checkLimit   = 4
+)
+
+type Feed struct {
+	Title string
+	Link  string
+	// Type labels feeds produced by a Rule, e.g. TypeGitHubReleases.
+	Type string
+}
+
+// Options controls how candidate feeds are fetched.
+type Options struct {
+	// Client performs all requests; use the SSRF-guarded httpc client.
+	Client *http.Client
+	// UserAgent overrides httpc.UserAgent() when set.
+	UserAgent string
+	// Rules maps service URLs to their feeds; DefaultRules when nil.
+	Rules *Registry
+}
+
+// FindByRules returns the feeds of the first rule matching target that parse
+// as feeds, in rule order. It returns nil when no rule matches or none of the
+// candidates is a feed.
+func FindByRules(ctx context.Context, target string, opts Options) ([]Feed, error) {
+	if opts.Client == nil {
+		return nil, errors.New("discovery requires a client")
+	}
+
+	rules := opts.Rules
+	if rules == nil {
+		rules = DefaultRules
+	}
+	candidates := rules.Match(target)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	return checkCandidates(ctx, candidates, opts), nil
+}
+
+// checkCandidates fetches candidates concurrently and keeps those that parse
+// as feeds, preserving candidate order. Kept candidates get the feed's title.
+func checkCandidates(ctx context.Context, candidates []Feed, opts Options) []Feed {
+	found := make([]*Feed, len(candidates))
+	var mu sync.Mutex
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(checkLimit)
+	for i, candidate := range candidates {
+		g.Go(func() error {
+			body, err := fetch(ctx, candidate.Link, opts)
+			if err != nil {
+				return nil
+			}
+			if title, ok := parseFeedTitle(body); ok {
+				candidate.Title = title
+				mu.Lock()
+				found[i] = &candidate
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	feeds := make([]Feed, 0, len(found))
+	for _, feed := range found {
+		if feed != nil {
+			feeds = append(feeds, *feed)
+		}
+	}
+	return feeds
+}
+
+func fetch(ctx context.Context, target string, opts Options) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpc.SetDefaultHeaders(req)
+	httpc.SetFeedUserAgent(req, opts.UserAgent)
+
+	resp, err := opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+}
+
+func parseFeedTitle(body []byte) (string, bool) {
+	parsed, err := gofeed.NewParser().Parse(bytes.NewReader(body))
+	if err != nil || parsed == nil {
+		return "", false
+	}
+	return strings.TrimSpace(parsed.Title), true
+}
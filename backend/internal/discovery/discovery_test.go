@@ -0,0 +1,82 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+const rssDoc = `<?xml version="1.0"?><rss version="2.0"><channel><title>Demo</title></channel></rss>`
+
+func newTestClient() *http.Client {
+	return &http.Client{Timeout: 5 * time.Second}
+}
+
+func TestFindByRulesKeepsParsedCandidates(t *testing.T) {
+	var mu sync.Mutex
+	var pageFetched bool
+	agents := map[string]string{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents[r.URL.Path] = r.UserAgent()
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/repo/releases.atom":
+			_, _ = w.Write([]byte(rssDoc))
+		case "/repo":
+			mu.Lock()
+			pageFetched = true
+			mu.Unlock()
+			_, _ = w.Write([]byte(`<html></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	rules := NewRegistry(Rule{Name: "test", Match: func(u *url.URL) []Feed {
+		if u.Path != "/repo" {
+			return nil
+		}
+		return []Feed{
+			{Link: server.URL + "/repo/releases.atom", Type: "releases"},
+			{Link: server.URL + "/repo/missing.atom", Type: "missing"},
+		}
+	}})
+
+	opts := Options{Client: newTestClient(), UserAgent: "custom/1.0", Rules: rules}
+	feeds, err := FindByRules(context.Background(), server.URL+"/repo", opts)
+	if err != nil {
+		t.Fatalf("FindByRules() failed: %v", err)
+	}
+	want := []Feed{{Title: "Demo", Link: server.URL + "/repo/releases.atom", Type: "releases"}}
+	if !reflect.DeepEqual(feeds, want) {
+		t.Fatalf("FindByRules() = %+v, want %+v", feeds, want)
+	}
+	if pageFetched {
+		t.Fatal("page was fetched although rules only produce candidates")
+	}
+	for path, ua := range agents {
+		if ua != "custom/1.0" {
+			t.Errorf("%s: User-Agent = %q", path, ua)
+		}
+	}
+}
+
+func TestFindByRulesNoMatch(t *testing.T) {
+	feeds, err := FindByRules(context.Background(), "https://example.com/blog", Options{Client: newTestClient(), Rules: NewRegistry()})
+	if err != nil {
+		t.Fatalf("FindByRules() failed: %v", err)
+	}
+	if feeds != nil {
+		t.Fatalf("FindByRules() = %+v, want nil", feeds)
+	}
+}
@@ -0,0 +1,233 @@
+package discovery
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Candidate types produced by the built-in rules. Feeds found by feedfinder
+// have no type.
+const (
+	TypeYouTubeChannel    = "youtube_channel"
+	TypeYouTubePlaylist   = "youtube_playlist"
+	TypeYouTubeUser       = "youtube_user"
+	TypeSubreddit         = "reddit_subreddit"
+	TypeRedditUser        = "reddit_user"
+	TypeGitHubReleases    = "github_releases"
+	TypeGitHubCommits     = "github_commits"
+	TypeGitHubTags        = "github_tags"
+	TypeFediverseProfile  = "fediverse_profile"
+	TypeMediumUser        = "medium_user"
+	TypeMediumPublication = "medium_publication"
+	TypeMediumTag         = "medium_tag"
+	TypeSubstack          = "substack"
+)
+
+// Rule maps a URL shape to the feeds a service publishes for it, from the URL
+// alone. Match returns nil when the URL is not of that shape.
+type Rule struct {
+	Name  string
+	Match func(u *url.URL) []Feed
+}
+
+// Registry is an ordered set of rules. The first rule that matches a URL
+// wins, so service-specific rules must come before generic ones.
+type Registry struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+func NewRegistry(rules ...Rule) *Registry {
+	return &Registry{rules: rules}
+}
+
+// DefaultRules holds the built-in rules used when Options.Rules is nil.
+var DefaultRules = NewRegistry(
+	Rule{Name: "youtube", Match: matchYouTube},
+	Rule{Name: "reddit", Match: matchReddit},
+	Rule{Name: "github", Match: matchGitHub},
+	Rule{Name: "medium", Match: matchMedium},
+	Rule{Name: "substack", Match: matchSubstack},
+	Rule{Name: "fediverse", Match: matchFediverse},
+)
+
+// Register appends rule, so it is tried after every rule already in r.
+func (r *Registry) Register(rule Rule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = append(r.rules, rule)
+}
+
+// Match returns the candidate feeds of the first rule that matches target.
+// Candidates are not fetched; FindByRules verifies them.
+func (r *Registry) Match(target string) []Feed {
+	u, err := url.Parse(strings.TrimSpace(target))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	rules := slices.Clone(r.rules)
+	r.mu.RUnlock()
+
+	for _, rule := range rules {
+		if feeds := rule.Match(u); len(feeds) > 0 {
+			return feeds
+		}
+	}
+	return nil
+}
+
+// siteHost strips the subdomains services use for alternate front-ends.
+func siteHost(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "old.", "new."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	return host
+}
+
+func pathSegments(u *url.URL) []string {
+	var segments []string
+	for segment := range strings.SplitSeq(u.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+func matchYouTube(u *url.URL) []Feed {
+	if siteHost(u) != "youtube.com" {
+		return nil
+	}
+	const base = "https://www.youtube.com/feeds/videos.xml?"
+
+	segments := pathSegments(u)
+	if len(segments) >= 2 && segments[0] == "channel" && strings.HasPrefix(segments[1], "UC") {
+		return []Feed{{Link: base + url.Values{"channel_id": {segments[1]}}.Encode(), Type: TypeYouTubeChannel}}
+	}
+	if list := u.Query().Get("list"); list != "" {
+		return []Feed{{Link: base + url.Values{"playlist_id": {list}}.Encode(), Type: TypeYouTubePlaylist}}
+	}
+	if len(segments) >= 2 && segments[0] == "user" {
+		return []Feed{{Link: base + url.Values{"user": {segments[1]}}.Encode(), Type: TypeYouTubeUser}}
+	}
+	// @handle and /c/ pages advertise the channel feed in their HTML.
+	return nil
+}
+
+func matchReddit(u *url.URL) []Feed {
+	if siteHost(u) != "reddit.com" {
+		return nil
+	}
+
+	segments := pathSegments(u)
+	if len(segments) < 2 || strings.HasSuffix(u.Path, ".rss") {
+		return nil
+	}
+	name := segments[1]
+	switch segments[0] {
+	case "r":
+		return []Feed{{Link: "https://www.reddit.com/r/" + url.PathEscape(name) + "/.rss", Type: TypeSubreddit}}
+	case "u", "user":
+		return []Feed{{Link: "https://www.reddit.com/user/" + url.PathEscape(name) + "/.rss", Type: TypeRedditUser}}
+	}
+	return nil
+}
+
+// githubReserved are top-level GitHub paths that are not user or org names.
+var githubReserved = []string{
+	"about", "apps", "collections", "enterprise", "events", "explore", "features", "login",
+	"marketplace", "new", "notifications", "orgs", "pricing", "search", "settings",
+	"sponsors", "topics", "trending",
+}
+
+func matchGitHub(u *url.URL) []Feed {
+	if siteHost(u) != "github.com" {
+		return nil
+	}
+
+	segments := pathSegments(u)
+	if len(segments) < 2 || slices.Contains(githubReserved, strings.ToLower(segments[0])) {
+		return nil
+	}
+	if strings.HasSuffix(u.Path, ".atom") {
+		return nil
+	}
+	repo := (&url.URL{Scheme: "https", Host: "github.com"}).JoinPath(segments[0], strings.TrimSuffix(segments[1], ".git"))
+	return []Feed{
+		{Link: repo.JoinPath("releases.atom").String(), Type: TypeGitHubReleases},
+		{Link: repo.JoinPath("commits.atom").String(), Type: TypeGitHubCommits},
+		{Link: repo.JoinPath("tags.atom").String(), Type: TypeGitHubTags},
+	}
+}
+
+// mediumReserved are top-level medium.com paths that are not publications.
+var mediumReserved = []string{"feed", "m", "me", "membership", "plans", "search", "tag", "topics"}
+
+func matchMedium(u *url.URL) []Feed {
+	host := siteHost(u)
+	if host != "medium.com" {
+		// Custom subdomains (name.medium.com) serve their feed at /feed.
+		sub, ok := strings.CutSuffix(host, ".medium.com")
+		if !ok || sub == "" || strings.HasSuffix(u.Path, "/feed") {
+			return nil
+		}
+		return []Feed{{Link: "https://" + host + "/feed", Type: TypeMediumUser}}
+	}
+
+	segments := pathSegments(u)
+	if len(segments) == 0 {
+		return nil
+	}
+	feed := &url.URL{Scheme: "https", Host: "medium.com", Path: "/feed"}
+	switch first := segments[0]; {
+	case strings.HasPrefix(first, "@"):
+		return []Feed{{Link: feed.JoinPath(first).String(), Type: TypeMediumUser}}
+	case first == "tag" && len(segments) >= 2:
+		return []Feed{{Link: feed.JoinPath("tag", segments[1]).String(), Type: TypeMediumTag}}
+	case !slices.Contains(mediumReserved, strings.ToLower(first)):
+		return []Feed{{Link: feed.JoinPath(first).String(), Type: TypeMediumPublication}}
+	}
+	return nil
+}
+
+func matchSubstack(u *url.URL) []Feed {
+	host := siteHost(u)
+	sub, ok := strings.CutSuffix(host, ".substack.com")
+	if !ok || sub == "" || strings.Contains(sub, ".") || strings.HasSuffix(u.Path, "/feed") {
+		return nil
+	}
+	return []Feed{{Link: "https://" + host + "/feed", Type: TypeSubstack}}
+}
+
+// serviceHosts use /@name paths of their own and are never fediverse servers.
+var serviceHosts = []string{"youtube.com", "reddit.com", "github.com", "medium.com", "substack.com"}
+
+// fediverseUser matches Mastodon usernames.
+var fediverseUser = regexp.MustCompile(`^[A-Za-z0-9_]+([A-Za-z0-9_.-]*[A-Za-z0-9_])?$`)
+
+// matchFediverse maps Mastodon-style profile URLs (/@user) to the profile's
+// RSS feed on the same scheme and host. Remote accounts (/@user@domain) are
+// not mapped, since their feed lives on a host the user did not enter. Any
+// host may run such a server, so it must stay the last built-in rule.
+func matchFediverse(u *url.URL) []Feed {
+	if slices.Contains(serviceHosts, siteHost(u)) {
+		return nil
+	}
+	segments := pathSegments(u)
+	if len(segments) != 1 {
+		return nil
+	}
+	user, ok := strings.CutPrefix(segments[0], "@")
+	if !ok || !fediverseUser.MatchString(user) || strings.HasSuffix(user, ".rss") {
+		return nil
+	}
+
+	profile := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/@" + user + ".rss"}
+	return []Feed{{Link: profile.String(), Type: TypeFediverseProfile}}
+}
@@ -0,0 +1,109 @@
+package discovery
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultRulesMatch(t *testing.T) {
+	tests := []struct {
+		url  string
+		want []Feed
+	}{
+		{
+			url:  "https://www.youtube.com/channel/UCabc123",
+			want: []Feed{{Link: "https://www.youtube.com/feeds/videos.xml?channel_id=UCabc123", Type: TypeYouTubeChannel}},
+		},
+		{
+			url:  "https://youtube.com/playlist?list=PLxyz",
+			want: []Feed{{Link: "https://www.youtube.com/feeds/videos.xml?playlist_id=PLxyz", Type: TypeYouTubePlaylist}},
+		},
+		{
+			url:  "https://m.youtube.com/user/legacy",
+			want: []Feed{{Link: "https://www.youtube.com/feeds/videos.xml?user=legacy", Type: TypeYouTubeUser}},
+		},
+		{url: "https://www.youtube.com/@handle"},
+		{
+			url:  "https://old.reddit.com/r/golang/top/",
+			want: []Feed{{Link: "https://www.reddit.com/r/golang/.rss", Type: TypeSubreddit}},
+		},
+		{
+			url:  "https://www.reddit.com/u/spez",
+			want: []Feed{{Link: "https://www.reddit.com/user/spez/.rss", Type: TypeRedditUser}},
+		},
+		{
+			url: "https://github.com/0x2E/fusion.git",
+			want: []Feed{
+				{Link: "https://github.com/0x2E/fusion/releases.atom", Type: TypeGitHubReleases},
+				{Link: "https://github.com/0x2E/fusion/commits.atom", Type: TypeGitHubCommits},
+				{Link: "https://github.com/0x2E/fusion/tags.atom", Type: TypeGitHubTags},
+			},
+		},
+		{url: "https://github.com/settings/profile"},
+		{url: "https://github.com/0x2E/fusion/releases.atom"},
+		{
+			url:  "https://mastodon.social/@Gargron",
+			want: []Feed{{Link: "https://mastodon.social/@Gargron.rss", Type: TypeFediverseProfile}},
+		},
+		{
+			url:  "http://social.lan:8080/@alice",
+			want: []Feed{{Link: "http://social.lan:8080/@alice.rss", Type: TypeFediverseProfile}},
+		},
+		{url: "https://mastodon.social/@someone@fosstodon.org"},
+		{url: "https://a.example/@u@b.example"},
+		{url: "https://mastodon.social/@Gargron/123456"},
+		{url: "https://mastodon.social/@Gargron.rss"},
+		{url: "https://example.com/@"},
+		{url: "https://example.com/@bad%20name"},
+		{
+			url:  "https://medium.com/@author/some-post-1a2b",
+			want: []Feed{{Link: "https://medium.com/feed/@author", Type: TypeMediumUser}},
+		},
+		{
+			url:  "https://medium.com/tag/golang",
+			want: []Feed{{Link: "https://medium.com/feed/tag/golang", Type: TypeMediumTag}},
+		},
+		{
+			url:  "https://medium.com/some-publication/a-story",
+			want: []Feed{{Link: "https://medium.com/feed/some-publication", Type: TypeMediumPublication}},
+		},
+		{
+			url:  "https://writer.medium.com/",
+			want: []Feed{{Link: "https://writer.medium.com/feed", Type: TypeMediumUser}},
+		},
+		{
+			url:  "https://newsletter.substack.com/p/a-post",
+			want: []Feed{{Link: "https://newsletter.substack.com/feed", Type: TypeSubstack}},
+		},
+		{url: "https://substack.com/"},
+		{url: "https://example.com/blog"},
+		{url: "ftp://github.com/0x2E/fusion"},
+	}
+
+	for _, tt := range tests {
+		got := DefaultRules.Match(tt.url)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) = %+v, want %+v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryFirstMatchWins(t *testing.T) {
+	rules := NewRegistry(Rule{Name: "first", Match: func(u *url.URL) []Feed {
+		if u.Host != "example.com" {
+			return nil
+		}
+		return []Feed{{Link: "https://example.com/first.xml", Type: "first"}}
+	}})
+	rules.Register(Rule{Name: "second", Match: func(u *url.URL) []Feed {
+		return []Feed{{Link: "https://" + u.Host + "/second.xml", Type: "second"}}
+	}})
+
+	if got := rules.Match("https://example.com/"); len(got) != 1 || got[0].Type != "first" {
+		t.Fatalf("Match(example.com) = %+v, want first rule", got)
+	}
+	if got := rules.Match("https://other.test/"); len(got) != 1 || got[0].Type != "second" {
+		t.Fatalf("Match(other.test) = %+v, want registered rule", got)
+	}
+}
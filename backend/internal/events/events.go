@@ -0,0 +1,155 @@
+// Package events fans out live notifications (pull progress, new items,
+// unread counts) to subscribers such as the SSE endpoint.
+package events
+
+import (
+	"sync"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// Event types, used as SSE event names.
+const (
+	PullStarted        = "pull.started"
+	PullNotModified    = "pull.not_modified"
+	PullSucceeded      = "pull.succeeded"
+	PullFailed         = "pull.failed"
+	RefreshAllProgress = "refresh_all.progress"
+	ItemsCreated       = "items.created"
+	UnreadCounts       = "unread.counts"
+)
+
+// subscriberBuffer is the number of events a subscriber may lag behind
+// before it is dropped.
+const subscriberBuffer = 64
+
+// Event is one notification; Data is sent to clients as JSON.
+type Event struct {
+	Type string
+	Data any
+}
+
+// Pull is the payload of the pull.* events.
+type Pull struct {
+	FeedID     int64  `json:"feed_id"`
+	FeedName   string `json:"feed_name"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	NewItems   int64  `json:"new_items,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// RefreshProgress is the payload of refresh_all.progress. Skipped counts feeds
+// on hosts blocked by Retry-After; the run is over when Done equals Total.
+type RefreshProgress struct {
+	Done    int `json:"done"`
+	Total   int `json:"total"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// Items is the payload of items.created. Source is "pull" or "websub".
+type Items struct {
+	FeedID int64  `json:"feed_id"`
+	Count  int    `json:"count"`
+	Source string `json:"source"`
+}
+
+// Unread is the payload of unread.counts: feeds without unread items are
+// left out.
+type Unread struct {
+	Total int64                    `json:"total"`
+	Feeds []*model.FeedUnreadCount `json:"feeds"`
+}
+
+// NewUnread sums per-feed counts into an Unread payload.
+func NewUnread(feeds []*model.FeedUnreadCount) Unread {
+	u := Unread{Feeds: feeds}
+	for _, f := range feeds {
+		u.Total += f.UnreadCount
+	}
+	return u
+}
+
+// Bus delivers published events to every subscriber. Publishing never blocks:
+// a subscriber that falls subscriberBuffer events behind is dropped and its
+// channel closed, so clients reconnect and resync instead of missing events
+// silently. A nil Bus drops everything.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	closed bool
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving events published from now on, and a
+// func ending the subscription. After Close the channel is already closed.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	if b.closed {
+		close(ch)
+	} else {
+		b.subs[ch] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.drop(ch)
+	}
+}
+
+// Publish sends e to all subscribers.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			b.drop(ch)
+		}
+	}
+}
+
+// Subscribers reports how many subscriptions are open, so publishers can skip
+// building payloads nobody receives.
+func (b *Bus) Subscribers() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close ends every subscription, e.g. so long-lived event streams return
+// during server shutdown. Later subscriptions end immediately.
+func (b *Bus) Close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subs {
+		b.drop(ch)
+	}
+}
+
+func (b *Bus) drop(ch chan Event) {
+	if _, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
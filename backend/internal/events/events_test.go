@@ -0,0 +1,60 @@
+package events
+
+import "testing"
+
+func TestBusFansOutAndDropsSlowSubscribers(t *testing.T) {
+	b := NewBus()
+	fast, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	slow, _ := b.Subscribe()
+
+	for i := range subscriberBuffer + 1 {
+		b.Publish(Event{Type: PullStarted, Data: Pull{FeedID: int64(i)}})
+		if e := <-fast; e.Data.(Pull).FeedID != int64(i) {
+			t.Fatalf("fast subscriber got %+v, want feed %d", e, i)
+		}
+	}
+
+	// The slow subscriber got the buffered events, then its channel closed.
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Fatalf("slow subscriber received %d events, want %d", received, subscriberBuffer)
+	}
+	if n := b.Subscribers(); n != 1 {
+		t.Fatalf("Subscribers() = %d, want 1", n)
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if n := b.Subscribers(); n != 0 {
+		t.Fatalf("Subscribers() after unsubscribe = %d, want 0", n)
+	}
+
+	var nilBus *Bus
+	nilBus.Publish(Event{Type: PullStarted})
+}
+
+func TestBusCloseEndsSubscriptions(t *testing.T) {
+	b := NewBus()
+	open, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+
+	b.Close()
+	if _, ok := <-open; ok {
+		t.Fatal("expected open subscription to be closed")
+	}
+	late, _ := b.Subscribe()
+	if _, ok := <-late; ok {
+		t.Fatal("expected subscription after Close to be closed")
+	}
+	b.Publish(Event{Type: PullStarted})
+	if n := b.Subscribers(); n != 0 {
+		t.Fatalf("Subscribers() = %d, want 0", n)
+	}
+
+	var nilBus *Bus
+	nilBus.Close()
+}
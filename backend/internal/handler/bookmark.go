@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/0x2E/fusion/internal/pkg/sanitize"
 	"github.com/0x2E/fusion/internal/store"
 	"github.com/gin-gonic/gin"
 )
@@ -153,7 +154,8 @@ func (h *Handler) createBookmark(c *gin.Context) {
 		feedName = req.FeedName
 	}
 
-	bookmark, err := h.store.CreateBookmark(req.ItemID, feedID, link, title, content, pubDate, feedName)
+	content = sanitize.HTML(content, link)
+	bookmark, err := h.store.CreateBookmark(req.ItemID, feedID, link, title, content, pubDate, feedName, sanitize.PolicyVersion)
 	if err != nil {
 		internalError(c, err, "create bookmark")
 		return
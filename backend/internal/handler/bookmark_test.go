@@ -23,15 +23,15 @@ func TestListBookmarksCursorPagination(t *testing.T) {
 	// created_at is non-decreasing across inserts and id is auto-increment, so
 	// ORDER BY created_at DESC, id DESC is deterministic regardless of second
 	// resolution: the page order is always [b3, b2, b1].
-	b1, err := st.CreateBookmark(nil, &feed.ID, "https://example.com/1", "Bookmark 1", "c", 100, feed.Name)
+	b1, err := st.CreateBookmark(nil, &feed.ID, "https://example.com/1", "Bookmark 1", "c", 100, feed.Name, 0)
 	if err != nil {
 		t.Fatalf("CreateBookmark 1: %v", err)
 	}
-	b2, err := st.CreateBookmark(nil, &feed.ID, "https://example.com/2", "Bookmark 2", "c", 100, feed.Name)
+	b2, err := st.CreateBookmark(nil, &feed.ID, "https://example.com/2", "Bookmark 2", "c", 100, feed.Name, 0)
 	if err != nil {
 		t.Fatalf("CreateBookmark 2: %v", err)
 	}
-	b3, err := st.CreateBookmark(nil, &feed.ID, "https://example.com/3", "Bookmark 3", "c", 100, feed.Name)
+	b3, err := st.CreateBookmark(nil, &feed.ID, "https://example.com/3", "Bookmark 3", "c", 100, feed.Name, 0)
 	if err != nil {
 		t.Fatalf("CreateBookmark 3: %v", err)
 	}
@@ -102,3 +102,31 @@ func TestListBookmarksCursorInvalidBefore(t *testing.T) {
 		t.Fatalf("expected status 400 for malformed cursor, got %d", w.Code)
 	}
 }
+
+func TestCreateBookmarkSanitizesContent(t *testing.T) {
+	h, _ := newFeverTestHandler(t)
+
+	r := newTestRouter()
+	r.POST("/api/bookmarks", h.createBookmark)
+
+	body := mustJSONBody(t, map[string]any{
+		"link":      "https://example.com/posts/1",
+		"title":     "Post",
+		"content":   `<p onmouseover="x()">Hi<script>steal()</script> <img src="pic.png"></p>`,
+		"feed_name": "Feed",
+	})
+	w := performRequest(r, http.MethodPost, "/api/bookmarks", body, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Data model.Bookmark `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if want := `<p>Hi <img src="https://example.com/posts/pic.png"/></p>`; resp.Data.Content != want {
+		t.Fatalf("content = %q, want %q", resp.Data.Content, want)
+	}
+}
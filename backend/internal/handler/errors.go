@@ -33,6 +33,21 @@ func unauthorizedError(c *gin.Context) {
 	c.JSON(401, gin.H{"error": "unauthorized"})
 }
 
+// forbiddenError returns 403 with the given message.
+func forbiddenError(c *gin.Context, message string) {
+	c.JSON(403, gin.H{"error": message})
+}
+
+// badGatewayError returns 502 when an upstream site could not be used.
+func badGatewayError(c *gin.Context, message string) {
+	c.JSON(502, gin.H{"error": message})
+}
+
+// serviceUnavailableError returns 503 with the given message.
+func serviceUnavailableError(c *gin.Context, message string) {
+	c.JSON(503, gin.H{"error": message})
+}
+
 // tooManyRequestsError returns 429 and sets Retry-After when available.
 func tooManyRequestsError(c *gin.Context, retryAfterSec int64) {
 	if retryAfterSec > 0 {
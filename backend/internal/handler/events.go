@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/0x2E/fusion/internal/events"
+	"github.com/gin-gonic/gin"
+)
+
+// sseHeartbeat keeps idle event streams alive through proxies.
+const sseHeartbeat = 25 * time.Second
+
+// streamEvents serves the event bus as Server-Sent Events. The stream starts
+// with the current unread counts so clients can sync without another request;
+// a client dropped for lagging behind reconnects and resyncs the same way.
+func (h *Handler) streamEvents(c *gin.Context) {
+	ch, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	counts, err := h.store.ListUnreadCounts()
+	if err != nil {
+		internalError(c, err, "list unread counts")
+		return
+	}
+
+	// The stream outlives the server's write timeout.
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		slog.Debug("failed to clear write deadline for event stream", "error", err)
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !writeSSE(c, events.Event{Type: events.UnreadCounts, Data: events.NewUnread(counts)}) {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case e, ok := <-ch:
+			if !ok || !writeSSE(c, e) {
+				return
+			}
+		}
+	}
+}
+
+// writeSSE writes one event and flushes it, reporting whether the client is
+// still reachable.
+func writeSSE(c *gin.Context, e events.Event) bool {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		slog.Error("failed to encode event", "type", e.Type, "error", err)
+		return true
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+		return false
+	}
+	c.Writer.Flush()
+	return true
+}
+
+// publishUnreadCounts announces the unread counts after a read-state change.
+func (h *Handler) publishUnreadCounts() {
+	if h.events.Subscribers() == 0 {
+		return
+	}
+	counts, err := h.store.ListUnreadCounts()
+	if err != nil {
+		slog.Warn("failed to list unread counts", "error", err)
+		return
+	}
+	h.events.Publish(events.Event{Type: events.UnreadCounts, Data: events.NewUnread(counts)})
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/events"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+func TestStreamEvents(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+	h.events = events.NewBus()
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/feed.xml", "", "")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if _, err := st.BatchCreateItemsIgnore(feed.ID, []store.BatchCreateItemInput{
+		{GUID: "a", Title: "A"},
+		{GUID: "b", Title: "B"},
+	}); err != nil {
+		t.Fatalf("BatchCreateItemsIgnore: %v", err)
+	}
+
+	r := newTestRouter()
+	r.GET("/api/events", h.streamEvents)
+	r.PATCH("/api/items/-/read", h.markItemsRead)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/events", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func() (string, string) {
+		t.Helper()
+		var typ, data string
+		for lines.Scan() {
+			line := lines.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				typ = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			case line == "" && typ != "":
+				return typ, data
+			}
+		}
+		t.Fatalf("stream ended: %v", lines.Err())
+		return "", ""
+	}
+
+	if typ, data := next(); typ != events.UnreadCounts || data != `{"total":2,"feeds":[{"feed_id":1,"unread_count":2}]}` {
+		t.Fatalf("first event = %s %s, want initial unread counts", typ, data)
+	}
+
+	h.events.Publish(events.Event{Type: events.PullFailed, Data: events.Pull{FeedID: feed.ID, FeedName: "Feed", HTTPStatus: 503, ErrorClass: "http", Error: "HTTP 503"}})
+	if typ, data := next(); typ != events.PullFailed || data != `{"feed_id":1,"feed_name":"Feed","http_status":503,"error_class":"http","error":"HTTP 503"}` {
+		t.Fatalf("got %s %s, want pull.failed", typ, data)
+	}
+
+	w := performRequest(r, http.MethodPatch, "/api/items/-/read", bytes.NewBufferString(`{"ids":[1]}`), map[string]string{"Content-Type": "application/json"})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("mark read status = %d, want 204", w.Code)
+	}
+	if typ, data := next(); typ != events.UnreadCounts || data != `{"total":1,"feeds":[{"feed_id":1,"unread_count":1}]}` {
+		t.Fatalf("got %s %s, want updated unread counts", typ, data)
+	}
+}
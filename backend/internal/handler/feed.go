@@ -5,12 +5,17 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/0x2E/feedfinder"
+	"github.com/0x2E/fusion/internal/discovery"
+	"github.com/0x2E/fusion/internal/jobs"
+	"github.com/0x2E/fusion/internal/model"
 	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"github.com/0x2E/fusion/internal/pull"
 	"github.com/0x2E/fusion/internal/store"
 	"github.com/gin-gonic/gin"
 	"github.com/mmcdole/gofeed"
@@ -22,6 +27,12 @@ type createFeedRequest struct {
 	Link    string `json:"link" binding:"required"`
 	SiteURL string `json:"site_url"`
 	Proxy   string `json:"proxy"`
+	// Credentials and headers sent with every fetch; stored encrypted.
+	Auth *model.FeedAuth `json:"auth"`
+	// Overrides FUSION_USER_AGENT for this feed.
+	UserAgent string `json:"user_agent"`
+	// Makes this a scraper feed: link is an HTML page read with the recipe.
+	Scrape *model.ScrapeRecipe `json:"scrape"`
 }
 
 type updateFeedRequest struct {
@@ -31,15 +42,36 @@ type updateFeedRequest struct {
 	SiteURL   *string `json:"site_url"`
 	Suspended *bool   `json:"suspended"`
 	Proxy     *string `json:"proxy"` // Empty string clears proxy
+	// -1 inherits the global retention default, 0 keeps items forever.
+	RetentionDays     *int64 `json:"retention_days"`
+	RetentionMaxItems *int64 `json:"retention_max_items"`
+	// Seconds; 0 inherits FUSION_PULL_INTERVAL / FUSION_PULL_MAX_BACKOFF.
+	PullInterval *int64 `json:"pull_interval"`
+	MaxBackoff   *int64 `json:"max_backoff"`
+	// Fetch each new item's page and extract the article (readability).
+	FullText *bool `json:"full_text"`
+	// How to treat upstream edits of known items: ignore, update, update_unread.
+	UpdateMode *string `json:"update_mode"`
+	// Replaces stored credentials; an empty object removes them.
+	Auth *model.FeedAuth `json:"auth"`
+	// Empty string restores FUSION_USER_AGENT.
+	UserAgent *string `json:"user_agent"`
+	// Replaces the scraping recipe; a regular feed becomes a scraper feed.
+	Scrape *model.ScrapeRecipe `json:"scrape"`
 }
 
 type validateFeedRequest struct {
 	URL string `json:"url" binding:"required"`
+	// Optional credentials for feeds behind authentication.
+	Auth      *model.FeedAuth `json:"auth"`
+	UserAgent string          `json:"user_agent"`
 }
 
 type discoveredFeed struct {
 	Title string `json:"title"`
 	Link  string `json:"link"`
+	// Type names the service rule that produced the feed, if any.
+	Type string `json:"type,omitempty"`
 }
 
 type validateFeedResponse struct {
@@ -57,7 +89,8 @@ type batchCreateFeedItem struct {
 	SiteURL string `json:"site_url"`
 }
 
-const refreshAllTimeout = 30 * time.Minute
+// jobTimeout bounds refresh-all, import and initial pull jobs.
+const jobTimeout = 30 * time.Minute
 
 func (h *Handler) listFeeds(c *gin.Context) {
 	feeds, err := h.store.ListFeeds()
@@ -89,6 +122,99 @@ func (h *Handler) getFeed(c *gin.Context) {
 	dataResponse(c, feed)
 }
 
+// getFeedLinkHistory lists links replaced after stable permanent redirects.
+func (h *Handler) getFeedLinkHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	if _, err := h.store.GetFeed(id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "feed")
+			return
+		}
+		internalError(c, err, "get feed")
+		return
+	}
+
+	history, err := h.store.ListFeedLinkHistory(id)
+	if err != nil {
+		internalError(c, err, "list feed link history")
+		return
+	}
+
+	dataResponse(c, history)
+}
+
+// getFeedFetchHistory lists the recorded pull attempts of a feed.
+func (h *Handler) getFeedFetchHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	if _, err := h.store.GetFeed(id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "feed")
+			return
+		}
+		internalError(c, err, "get feed")
+		return
+	}
+
+	history, err := h.store.ListFeedFetchLog(id)
+	if err != nil {
+		internalError(c, err, "list feed fetch history")
+		return
+	}
+
+	dataResponse(c, history)
+}
+
+// listFeedHealth reports fetch reliability and publishing activity per feed.
+func (h *Handler) listFeedHealth(c *gin.Context) {
+	list, err := h.store.ListFeedHealth(time.Now().Unix())
+	if err != nil {
+		internalError(c, err, "list feed health")
+		return
+	}
+
+	listResponse(c, list, len(list))
+}
+
+// getPullStatus reports the puller's per-host limits and counters.
+func (h *Handler) getPullStatus(c *gin.Context) {
+	dataResponse(c, h.puller.Status())
+}
+
+// getFeedIcon serves the cached favicon discovered by the puller.
+func (h *Handler) getFeedIcon(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	icon, err := h.store.GetFeedIcon(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "feed icon")
+			return
+		}
+		internalError(c, err, "get feed icon")
+		return
+	}
+
+	// Icons may be SVG; never let them run script in the app origin.
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.Data(http.StatusOK, icon.MimeType, icon.Data)
+}
+
 func (h *Handler) createFeed(c *gin.Context) {
 	var req createFeedRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -99,22 +225,43 @@ func (h *Handler) createFeed(c *gin.Context) {
 		badRequestError(c, "invalid link")
 		return
 	}
+	if err := httpc.ValidateProxyURL(c.Request.Context(), req.Proxy, h.config.AllowPrivateFeeds); err != nil {
+		badRequestError(c, "invalid proxy")
+		return
+	}
+	if err := httpc.ValidateFeedAuth(req.Auth); err != nil {
+		badRequestError(c, "invalid auth: "+err.Error())
+		return
+	}
+	userAgent := strings.TrimSpace(req.UserAgent)
+	if err := httpc.ValidateUserAgent(userAgent); err != nil {
+		badRequestError(c, "invalid user_agent")
+		return
+	}
+	if req.Scrape != nil {
+		if err := pull.ValidateScrapeRecipe(req.Scrape); err != nil {
+			badRequestError(c, "invalid scrape: "+err.Error())
+			return
+		}
+	}
 
-	feed, err := h.store.CreateFeed(req.GroupID, req.Name, req.Link, req.SiteURL, req.Proxy)
+	// Request settings are stored with the feed, before the initial pull below.
+	feed, err := h.store.CreateFeedWithParams(store.CreateFeedParams{
+		GroupID:   req.GroupID,
+		Name:      req.Name,
+		Link:      req.Link,
+		SiteURL:   req.SiteURL,
+		Proxy:     req.Proxy,
+		Auth:      req.Auth,
+		UserAgent: userAgent,
+		Scrape:    req.Scrape,
+	})
 	if err != nil {
 		internalError(c, err, "create feed")
 		return
 	}
 
-	// Trigger initial pull in background.
-	refreshTimeout := time.Duration(h.config.PullTimeout) * time.Second
-	go func(feedID int64) {
-		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
-		defer cancel()
-		if err := h.puller.RefreshFeed(ctx, feedID); err != nil {
-			slog.Warn("initial feed pull failed", "feed_id", feedID, "error", err)
-		}
-	}(feed.ID)
+	h.startInitialPulls(model.JobInitialPull, []int64{feed.ID})
 
 	dataResponse(c, feed)
 }
@@ -153,8 +300,74 @@ func (h *Handler) updateFeed(c *gin.Context) {
 		params.Suspended = req.Suspended
 	}
 	if req.Proxy != nil {
+		if err := httpc.ValidateProxyURL(c.Request.Context(), *req.Proxy, h.config.AllowPrivateFeeds); err != nil {
+			badRequestError(c, "invalid proxy")
+			return
+		}
 		params.Proxy = req.Proxy
 	}
+	if req.RetentionDays != nil {
+		if *req.RetentionDays < -1 {
+			badRequestError(c, "invalid retention_days")
+			return
+		}
+		params.RetentionDays = req.RetentionDays
+	}
+	if req.RetentionMaxItems != nil {
+		if *req.RetentionMaxItems < -1 {
+			badRequestError(c, "invalid retention_max_items")
+			return
+		}
+		params.RetentionMaxItems = req.RetentionMaxItems
+	}
+	if req.PullInterval != nil {
+		if !validScheduleSeconds(*req.PullInterval) {
+			badRequestError(c, "invalid pull_interval")
+			return
+		}
+		params.PullInterval = req.PullInterval
+	}
+	if req.MaxBackoff != nil {
+		if !validScheduleSeconds(*req.MaxBackoff) {
+			badRequestError(c, "invalid max_backoff")
+			return
+		}
+		params.MaxBackoff = req.MaxBackoff
+	}
+	if req.FullText != nil {
+		params.FullText = req.FullText
+	}
+	if req.UpdateMode != nil {
+		switch *req.UpdateMode {
+		case model.ItemUpdateIgnore, model.ItemUpdateSilent, model.ItemUpdateUnread:
+		default:
+			badRequestError(c, "invalid update_mode")
+			return
+		}
+		params.UpdateMode = req.UpdateMode
+	}
+	if req.Auth != nil {
+		if err := httpc.ValidateFeedAuth(req.Auth); err != nil {
+			badRequestError(c, "invalid auth: "+err.Error())
+			return
+		}
+		params.Auth = req.Auth
+	}
+	if req.UserAgent != nil {
+		userAgent := strings.TrimSpace(*req.UserAgent)
+		if err := httpc.ValidateUserAgent(userAgent); err != nil {
+			badRequestError(c, "invalid user_agent")
+			return
+		}
+		params.UserAgent = &userAgent
+	}
+	if req.Scrape != nil {
+		if err := pull.ValidateScrapeRecipe(req.Scrape); err != nil {
+			badRequestError(c, "invalid scrape: "+err.Error())
+			return
+		}
+		params.Scrape = req.Scrape
+	}
 
 	if err := h.store.UpdateFeed(id, params); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
@@ -164,6 +377,13 @@ func (h *Handler) updateFeed(c *gin.Context) {
 		internalError(c, err, "update feed")
 		return
 	}
+	if params.PullInterval != nil || params.MaxBackoff != nil {
+		if err := h.puller.ApplySchedule(id); err != nil {
+			slog.Warn("failed to apply feed schedule", "feed_id", id, "error", err)
+		}
+	}
+	// Schedule overrides, suspension and link changes move the next pull.
+	h.puller.Reschedule(id)
 
 	feed, err := h.store.GetFeed(id)
 	if err != nil {
@@ -178,6 +398,15 @@ func (h *Handler) updateFeed(c *gin.Context) {
 	dataResponse(c, feed)
 }
 
+// minScheduleSeconds is the shortest per-feed pull_interval or max_backoff.
+const minScheduleSeconds = 60
+
+// validScheduleSeconds accepts 0 (inherit the global value) or at least
+// minScheduleSeconds.
+func validScheduleSeconds(seconds int64) bool {
+	return seconds == 0 || seconds >= minScheduleSeconds
+}
+
 func (h *Handler) deleteFeed(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -210,11 +439,39 @@ func (h *Handler) validateFeed(c *gin.Context) {
 		badRequestError(c, "invalid url")
 		return
 	}
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		badRequestError(c, "invalid url")
+		return
+	}
+	if err := httpc.ValidateFeedAuth(req.Auth); err != nil {
+		badRequestError(c, "invalid auth: "+err.Error())
+		return
+	}
+	userAgent := strings.TrimSpace(req.UserAgent)
+	if err := httpc.ValidateUserAgent(userAgent); err != nil {
+		badRequestError(c, "invalid user_agent")
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
-	found, err := feedfinder.Find(ctx, target, nil)
+	var found []discovery.Feed
+	client, err := httpc.NewClient(30*time.Second, "", allowPrivateFeeds)
+	if err == nil {
+		// Credentials are only sent to the host the user entered.
+		client = httpc.FeedAuthClient(client, req.Auth, targetURL.Host)
+		// Service URLs map to known feeds; anything else goes to feedfinder.
+		found, err = discovery.FindByRules(ctx, target, discovery.Options{Client: client, UserAgent: userAgent})
+		if err == nil && len(found) == 0 {
+			var pageFeeds []feedfinder.Feed
+			pageFeeds, err = feedfinder.Find(ctx, target, httpc.UserAgentClient(client, userAgent))
+			for _, feed := range pageFeeds {
+				found = append(found, discovery.Feed{Title: feed.Title, Link: feed.Link})
+			}
+		}
+	}
 	if err != nil {
 		slog.Warn("feed discovery failed", "url", target, "error", err)
 	}
@@ -231,7 +488,7 @@ func (h *Handler) validateFeed(c *gin.Context) {
 	}
 
 	if len(feeds) == 0 {
-		title, parseErr := h.parseFeedTitle(ctx, target)
+		title, parseErr := h.parseFeedTitle(ctx, target, userAgent, req.Auth)
 		if parseErr == nil {
 			feeds = append(feeds, discoveredFeed{Title: title, Link: target})
 		}
@@ -240,7 +497,7 @@ func (h *Handler) validateFeed(c *gin.Context) {
 	dataResponse(c, validateFeedResponse{Feeds: feeds})
 }
 
-func normalizeDiscoveredFeeds(found []feedfinder.Feed) []discoveredFeed {
+func normalizeDiscoveredFeeds(found []discovery.Feed) []discoveredFeed {
 	result := make([]discoveredFeed, 0, len(found))
 	seen := make(map[string]struct{}, len(found))
 
@@ -257,13 +514,14 @@ func normalizeDiscoveredFeeds(found []feedfinder.Feed) []discoveredFeed {
 		result = append(result, discoveredFeed{
 			Title: strings.TrimSpace(feed.Title),
 			Link:  link,
+			Type:  feed.Type,
 		})
 	}
 
 	return result
 }
 
-func (h *Handler) parseFeedTitle(ctx context.Context, target string) (string, error) {
+func (h *Handler) parseFeedTitle(ctx context.Context, target, userAgent string, auth *model.FeedAuth) (string, error) {
 	allowPrivateFeeds := h.config != nil && h.config.AllowPrivateFeeds
 
 	client, err := httpc.NewClient(30*time.Second, "", allowPrivateFeeds)
@@ -276,8 +534,10 @@ func (h *Handler) parseFeedTitle(ctx context.Context, target string) (string, er
 		return "", err
 	}
 	httpc.SetDefaultHeaders(req)
+	httpc.SetFeedUserAgent(req, userAgent)
+	httpc.SetFeedAuth(req, auth)
 
-	resp, err := client.Do(req)
+	resp, err := httpc.AuthClient(client, auth).Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -329,43 +589,24 @@ func (h *Handler) refreshFeed(c *gin.Context) {
 	c.Status(http.StatusAccepted)
 }
 
+// refreshAllFeeds starts a refresh-all job, or returns the one already queued
+// or running.
 func (h *Handler) refreshAllFeeds(c *gin.Context) {
-	if !h.tryStartRefreshAll() {
-		c.Status(http.StatusAccepted)
+	job, _, err := h.jobs.Submit(jobs.Spec{
+		Kind:    model.JobRefreshAll,
+		Unique:  true,
+		Timeout: jobTimeout,
+		Run: func(ctx context.Context, progress *jobs.Progress) error {
+			_, err := h.puller.RefreshAll(ctx, progress)
+			return err
+		},
+	})
+	if err != nil {
+		serviceUnavailableError(c, "server is shutting down")
 		return
 	}
 
-	// Run in background so the HTTP response returns immediately.
-	go func() {
-		defer h.finishRefreshAll()
-
-		ctx, cancel := context.WithTimeout(context.Background(), refreshAllTimeout)
-		defer cancel()
-
-		if count, err := h.puller.RefreshAll(ctx); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
-			slog.Warn("refresh all feeds failed", "refreshed", count, "error", err)
-		}
-	}()
-
-	c.Status(http.StatusAccepted)
-}
-
-func (h *Handler) tryStartRefreshAll() bool {
-	h.refreshAllMu.Lock()
-	defer h.refreshAllMu.Unlock()
-
-	if h.refreshAllRunning {
-		return false
-	}
-
-	h.refreshAllRunning = true
-	return true
-}
-
-func (h *Handler) finishRefreshAll() {
-	h.refreshAllMu.Lock()
-	h.refreshAllRunning = false
-	h.refreshAllMu.Unlock()
+	c.JSON(http.StatusAccepted, gin.H{"data": job})
 }
 
 func (h *Handler) batchCreateFeeds(c *gin.Context) {
@@ -395,21 +636,40 @@ func (h *Handler) batchCreateFeeds(c *gin.Context) {
 		return
 	}
 
-	// Trigger initial pull for each new feed in background.
-	refreshTimeout := time.Duration(h.config.PullTimeout) * time.Second
-	for _, id := range result.CreatedIDs {
-		go func(feedID int64) {
-			ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
-			defer cancel()
-			if err := h.puller.RefreshFeed(ctx, feedID); err != nil {
-				slog.Warn("initial feed pull failed", "feed_id", feedID, "error", err)
-			}
-		}(id)
-	}
-
-	dataResponse(c, gin.H{
+	response := gin.H{
 		"created": result.Created,
 		"failed":  len(result.Errors),
 		"errors":  result.Errors,
+	}
+	if jobID := h.startInitialPulls(model.JobImport, result.CreatedIDs); jobID != 0 {
+		response["job_id"] = jobID
+	}
+
+	dataResponse(c, response)
+}
+
+// startInitialPulls submits a job pulling newly created feeds for the first
+// time and returns its ID, or 0 when none was started. Feeds whose initial
+// pull cannot run are left to the scheduler.
+func (h *Handler) startInitialPulls(kind string, feedIDs []int64) int64 {
+	if len(feedIDs) == 0 {
+		return 0
+	}
+
+	job, _, err := h.jobs.Submit(jobs.Spec{
+		Kind:    kind,
+		Timeout: jobTimeout,
+		Run: func(ctx context.Context, progress *jobs.Progress) error {
+			_, err := h.puller.RefreshFeeds(ctx, feedIDs, progress)
+			return err
+		},
 	})
+	if err != nil {
+		slog.Warn("initial feed pulls not started", "feeds", len(feedIDs), "error", err)
+		for _, id := range feedIDs {
+			h.puller.Reschedule(id)
+		}
+		return 0
+	}
+	return job.ID
 }
@@ -1,20 +1,108 @@
 package handler
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
 
-func TestRefreshAllRunGuard(t *testing.T) {
-	h := &Handler{}
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pull"
+)
 
-	if !h.tryStartRefreshAll() {
-		t.Fatal("expected first refresh-all start to succeed")
+// blockingRefreshPuller runs refresh-all until release is closed.
+type blockingRefreshPuller struct {
+	noopPuller
+	release chan struct{}
+}
+
+func (p *blockingRefreshPuller) RefreshAll(ctx context.Context, progress pull.Progress) (int, error) {
+	progress.SetTotal(2)
+	progress.Step(nil)
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return 1, ctx.Err()
+	}
+	progress.Step(errors.New("feed 2 (Broken): HTTP 500"))
+	return 2, nil
+}
+
+func TestRefreshAllRunsAsSingleJob(t *testing.T) {
+	h, _ := newFeverTestHandler(t)
+	puller := &blockingRefreshPuller{release: make(chan struct{})}
+	h.puller = puller
+
+	r := newTestRouter()
+	r.POST("/api/feeds/refresh", h.refreshAllFeeds)
+	r.GET("/api/jobs", h.listJobs)
+	r.GET("/api/jobs/:id", h.getJob)
+	r.DELETE("/api/jobs/:id", h.deleteJob)
+
+	start := func() model.Job {
+		t.Helper()
+		w := performRequest(r, http.MethodPost, "/api/feeds/refresh", nil, nil)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("expected status 202, got %d", w.Code)
+		}
+		var resp struct {
+			Data model.Job `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal job: %v", err)
+		}
+		return resp.Data
+	}
+	get := func(id string) model.Job {
+		t.Helper()
+		w := performRequest(r, http.MethodGet, "/api/jobs/"+id, nil, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		var resp struct {
+			Data model.Job `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal job: %v", err)
+		}
+		return resp.Data
 	}
-	if h.tryStartRefreshAll() {
-		t.Fatal("expected second refresh-all start to be rejected")
+
+	first := start()
+	if second := start(); second.ID != first.ID {
+		t.Fatalf("second refresh-all started job %d, want the running job %d", second.ID, first.ID)
+	}
+
+	close(puller.release)
+	deadline := time.Now().Add(3 * time.Second)
+	job := get("1")
+	for job.State != model.JobSucceeded {
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish: %+v", job)
+		}
+		time.Sleep(5 * time.Millisecond)
+		job = get("1")
+	}
+	if job.Kind != model.JobRefreshAll || job.Total != 2 || job.Done != 2 || job.Failed != 1 || len(job.Errors) != 1 {
+		t.Fatalf("unexpected job: %+v", job)
 	}
 
-	h.finishRefreshAll()
+	if w := performRequest(r, http.MethodGet, "/api/jobs", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("list jobs: expected status 200, got %d", w.Code)
+	}
+	if w := performRequest(r, http.MethodDelete, "/api/jobs/1", nil, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("delete job: expected status 204, got %d", w.Code)
+	}
+	if w := performRequest(r, http.MethodGet, "/api/jobs/1", nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("get deleted job: expected status 404, got %d", w.Code)
+	}
 
-	if !h.tryStartRefreshAll() {
-		t.Fatal("expected refresh-all to be allowed after finish")
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown(): %v", err)
+	}
+	if w := performRequest(r, http.MethodPost, "/api/feeds/refresh", nil, nil); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("refresh after shutdown: expected status 503, got %d", w.Code)
 	}
 }
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"github.com/0x2E/fusion/internal/pull"
+	"github.com/gin-gonic/gin"
+)
+
+type scrapePreviewRequest struct {
+	URL    string              `json:"url" binding:"required"`
+	Scrape *model.ScrapeRecipe `json:"scrape" binding:"required"`
+	// Optional request settings, as they would be saved on the feed.
+	Auth      *model.FeedAuth `json:"auth"`
+	UserAgent string          `json:"user_agent"`
+}
+
+type scrapePreviewItem struct {
+	Title   string `json:"title"`
+	Link    string `json:"link"`
+	Content string `json:"content"`
+	PubDate int64  `json:"pub_date"`
+}
+
+type scrapePreviewResponse struct {
+	SiteURL string              `json:"site_url"`
+	Items   []scrapePreviewItem `json:"items"`
+}
+
+// previewScrape fetches a page and returns what a recipe would extract,
+// without storing anything.
+func (h *Handler) previewScrape(c *gin.Context) {
+	var req scrapePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+
+	target := strings.TrimSpace(req.URL)
+	allowPrivateFeeds := h.config != nil && h.config.AllowPrivateFeeds
+	if err := httpc.ValidateRequestURL(c.Request.Context(), target, allowPrivateFeeds); err != nil {
+		badRequestError(c, "invalid url")
+		return
+	}
+	if err := pull.ValidateScrapeRecipe(req.Scrape); err != nil {
+		badRequestError(c, "invalid scrape: "+err.Error())
+		return
+	}
+	if err := httpc.ValidateFeedAuth(req.Auth); err != nil {
+		badRequestError(c, "invalid auth: "+err.Error())
+		return
+	}
+	userAgent := strings.TrimSpace(req.UserAgent)
+	if err := httpc.ValidateUserAgent(userAgent); err != nil {
+		badRequestError(c, "invalid user_agent")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	feed := &model.Feed{
+		Link:      target,
+		Kind:      model.FeedKindScrape,
+		Scrape:    req.Scrape,
+		UserAgent: userAgent,
+		Auth:      req.Auth,
+	}
+	result, err := pull.FetchAndParse(ctx, feed, 30*time.Second, allowPrivateFeeds)
+	if err != nil {
+		slog.Warn("scrape preview failed", "url", target, "error", err)
+		badGatewayError(c, "failed to fetch page")
+		return
+	}
+
+	items := make([]scrapePreviewItem, 0, len(result.Items))
+	for _, item := range result.Items {
+		items = append(items, scrapePreviewItem{
+			Title:   item.Title,
+			Link:    item.Link,
+			Content: item.Content,
+			PubDate: item.PubDate,
+		})
+	}
+	dataResponse(c, scrapePreviewResponse{SiteURL: result.SiteURL, Items: items})
+}
@@ -0,0 +1,382 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/secretbox"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+func TestGetFeedIcon(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/rss.xml", "https://example.com", "")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	r := newTestRouter()
+	r.GET("/api/feeds/:id/icon", h.getFeedIcon)
+
+	w := performRequest(r, http.MethodGet, "/api/feeds/1/icon", nil, nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 without icon, got %d", w.Code)
+	}
+
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)
+	if err := st.UpsertFeedIcon(feed.ID, store.UpsertFeedIconParams{MimeType: "image/svg+xml", Data: svg, RefreshAfter: 1}); err != nil {
+		t.Fatalf("UpsertFeedIcon: %v", err)
+	}
+
+	w = performRequest(r, http.MethodGet, "/api/feeds/1/icon", nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "image/svg+xml" {
+		t.Errorf("unexpected content type %q", got)
+	}
+	if w.Header().Get("Content-Security-Policy") == "" {
+		t.Error("expected Content-Security-Policy header")
+	}
+	if w.Body.String() != string(svg) {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+}
+
+func TestGetFeedLinkHistory(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	feed, err := st.CreateFeed(1, "Feed", "http://example.com/rss.xml", "https://example.com", "")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if err := st.MoveFeedLink(feed.ID, feed.Link, "https://example.com/rss.xml"); err != nil {
+		t.Fatalf("MoveFeedLink: %v", err)
+	}
+
+	r := newTestRouter()
+	r.GET("/api/feeds/:id/links", h.getFeedLinkHistory)
+
+	w := performRequest(r, http.MethodGet, "/api/feeds/1/links", nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp struct {
+		Data []model.FeedLinkChange `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal history: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].OldLink != "http://example.com/rss.xml" || resp.Data[0].NewLink != "https://example.com/rss.xml" {
+		t.Fatalf("unexpected history: %+v", resp.Data)
+	}
+
+	if w := performRequest(r, http.MethodGet, "/api/feeds/999/links", nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for unknown feed, got %d", w.Code)
+	}
+}
+
+func TestFeedFetchHistoryAndHealth(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/rss.xml", "", "")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	for _, attempt := range []*model.FeedFetchLog{
+		{FeedID: feed.ID, FetchedAt: 100, HTTPStatus: 200, Bytes: 2048, NewItems: 3},
+		{FeedID: feed.ID, FetchedAt: 200, HTTPStatus: 503, ErrorClass: model.FetchErrorHTTP, Error: "HTTP 503"},
+	} {
+		if err := st.RecordFeedFetch(attempt); err != nil {
+			t.Fatalf("RecordFeedFetch: %v", err)
+		}
+	}
+
+	r := newTestRouter()
+	r.GET("/api/feeds/health", h.listFeedHealth)
+	r.GET("/api/feeds/:id/history", h.getFeedFetchHistory)
+
+	w := performRequest(r, http.MethodGet, "/api/feeds/1/history", nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var history struct {
+		Data []model.FeedFetchLog `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatalf("unmarshal history: %v", err)
+	}
+	if len(history.Data) != 2 || history.Data[0].ErrorClass != model.FetchErrorHTTP || history.Data[1].Bytes != 2048 {
+		t.Fatalf("unexpected history: %+v", history.Data)
+	}
+	if w := performRequest(r, http.MethodGet, "/api/feeds/999/history", nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for unknown feed, got %d", w.Code)
+	}
+
+	w = performRequest(r, http.MethodGet, "/api/feeds/health", nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var health struct {
+		Data  []model.FeedHealth `json:"data"`
+		Total int                `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+		t.Fatalf("unmarshal health: %v", err)
+	}
+	if health.Total != 1 || health.Data[0].SuccessRate != 0.5 || health.Data[0].LastErrorClass != model.FetchErrorHTTP {
+		t.Fatalf("unexpected health: %+v", health.Data)
+	}
+}
+
+func TestFeedAuthIsNeverReturned(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+	h.config.AllowPrivateFeeds = true
+	box, err := secretbox.New([]byte("test-key"))
+	if err != nil {
+		t.Fatalf("secretbox.New: %v", err)
+	}
+	st.SetSecretBox(box)
+
+	r := newTestRouter()
+	r.POST("/api/feeds", h.createFeed)
+	r.GET("/api/feeds", h.listFeeds)
+	r.PATCH("/api/feeds/:id", h.updateFeed)
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	w := performRequest(r, http.MethodPost, "/api/feeds", mustJSONBody(t, map[string]any{
+		"group_id": 1,
+		"name":     "Private",
+		"link":     "https://jira.example.com/activity",
+		"auth":     map[string]any{"username": "alice", "password": "hunter2", "headers": map[string]string{"X-Api-Key": "k1"}},
+	}), jsonHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+
+	w = performRequest(r, http.MethodGet, "/api/feeds", nil, nil)
+	body := w.Body.String()
+	if strings.Contains(body, "hunter2") || strings.Contains(body, "k1") || !strings.Contains(body, `"has_auth":true`) {
+		t.Fatalf("unexpected feed list: %s", body)
+	}
+
+	feed, err := st.GetFeed(1)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+	if feed.Auth == nil || feed.Auth.Password != "hunter2" {
+		t.Fatalf("expected stored credentials, got %+v", feed.Auth)
+	}
+
+	w = performRequest(r, http.MethodPatch, "/api/feeds/1", mustJSONBody(t, map[string]any{
+		"auth": map[string]any{"headers": map[string]string{"Host": "evil.example.com"}},
+	}), jsonHeaders)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for reserved header, got %d", w.Code)
+	}
+
+	w = performRequest(r, http.MethodPatch, "/api/feeds/1", mustJSONBody(t, map[string]any{"auth": map[string]any{}}), jsonHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 when clearing auth, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	if feed, err := st.GetFeed(1); err != nil || feed.HasAuth {
+		t.Fatalf("expected auth cleared, got %+v, %v", feed, err)
+	}
+}
+
+func TestUpdateFeedUserAgent(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	if _, err := st.CreateFeed(1, "Feed", "https://example.com/rss.xml", "https://example.com", ""); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	r := newTestRouter()
+	r.PATCH("/api/feeds/:id", h.updateFeed)
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	w := performRequest(r, http.MethodPatch, "/api/feeds/1", mustJSONBody(t, map[string]any{"user_agent": " Mozilla/5.0 (compatible; fusion) "}), jsonHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	if feed, err := st.GetFeed(1); err != nil || feed.UserAgent != "Mozilla/5.0 (compatible; fusion)" {
+		t.Fatalf("unexpected user agent: %+v, %v", feed, err)
+	}
+
+	w = performRequest(r, http.MethodPatch, "/api/feeds/1", mustJSONBody(t, map[string]any{"user_agent": "a\nb"}), jsonHeaders)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for multi-line user agent, got %d", w.Code)
+	}
+}
+
+func TestUpdateFeedScheduleBounds(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	if _, err := st.CreateFeed(1, "Feed", "https://example.com/rss.xml", "https://example.com", ""); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	r := newTestRouter()
+	r.PATCH("/api/feeds/:id", h.updateFeed)
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	for _, body := range []map[string]any{{"pull_interval": 1}, {"pull_interval": -1}, {"max_backoff": 59}} {
+		w := performRequest(r, http.MethodPatch, "/api/feeds/1", mustJSONBody(t, body), jsonHeaders)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%v: expected status 400, got %d", body, w.Code)
+		}
+	}
+
+	w := performRequest(r, http.MethodPatch, "/api/feeds/1", mustJSONBody(t, map[string]any{"pull_interval": 300, "max_backoff": 0}), jsonHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	if feed, err := st.GetFeed(1); err != nil || feed.PullInterval != 300 {
+		t.Fatalf("unexpected schedule: %+v, %v", feed, err)
+	}
+}
+
+func TestPreviewScrape(t *testing.T) {
+	h, _ := newFeverTestHandler(t)
+	h.config.AllowPrivateFeeds = true
+
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<ul><li><a href="/a">Alpha</a> <time>2024-01-02</time></li><li><a href="/b">Beta</a></li></ul>`))
+	}))
+	defer page.Close()
+
+	r := newTestRouter()
+	r.POST("/api/feeds/scrape/preview", h.previewScrape)
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	w := performRequest(r, http.MethodPost, "/api/feeds/scrape/preview", mustJSONBody(t, map[string]any{
+		"url":    page.URL,
+		"scrape": map[string]any{"item": "li", "title": "a", "date": "time"},
+	}), jsonHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Data scrapePreviewResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Data.Items) != 2 {
+		t.Fatalf("expected 2 items, got %+v", resp.Data.Items)
+	}
+	if got := resp.Data.Items[0]; got.Title != "Alpha" || got.Link != page.URL+"/a" || got.PubDate != 1704153600 {
+		t.Fatalf("unexpected first item: %+v", got)
+	}
+
+	w = performRequest(r, http.MethodPost, "/api/feeds/scrape/preview", mustJSONBody(t, map[string]any{
+		"url":    page.URL,
+		"scrape": map[string]any{"item": "li["},
+	}), jsonHeaders)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for invalid recipe, got %d", w.Code)
+	}
+}
+
+func TestUpdateFeedScrapeRecipe(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	if _, err := st.CreateFeed(1, "Page", "https://example.com/news", "", ""); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if feed, err := st.GetFeed(1); err != nil || feed.Kind != model.FeedKindFeed || feed.Scrape != nil {
+		t.Fatalf("expected regular feed, got %+v, %v", feed, err)
+	}
+
+	r := newTestRouter()
+	r.PATCH("/api/feeds/:id", h.updateFeed)
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	recipe := map[string]any{"item": "article", "title": "h2", "date_layout": "2006-01-02"}
+	w := performRequest(r, http.MethodPatch, "/api/feeds/1", mustJSONBody(t, map[string]any{"scrape": recipe}), jsonHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	feed, err := st.GetFeed(1)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+	want := &model.ScrapeRecipe{Item: "article", Title: "h2", DateLayout: "2006-01-02"}
+	if feed.Kind != model.FeedKindScrape || feed.Scrape == nil || *feed.Scrape != *want {
+		t.Fatalf("unexpected scraper feed: kind=%q recipe=%+v", feed.Kind, feed.Scrape)
+	}
+
+	w = performRequest(r, http.MethodPatch, "/api/feeds/1", mustJSONBody(t, map[string]any{"scrape": map[string]any{"item": "article"}}), jsonHeaders)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 without title selector, got %d", w.Code)
+	}
+}
+
+func TestGetPullStatus(t *testing.T) {
+	h, _ := newFeverTestHandler(t)
+
+	r := newTestRouter()
+	r.GET("/api/admin/pull-status", h.getPullStatus)
+
+	w := performRequest(r, http.MethodGet, "/api/admin/pull-status", nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp struct {
+		Data model.PullStatus `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal status: %v", err)
+	}
+	if resp.Data.HostConcurrency != 2 || len(resp.Data.Hosts) != 1 || resp.Data.Hosts[0].Requests != 3 {
+		t.Fatalf("unexpected status: %+v", resp.Data)
+	}
+}
+
+func TestValidateFeedDiscoversAuthenticatedPage(t *testing.T) {
+	h, _ := newFeverTestHandler(t)
+	h.config.AllowPrivateFeeds = true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head><link rel="alternate" type="application/rss+xml" href="/feed.xml"></head></html>`))
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Private</title></channel></rss>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	r := newTestRouter()
+	r.POST("/api/feeds/validate", h.validateFeed)
+	body := map[string]any{"url": server.URL + "/", "auth": map[string]any{"username": "alice", "password": "secret"}}
+	w := performRequest(r, http.MethodPost, "/api/feeds/validate", mustJSONBody(t, body), map[string]string{"Content-Type": "application/json"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Data validateFeedResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Data.Feeds) != 1 || resp.Data.Feeds[0].Link != server.URL+"/feed.xml" {
+		t.Fatalf("expected the linked feed, got %+v", resp.Data.Feeds)
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"crypto/md5"
 	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/0x2E/fusion/internal/pkg/sanitize"
 	"github.com/0x2E/fusion/internal/store"
 	"github.com/gin-gonic/gin"
 )
@@ -108,8 +110,10 @@ func (h *Handler) fever(c *gin.Context) {
 		badRequestError(c, msg)
 		return
 	}
-
 	if markResult.IncludeUnreadItemIDs {
+		// Only read-state marks ask for the unread IDs.
+		h.publishUnreadCounts()
+
 		ids, err := h.store.ListUnreadItemIDs()
 		if err != nil {
 			internalError(c, err, "list fever unread item ids")
@@ -157,7 +161,7 @@ func (h *Handler) fever(c *gin.Context) {
 	}
 
 	if hasFeverFlag(c.Request.Form, "items") {
-		items, totalItems, err := h.buildFeverItemsPayload(c.Request.Form)
+		items, totalItems, err := h.buildFeverItemsPayload(c.Request.Form, h.mediaBaseURL(c))
 		if err != nil {
 			if errors.Is(err, strconv.ErrSyntax) || errors.Is(err, strconv.ErrRange) {
 				badRequestError(c, "invalid item filter")
@@ -315,7 +319,8 @@ func (h *Handler) markItemSaved(itemID int64) error {
 		return err
 	}
 
-	_, err = h.store.CreateBookmark(&item.ID, &item.FeedID, item.Link, item.Title, item.Content, item.PubDate, feed.Name)
+	content := sanitize.HTML(item.Content, item.Link)
+	_, err = h.store.CreateBookmark(&item.ID, &item.FeedID, item.Link, item.Title, content, item.PubDate, feed.Name, sanitize.PolicyVersion)
 	return err
 }
 
@@ -407,15 +412,30 @@ func (h *Handler) buildFeverFaviconsPayload() ([]feverFavicon, error) {
 		return nil, err
 	}
 
+	icons, err := h.store.ListFeedIcons()
+	if err != nil {
+		return nil, err
+	}
+	iconData := make(map[int64]string, len(icons))
+	for _, icon := range icons {
+		iconData[icon.FeedID] = icon.MimeType + ";base64," + base64.StdEncoding.EncodeToString(icon.Data)
+	}
+
+	// Feeds without a discovered icon keep the transparent placeholder so
+	// every favicon_id in the feeds payload resolves.
 	result := make([]feverFavicon, 0, len(feeds))
 	for _, feed := range feeds {
-		result = append(result, feverFavicon{ID: feed.ID, Data: feverTransparentGIFData})
+		data, ok := iconData[feed.ID]
+		if !ok {
+			data = feverTransparentGIFData
+		}
+		result = append(result, feverFavicon{ID: feed.ID, Data: data})
 	}
 
 	return result, nil
 }
 
-func (h *Handler) buildFeverItemsPayload(form url.Values) ([]feverItem, int, error) {
+func (h *Handler) buildFeverItemsPayload(form url.Values, mediaBase string) ([]feverItem, int, error) {
 	params, err := parseListFeverItemsParams(form)
 	if err != nil {
 		return nil, 0, err
@@ -448,11 +468,12 @@ func (h *Handler) buildFeverItemsPayload(form url.Values) ([]feverItem, int, err
 		}
 
 		_, isSaved := savedSet[item.ID]
+		h.rewriteItemMedia(item, mediaBase)
 		result = append(result, feverItem{
 			ID:            item.ID,
 			FeedID:        item.FeedID,
 			Title:         item.Title,
-			Author:        "",
+			Author:        item.Author,
 			HTML:          item.Content,
 			URL:           item.Link,
 			IsSaved:       boolToFeverInt(isSaved),
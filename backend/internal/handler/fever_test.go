@@ -11,6 +11,9 @@ import (
 	"testing"
 
 	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/events"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pull"
 	"github.com/0x2E/fusion/internal/store"
 )
 
@@ -18,7 +21,23 @@ type noopPuller struct{}
 
 func (noopPuller) RefreshFeed(context.Context, int64) error { return nil }
 
-func (noopPuller) RefreshAll(context.Context) (int, error) { return 0, nil }
+func (noopPuller) RefreshAll(context.Context, pull.Progress) (int, error) { return 0, nil }
+
+func (noopPuller) RefreshFeeds(context.Context, []int64, pull.Progress) (int, error) { return 0, nil }
+
+func (noopPuller) IngestPayload(context.Context, int64, []byte) (int, error) { return 0, nil }
+
+func (noopPuller) ReadableContent(context.Context, int64) (string, error) { return "", nil }
+
+func (noopPuller) ApplySchedule(int64) error { return nil }
+
+func (noopPuller) Reschedule(int64) {}
+
+func (noopPuller) Events() *events.Bus { return nil }
+
+func (noopPuller) Status() model.PullStatus {
+	return model.PullStatus{HostConcurrency: 2, HostDelay: 1, Hosts: []model.PullHostStatus{{Host: "example.com", Requests: 3}}}
+}
 
 func newFeverTestHandler(t *testing.T) (*Handler, *store.Store) {
 	t.Helper()
@@ -38,7 +57,7 @@ func newFeverTestHandler(t *testing.T) (*Handler, *store.Store) {
 		LoginBlock:     300,
 	}
 
-	h, err := New(st, cfg, noopPuller{})
+	h, err := New(st, cfg, noopPuller{}, nil)
 	if err != nil {
 		_ = st.Close()
 		t.Fatalf("new handler: %v", err)
@@ -236,7 +255,7 @@ func TestFeverMarkSavedLinksExistingBookmarkToItem(t *testing.T) {
 		t.Fatalf("create item: %v", err)
 	}
 
-	if _, err := st.CreateBookmark(nil, nil, item.Link, "Old Snapshot", "<p>old</p>", item.PubDate, feed.Name); err != nil {
+	if _, err := st.CreateBookmark(nil, nil, item.Link, "Old Snapshot", "<p>old</p>", item.PubDate, feed.Name, 0); err != nil {
 		t.Fatalf("create preexisting bookmark: %v", err)
 	}
 
@@ -349,7 +368,14 @@ func TestFeverItemsWithMaxIDZeroReturnsRecentItems(t *testing.T) {
 	if err != nil {
 		t.Fatalf("create feed: %v", err)
 	}
-	if _, err := st.CreateItem(feed.ID, "guid-3", "Entry 3", "https://example.com/entry-3", "<p>Hello 3</p>", 1700000002); err != nil {
+	if _, err := st.BatchCreateItemsIgnore(feed.ID, []store.BatchCreateItemInput{{
+		GUID:    "guid-3",
+		Title:   "Entry 3",
+		Link:    "https://example.com/entry-3",
+		Content: "<p>Hello 3</p>",
+		Author:  "Jane Doe",
+		PubDate: 1700000002,
+	}}); err != nil {
 		t.Fatalf("create item: %v", err)
 	}
 
@@ -383,6 +409,9 @@ func TestFeverItemsWithMaxIDZeroReturnsRecentItems(t *testing.T) {
 	if payload["total_items"] != float64(1) {
 		t.Fatalf("expected total_items=1, got %#v", payload["total_items"])
 	}
+	if item, _ := items[0].(map[string]any); item["author"] != "Jane Doe" {
+		t.Fatalf("expected item author, got %#v", items[0])
+	}
 }
 
 func TestParseListFeverItemsParamsLimitsWithIDs(t *testing.T) {
@@ -450,6 +479,35 @@ func TestFeverFaviconsHaveDataURI(t *testing.T) {
 	}
 }
 
+func TestFeverFaviconsUseCachedIcons(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	withIcon, err := st.CreateFeed(1, "With Icon", "https://example.com/a.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	if _, err := st.CreateFeed(1, "Without Icon", "https://example.com/b.xml", "", ""); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	if err := st.UpsertFeedIcon(withIcon.ID, store.UpsertFeedIconParams{MimeType: "image/png", Data: []byte("png"), RefreshAfter: 1}); err != nil {
+		t.Fatalf("upsert feed icon: %v", err)
+	}
+
+	favicons, err := h.buildFeverFaviconsPayload()
+	if err != nil {
+		t.Fatalf("buildFeverFaviconsPayload() failed: %v", err)
+	}
+	if len(favicons) != 2 {
+		t.Fatalf("expected 2 favicons, got %d", len(favicons))
+	}
+	if favicons[0].Data != "image/png;base64,cG5n" {
+		t.Errorf("expected cached icon data, got %q", favicons[0].Data)
+	}
+	if favicons[1].Data != feverTransparentGIFData {
+		t.Errorf("expected placeholder for feed without icon, got %q", favicons[1].Data)
+	}
+}
+
 func TestFeverMarkFeedReadRespectsBefore(t *testing.T) {
 	h, st := newFeverTestHandler(t)
 
@@ -0,0 +1,902 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0x2E/fusion/internal/auth"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"github.com/0x2E/fusion/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	greaderItemIDPrefix = "tag:google.com,2005:reader/item/"
+	greaderFeedPrefix   = "feed/"
+	greaderLabelPrefix  = "user/-/label/"
+
+	greaderStreamReadingList = "user/-/state/com.google/reading-list"
+	greaderStreamRead        = "user/-/state/com.google/read"
+	greaderStreamStarred     = "user/-/state/com.google/starred"
+	greaderStreamKeptUnread  = "user/-/state/com.google/kept-unread"
+
+	greaderDefaultCount   = 20
+	greaderMaxItemsCount  = 1000
+	greaderMaxItemIDCount = 10000
+)
+
+var errGReaderInvalidStream = errors.New("invalid stream")
+
+type greaderSubscription struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Categories []greaderCategory `json:"categories"`
+	URL        string            `json:"url"`
+	HTMLURL    string            `json:"htmlUrl"`
+	IconURL    string            `json:"iconUrl"`
+}
+
+type greaderCategory struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type greaderTag struct {
+	ID   string `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+type greaderItemRef struct {
+	ID              string   `json:"id"`
+	DirectStreamIDs []string `json:"directStreamIds"`
+	TimestampUsec   string   `json:"timestampUsec"`
+}
+
+type greaderLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type greaderContent struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type greaderOrigin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type greaderItem struct {
+	ID            string         `json:"id"`
+	CrawlTimeMsec string         `json:"crawlTimeMsec"`
+	TimestampUsec string         `json:"timestampUsec"`
+	Published     int64          `json:"published"`
+	Updated       int64          `json:"updated"`
+	Title         string         `json:"title"`
+	Author        string         `json:"author"`
+	Canonical     []greaderLink  `json:"canonical"`
+	Alternate     []greaderLink  `json:"alternate"`
+	Summary       greaderContent `json:"summary"`
+	Categories    []string       `json:"categories"`
+	Origin        greaderOrigin  `json:"origin"`
+}
+
+// deriveGReaderAuthToken returns the stateless token handed out by ClientLogin.
+// It changes whenever the username or password changes, revoking old clients.
+func deriveGReaderAuthToken(username, password string) string {
+	sum := sha256.Sum256([]byte("greader:" + strings.TrimSpace(username) + ":" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+func (h *Handler) greaderClientLogin(c *gin.Context) {
+	ip := c.ClientIP()
+	allowed, retryAfter := h.limiter.allow(ip, time.Now())
+	if !allowed {
+		tooManyRequestsError(c, retryAfter)
+		return
+	}
+
+	if err := c.Request.ParseForm(); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+
+	// Credentials are read from the POST body only, so they never end up in
+	// URLs and access logs.
+	email := strings.TrimSpace(c.Request.PostForm.Get("Email"))
+	passwd := c.Request.PostForm.Get("Passwd")
+	usernameMatches := subtle.ConstantTimeCompare([]byte(email), []byte(strings.TrimSpace(h.config.FeverUsername))) == 1
+	if !usernameMatches || auth.CheckPassword(h.passwordHash, passwd) != nil {
+		h.limiter.recordFailure(ip, time.Now())
+		c.String(http.StatusUnauthorized, "Error=BadAuthentication\n")
+		return
+	}
+	h.limiter.recordSuccess(ip)
+
+	token := h.greaderAuthToken
+	c.String(http.StatusOK, "SID=%s\nLSID=%s\nAuth=%s\n", token, token, token)
+}
+
+func (h *Handler) greaderAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		allowed, retryAfter := h.limiter.allow(ip, time.Now())
+		if !allowed {
+			tooManyRequestsError(c, retryAfter)
+			c.Abort()
+			return
+		}
+
+		if !h.verifyGReaderToken(parseGReaderAuthHeader(c.GetHeader("Authorization"))) {
+			h.limiter.recordFailure(ip, time.Now())
+			c.String(http.StatusUnauthorized, "Unauthorized")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func parseGReaderAuthHeader(header string) string {
+	header = strings.TrimSpace(header)
+	const prefix = "GoogleLogin auth="
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+func (h *Handler) verifyGReaderToken(token string) bool {
+	if token == "" || h.greaderAuthToken == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.greaderAuthToken)) == 1
+}
+
+func (h *Handler) greaderToken(c *gin.Context) {
+	c.String(http.StatusOK, h.greaderAuthToken)
+}
+
+func (h *Handler) greaderUserInfo(c *gin.Context) {
+	username := strings.TrimSpace(h.config.FeverUsername)
+	c.JSON(http.StatusOK, gin.H{
+		"userId":        "1",
+		"userName":      username,
+		"userProfileId": "1",
+		"userEmail":     username,
+	})
+}
+
+func (h *Handler) greaderSubscriptionList(c *gin.Context) {
+	feeds, err := h.store.ListFeedRefs()
+	if err != nil {
+		internalError(c, err, "list greader subscriptions")
+		return
+	}
+
+	groupNames, err := h.greaderGroupNames()
+	if err != nil {
+		internalError(c, err, "list greader groups")
+		return
+	}
+
+	subscriptions := make([]greaderSubscription, 0, len(feeds))
+	for _, feed := range feeds {
+		name := groupNames[feed.GroupID]
+		subscriptions = append(subscriptions, greaderSubscription{
+			ID:         greaderFeedStreamID(feed.ID),
+			Title:      feed.Name,
+			Categories: []greaderCategory{{ID: greaderLabelPrefix + name, Label: name}},
+			URL:        feed.Link,
+			HTMLURL:    feed.SiteURL,
+			IconURL:    "",
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subscriptions})
+}
+
+func (h *Handler) greaderTagList(c *gin.Context) {
+	groups, err := h.store.ListGroups()
+	if err != nil {
+		internalError(c, err, "list greader tags")
+		return
+	}
+
+	tags := make([]greaderTag, 0, len(groups)+1)
+	tags = append(tags, greaderTag{ID: greaderStreamStarred})
+	for _, group := range groups {
+		tags = append(tags, greaderTag{ID: greaderLabelPrefix + group.Name, Type: "folder"})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func (h *Handler) greaderSubscriptionEdit(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+	form := c.Request.Form
+
+	action := strings.TrimSpace(form.Get("ac"))
+	streams := form["s"]
+	if len(streams) == 0 {
+		badRequestError(c, "missing s")
+		return
+	}
+
+	title := strings.TrimSpace(form.Get("t"))
+	addLabel := strings.TrimPrefix(strings.TrimSpace(form.Get("a")), greaderLabelPrefix)
+	removeLabel := strings.TrimPrefix(strings.TrimSpace(form.Get("r")), greaderLabelPrefix)
+
+	for _, stream := range streams {
+		stream = strings.TrimSpace(stream)
+		if !strings.HasPrefix(stream, greaderFeedPrefix) {
+			badRequestError(c, "invalid s")
+			return
+		}
+
+		switch action {
+		case "subscribe":
+			if _, msg, err := h.greaderSubscribe(c.Request.Context(), strings.TrimPrefix(stream, greaderFeedPrefix), title, addLabel); err != nil {
+				internalError(c, err, "greader subscribe")
+				return
+			} else if msg != "" {
+				badRequestError(c, msg)
+				return
+			}
+		case "unsubscribe":
+			feed, err := h.greaderLookupFeed(stream)
+			if err != nil {
+				h.greaderFeedLookupError(c, err)
+				return
+			}
+			if err := h.store.DeleteFeed(feed.ID); err != nil {
+				internalError(c, err, "greader unsubscribe")
+				return
+			}
+		case "edit":
+			feed, err := h.greaderLookupFeed(stream)
+			if err != nil {
+				h.greaderFeedLookupError(c, err)
+				return
+			}
+
+			params := store.UpdateFeedParams{}
+			if title != "" {
+				params.Name = &title
+			}
+			if addLabel != "" {
+				groupID, err := h.greaderEnsureGroup(addLabel)
+				if err != nil {
+					internalError(c, err, "greader ensure group")
+					return
+				}
+				params.GroupID = &groupID
+			} else if removeLabel != "" {
+				groupID := store.DefaultGroupID
+				params.GroupID = &groupID
+			}
+
+			if err := h.store.UpdateFeed(feed.ID, params); err != nil {
+				internalError(c, err, "greader edit subscription")
+				return
+			}
+			h.puller.Reschedule(feed.ID)
+		default:
+			badRequestError(c, "invalid ac")
+			return
+		}
+	}
+
+	c.String(http.StatusOK, "OK")
+}
+
+func (h *Handler) greaderQuickAdd(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+
+	target := strings.TrimPrefix(strings.TrimSpace(c.Request.Form.Get("quickadd")), greaderFeedPrefix)
+	feed, msg, err := h.greaderSubscribe(c.Request.Context(), target, "", "")
+	if err != nil {
+		internalError(c, err, "greader quickadd")
+		return
+	}
+	if msg != "" {
+		badRequestError(c, msg)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"numResults": 1,
+		"query":      feed.Link,
+		"streamId":   greaderFeedStreamID(feed.ID),
+		"streamName": feed.Name,
+	})
+}
+
+// greaderSubscribe creates a feed for link, or returns the existing feed when
+// link is already subscribed. A non-empty message reports a client error; err
+// reports an internal failure.
+func (h *Handler) greaderSubscribe(ctx context.Context, link, title, label string) (*model.Feed, string, error) {
+	link = strings.TrimSpace(link)
+	if err := httpc.ValidateRequestURL(ctx, link, h.config.AllowPrivateFeeds); err != nil {
+		return nil, "invalid link", nil
+	}
+
+	if feed, err := h.greaderLookupFeed(link); err == nil {
+		return feed, "", nil
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return nil, "", err
+	}
+
+	if title == "" {
+		title = link
+	}
+
+	groupID := store.DefaultGroupID
+	if label != "" {
+		id, err := h.greaderEnsureGroup(label)
+		if err != nil {
+			return nil, "", err
+		}
+		groupID = id
+	}
+
+	feed, err := h.store.CreateFeed(groupID, title, link, "", "")
+	if err != nil {
+		// A concurrent subscribe may have created the feed since the lookup.
+		if existing, lookupErr := h.greaderLookupFeed(link); lookupErr == nil {
+			return existing, "", nil
+		}
+		return nil, "", err
+	}
+
+	h.startInitialPulls(model.JobInitialPull, []int64{feed.ID})
+	return feed, "", nil
+}
+
+func (h *Handler) greaderEnsureGroup(name string) (int64, error) {
+	groups, err := h.store.ListGroups()
+	if err != nil {
+		return 0, err
+	}
+	for _, group := range groups {
+		if group.Name == name {
+			return group.ID, nil
+		}
+	}
+
+	group, err := h.store.CreateGroup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return group.ID, nil
+}
+
+// greaderLookupFeed resolves "feed/<id>" as well as "feed/<url>", the form
+// clients use for subscriptions they created themselves.
+func (h *Handler) greaderLookupFeed(stream string) (*model.Feed, error) {
+	ref := strings.TrimPrefix(strings.TrimSpace(stream), greaderFeedPrefix)
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return h.store.GetFeed(id)
+	}
+
+	return h.store.GetFeedByLink(ref)
+}
+
+func (h *Handler) greaderFeedLookupError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		notFoundError(c, "feed")
+		return
+	}
+	internalError(c, err, "greader lookup feed")
+}
+
+func (h *Handler) greaderStreamContents(c *gin.Context) {
+	streamID := strings.TrimPrefix(c.Param("streamId"), "/")
+	if streamID == "" {
+		streamID = c.Query("s")
+	}
+
+	params, msg, err := h.greaderItemsParams(streamID, c.Request.URL.Query(), greaderMaxItemsCount)
+	if err != nil {
+		internalError(c, err, "resolve greader stream")
+		return
+	}
+	if msg != "" {
+		badRequestError(c, msg)
+		return
+	}
+
+	items, err := h.store.ListItems(params)
+	if err != nil {
+		internalError(c, err, "list greader items")
+		return
+	}
+
+	payload, err := h.buildGReaderItems(items)
+	if err != nil {
+		internalError(c, err, "build greader items")
+		return
+	}
+
+	response := gin.H{
+		"id":      streamID,
+		"updated": time.Now().Unix(),
+		"items":   payload,
+	}
+	if continuation := greaderContinuation(items, params.Limit); continuation != "" {
+		response["continuation"] = continuation
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) greaderStreamItemIDs(c *gin.Context) {
+	params, msg, err := h.greaderItemsParams(c.Query("s"), c.Request.URL.Query(), greaderMaxItemIDCount)
+	if err != nil {
+		internalError(c, err, "resolve greader stream")
+		return
+	}
+	if msg != "" {
+		badRequestError(c, msg)
+		return
+	}
+	params.OmitContent = true
+
+	items, err := h.store.ListItems(params)
+	if err != nil {
+		internalError(c, err, "list greader item ids")
+		return
+	}
+
+	refs := make([]greaderItemRef, 0, len(items))
+	for _, item := range items {
+		refs = append(refs, greaderItemRef{
+			ID:              strconv.FormatInt(item.ID, 10),
+			DirectStreamIDs: []string{},
+			TimestampUsec:   strconv.FormatInt(greaderItemTime(item)*1_000_000, 10),
+		})
+	}
+
+	response := gin.H{"itemRefs": refs}
+	if continuation := greaderContinuation(items, params.Limit); continuation != "" {
+		response["continuation"] = continuation
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) greaderStreamItemsContents(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+
+	ids, err := parseGReaderItemIDs(c.Request.Form["i"])
+	if err != nil || len(ids) == 0 || len(ids) > greaderMaxItemsCount {
+		badRequestError(c, "invalid i")
+		return
+	}
+
+	items, err := h.store.ListFeverItems(store.ListFeverItemsParams{WithIDs: ids})
+	if err != nil {
+		internalError(c, err, "list greader items by id")
+		return
+	}
+
+	payload, err := h.buildGReaderItems(items)
+	if err != nil {
+		internalError(c, err, "build greader items")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":      greaderStreamReadingList,
+		"updated": time.Now().Unix(),
+		"items":   payload,
+	})
+}
+
+func (h *Handler) greaderEditTag(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+	form := c.Request.Form
+
+	ids, err := parseGReaderItemIDs(form["i"])
+	if err != nil || len(ids) == 0 || len(ids) > maxBatchUpdateIDs {
+		badRequestError(c, "invalid i")
+		return
+	}
+
+	for _, tag := range form["a"] {
+		if err := h.greaderApplyTag(ids, strings.TrimSpace(tag), true); err != nil {
+			internalError(c, err, "greader add tag")
+			return
+		}
+	}
+	for _, tag := range form["r"] {
+		if err := h.greaderApplyTag(ids, strings.TrimSpace(tag), false); err != nil {
+			internalError(c, err, "greader remove tag")
+			return
+		}
+	}
+	h.publishUnreadCounts()
+
+	c.String(http.StatusOK, "OK")
+}
+
+// greaderApplyTag maps state tags onto Fusion item state. Item labels are not
+// supported and are ignored.
+func (h *Handler) greaderApplyTag(ids []int64, tag string, add bool) error {
+	switch tag {
+	case greaderStreamRead:
+		return h.store.BatchUpdateItemsUnread(ids, !add)
+	case greaderStreamKeptUnread:
+		if add {
+			return h.store.BatchUpdateItemsUnread(ids, true)
+		}
+		return nil
+	case greaderStreamStarred:
+		for _, id := range ids {
+			var err error
+			if add {
+				err = h.markItemSaved(id)
+			} else {
+				err = h.markItemUnsaved(id)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (h *Handler) greaderMarkAllAsRead(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+	form := c.Request.Form
+
+	before := time.Now().Unix()
+	if ts := strings.TrimSpace(form.Get("ts")); ts != "" {
+		parsed, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil || parsed <= 0 {
+			badRequestError(c, "invalid ts")
+			return
+		}
+		before = normalizeGReaderTimestamp(parsed)
+	}
+
+	streamID := strings.TrimSpace(form.Get("s"))
+	switch {
+	case streamID == greaderStreamReadingList:
+		err := h.store.MarkAllAsReadBefore(before)
+		if err != nil {
+			internalError(c, err, "greader mark all as read")
+			return
+		}
+	case strings.HasPrefix(streamID, greaderFeedPrefix):
+		feed, err := h.greaderLookupFeed(streamID)
+		if err != nil {
+			h.greaderFeedLookupError(c, err)
+			return
+		}
+		if err := h.store.MarkFeedAsReadBefore(feed.ID, before); err != nil {
+			internalError(c, err, "greader mark feed as read")
+			return
+		}
+	case strings.HasPrefix(streamID, greaderLabelPrefix):
+		groupID, err := h.greaderLookupGroup(strings.TrimPrefix(streamID, greaderLabelPrefix))
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				notFoundError(c, "group")
+				return
+			}
+			internalError(c, err, "greader lookup group")
+			return
+		}
+		if err := h.store.MarkGroupAsReadBefore(groupID, before); err != nil {
+			internalError(c, err, "greader mark group as read")
+			return
+		}
+	default:
+		badRequestError(c, "invalid s")
+		return
+	}
+	h.publishUnreadCounts()
+
+	c.String(http.StatusOK, "OK")
+}
+
+// greaderItemsParams translates GReader stream query parameters (n, r, ot, nt,
+// xt, it, c) into store filters. A non-empty message reports a client error.
+func (h *Handler) greaderItemsParams(streamID string, query url.Values, maxCount int) (store.ListItemsParams, string, error) {
+	params := store.ListItemsParams{Limit: greaderDefaultCount}
+
+	if err := h.applyGReaderStream(&params, strings.TrimSpace(streamID), true); err != nil {
+		if errors.Is(err, errGReaderInvalidStream) || errors.Is(err, store.ErrNotFound) {
+			return params, "invalid s", nil
+		}
+		return params, "", err
+	}
+
+	for _, target := range query["xt"] {
+		if err := h.applyGReaderStream(&params, strings.TrimSpace(target), false); err != nil {
+			if errors.Is(err, errGReaderInvalidStream) || errors.Is(err, store.ErrNotFound) {
+				return params, "invalid xt", nil
+			}
+			return params, "", err
+		}
+	}
+	for _, target := range query["it"] {
+		if err := h.applyGReaderStream(&params, strings.TrimSpace(target), true); err != nil {
+			if errors.Is(err, errGReaderInvalidStream) || errors.Is(err, store.ErrNotFound) {
+				return params, "invalid it", nil
+			}
+			return params, "", err
+		}
+	}
+
+	if n := query.Get("n"); n != "" {
+		val, err := strconv.Atoi(n)
+		if err != nil || val <= 0 {
+			return params, "invalid n", nil
+		}
+		params.Limit = min(val, maxCount)
+	}
+
+	params.SortAsc = query.Get("r") == "o"
+
+	if ot := query.Get("ot"); ot != "" {
+		val, err := strconv.ParseInt(ot, 10, 64)
+		if err != nil {
+			return params, "invalid ot", nil
+		}
+		params.Since = &val
+	}
+	if nt := query.Get("nt"); nt != "" {
+		val, err := strconv.ParseInt(nt, 10, 64)
+		if err != nil {
+			return params, "invalid nt", nil
+		}
+		params.Until = &val
+	}
+
+	if continuation := query.Get("c"); continuation != "" {
+		pubDate, id, err := parseCursor(continuation)
+		if err != nil {
+			return params, "invalid c", nil
+		}
+		params.BeforePubDate = &pubDate
+		params.BeforeID = &id
+	}
+
+	return params, "", nil
+}
+
+// applyGReaderStream narrows params to streamID. include=false applies the
+// stream as an exclusion, which is only meaningful for state streams.
+func (h *Handler) applyGReaderStream(params *store.ListItemsParams, streamID string, include bool) error {
+	switch {
+	case streamID == greaderStreamReadingList:
+		if !include {
+			return errGReaderInvalidStream
+		}
+		return nil
+	case streamID == greaderStreamRead:
+		unread := !include
+		params.Unread = &unread
+		return nil
+	case streamID == greaderStreamKeptUnread:
+		unread := include
+		params.Unread = &unread
+		return nil
+	case streamID == greaderStreamStarred:
+		params.Bookmarked = &include
+		return nil
+	case !include:
+		return errGReaderInvalidStream
+	case strings.HasPrefix(streamID, greaderFeedPrefix):
+		feed, err := h.greaderLookupFeed(streamID)
+		if err != nil {
+			return err
+		}
+		params.FeedID = &feed.ID
+		return nil
+	case strings.HasPrefix(streamID, greaderLabelPrefix):
+		groupID, err := h.greaderLookupGroup(strings.TrimPrefix(streamID, greaderLabelPrefix))
+		if err != nil {
+			return err
+		}
+		params.GroupID = &groupID
+		return nil
+	default:
+		return errGReaderInvalidStream
+	}
+}
+
+func (h *Handler) greaderLookupGroup(name string) (int64, error) {
+	groups, err := h.store.ListGroups()
+	if err != nil {
+		return 0, err
+	}
+	for _, group := range groups {
+		if group.Name == name {
+			return group.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: group", store.ErrNotFound)
+}
+
+func (h *Handler) greaderGroupNames() (map[int64]string, error) {
+	groups, err := h.store.ListGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string, len(groups))
+	for _, group := range groups {
+		names[group.ID] = group.Name
+	}
+
+	return names, nil
+}
+
+func (h *Handler) buildGReaderItems(items []*model.Item) ([]greaderItem, error) {
+	feeds, err := h.store.ListFeedRefs()
+	if err != nil {
+		return nil, err
+	}
+	feedsByID := make(map[int64]*model.Feed, len(feeds))
+	for _, feed := range feeds {
+		feedsByID[feed.ID] = feed
+	}
+
+	groupNames, err := h.greaderGroupNames()
+	if err != nil {
+		return nil, err
+	}
+
+	savedIDs, err := h.store.ListSavedItemIDs()
+	if err != nil {
+		return nil, err
+	}
+	savedSet := make(map[int64]struct{}, len(savedIDs))
+	for _, id := range savedIDs {
+		savedSet[id] = struct{}{}
+	}
+
+	result := make([]greaderItem, 0, len(items))
+	for _, item := range items {
+		categories := []string{greaderStreamReadingList}
+		origin := greaderOrigin{StreamID: greaderFeedStreamID(item.FeedID)}
+		if feed, ok := feedsByID[item.FeedID]; ok {
+			origin.Title = feed.Name
+			origin.HTMLURL = feed.SiteURL
+			if name, ok := groupNames[feed.GroupID]; ok {
+				categories = append(categories, greaderLabelPrefix+name)
+			}
+		}
+		if !item.Unread {
+			categories = append(categories, greaderStreamRead)
+		}
+		if _, saved := savedSet[item.ID]; saved {
+			categories = append(categories, greaderStreamStarred)
+		}
+
+		published := greaderItemTime(item)
+		result = append(result, greaderItem{
+			ID:            formatGReaderItemID(item.ID),
+			CrawlTimeMsec: strconv.FormatInt(item.CreatedAt*1000, 10),
+			TimestampUsec: strconv.FormatInt(published*1_000_000, 10),
+			Published:     published,
+			Updated:       published,
+			Title:         item.Title,
+			Author:        item.Author,
+			Canonical:     []greaderLink{{Href: item.Link}},
+			Alternate:     []greaderLink{{Href: item.Link, Type: "text/html"}},
+			Summary:       greaderContent{Direction: "ltr", Content: item.Content},
+			Categories:    categories,
+			Origin:        origin,
+		})
+	}
+
+	return result, nil
+}
+
+func greaderItemTime(item *model.Item) int64 {
+	if item.PubDate > 0 {
+		return item.PubDate
+	}
+
+	return item.CreatedAt
+}
+
+func greaderContinuation(items []*model.Item, limit int) string {
+	if limit <= 0 || len(items) < limit {
+		return ""
+	}
+
+	last := items[len(items)-1]
+	return fmt.Sprintf("%d_%d", last.PubDate, last.ID)
+}
+
+func greaderFeedStreamID(feedID int64) string {
+	return greaderFeedPrefix + strconv.FormatInt(feedID, 10)
+}
+
+func formatGReaderItemID(id int64) string {
+	return fmt.Sprintf("%s%016x", greaderItemIDPrefix, id)
+}
+
+// parseGReaderItemIDs accepts both the long hex form
+// ("tag:google.com,2005:reader/item/000000000000001f") and the short decimal form.
+func parseGReaderItemIDs(values []string) ([]int64, error) {
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		var id int64
+		var err error
+		if hexID, ok := strings.CutPrefix(value, greaderItemIDPrefix); ok {
+			var parsed uint64
+			parsed, err = strconv.ParseUint(hexID, 16, 64)
+			id = int64(parsed)
+		} else {
+			id, err = strconv.ParseInt(value, 10, 64)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if id <= 0 {
+			return nil, fmt.Errorf("invalid id")
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// normalizeGReaderTimestamp converts ts, which clients send in seconds,
+// milliseconds or microseconds, to Unix seconds.
+func normalizeGReaderTimestamp(ts int64) int64 {
+	switch {
+	case ts >= 1e15:
+		return ts / 1_000_000
+	case ts >= 1e12:
+		return ts / 1000
+	default:
+		return ts
+	}
+}
@@ -0,0 +1,355 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+const greaderTestBase = "/api/greader/reader/api/0"
+
+func newGReaderTestRouter(t *testing.T, h *Handler) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	return h.SetupRouter()
+}
+
+func greaderHeaders(h *Handler) map[string]string {
+	return map[string]string{"Authorization": "GoogleLogin auth=" + h.greaderAuthToken}
+}
+
+func greaderFormHeaders(h *Handler) map[string]string {
+	headers := greaderHeaders(h)
+	headers["Content-Type"] = "application/x-www-form-urlencoded"
+	return headers
+}
+
+func mustCreateGReaderFeed(t *testing.T, st *store.Store, groupID int64, name, link string) *model.Feed {
+	t.Helper()
+
+	feed, err := st.CreateFeed(groupID, name, link, "", "")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	return feed
+}
+
+func TestGReaderClientLogin(t *testing.T) {
+	h, _ := newFeverTestHandler(t)
+	r := newGReaderTestRouter(t, h)
+
+	form := url.Values{"Email": {"fusion"}, "Passwd": {"wrong"}}
+	w := performRequest(r, http.MethodPost, "/api/greader/accounts/ClientLogin", strings.NewReader(form.Encode()), map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 for wrong password, got %d", w.Code)
+	}
+
+	form.Set("Passwd", "secret")
+	w = performRequest(r, http.MethodPost, "/api/greader/accounts/ClientLogin", strings.NewReader(form.Encode()), map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "Auth="+h.greaderAuthToken+"\n") {
+		t.Fatalf("expected auth token in body, got %q", w.Body.String())
+	}
+
+	// Credentials in the query string would be logged; they are not accepted.
+	w = performRequest(r, http.MethodPost, "/api/greader/accounts/ClientLogin?"+form.Encode(), nil, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 for query credentials, got %d", w.Code)
+	}
+	w = performRequest(r, http.MethodGet, "/api/greader/accounts/ClientLogin?"+form.Encode(), nil, nil)
+	if w.Code == http.StatusOK {
+		t.Fatal("expected GET ClientLogin to be rejected")
+	}
+
+	w = performRequest(r, http.MethodGet, greaderTestBase+"/token", nil, nil)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 without token, got %d", w.Code)
+	}
+
+	w = performRequest(r, http.MethodGet, greaderTestBase+"/token", nil, greaderHeaders(h))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 with token, got %d", w.Code)
+	}
+}
+
+func TestGReaderStreamContentsFiltersAndContinuation(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+	r := newGReaderTestRouter(t, h)
+
+	group, err := st.CreateGroup("Tech")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	feed := mustCreateGReaderFeed(t, st, group.ID, "Feed", "https://example.com/feed.xml")
+	other := mustCreateGReaderFeed(t, st, 1, "Other", "https://example.com/other.xml")
+
+	first, err := st.CreateItem(feed.ID, "a", "First", "https://example.com/a", "A", 100)
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	second, err := st.CreateItem(feed.ID, "b", "Second", "https://example.com/b", "B", 200)
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	third, err := st.CreateItem(feed.ID, "c", "Third", "https://example.com/c", "C", 300)
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	if _, err := st.CreateItem(other.ID, "d", "Other", "https://example.com/d", "D", 400); err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	if err := st.UpdateItemUnread(second.ID, false); err != nil {
+		t.Fatalf("UpdateItemUnread: %v", err)
+	}
+
+	type streamResponse struct {
+		Items []struct {
+			ID         string   `json:"id"`
+			Title      string   `json:"title"`
+			Categories []string `json:"categories"`
+			Origin     struct {
+				StreamID string `json:"streamId"`
+			} `json:"origin"`
+		} `json:"items"`
+		Continuation string `json:"continuation"`
+	}
+
+	w := performRequest(r, http.MethodGet, greaderTestBase+"/stream/contents/user/-/label/Tech?n=2", nil, greaderHeaders(h))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	var page streamResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(page.Items) != 2 || page.Items[0].ID != formatGReaderItemID(third.ID) || page.Items[1].ID != formatGReaderItemID(second.ID) {
+		t.Fatalf("unexpected first page: %+v", page.Items)
+	}
+	if page.Items[0].Origin.StreamID != greaderFeedStreamID(feed.ID) {
+		t.Errorf("unexpected origin stream: %q", page.Items[0].Origin.StreamID)
+	}
+	if !containsString(page.Items[1].Categories, greaderStreamRead) || !containsString(page.Items[1].Categories, "user/-/label/Tech") {
+		t.Errorf("expected read and label categories, got %v", page.Items[1].Categories)
+	}
+	if page.Continuation == "" {
+		t.Fatal("expected continuation for full page")
+	}
+
+	w = performRequest(r, http.MethodGet, greaderTestBase+"/stream/contents/user/-/label/Tech?n=2&c="+page.Continuation, nil, greaderHeaders(h))
+	page = streamResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != formatGReaderItemID(first.ID) || page.Continuation != "" {
+		t.Fatalf("unexpected second page: %+v continuation=%q", page.Items, page.Continuation)
+	}
+
+	query := url.Values{
+		"s":  {greaderStreamReadingList},
+		"xt": {greaderStreamRead},
+		"r":  {"o"},
+		"ot": {"100"},
+	}
+	w = performRequest(r, http.MethodGet, greaderTestBase+"/stream/items/ids?"+query.Encode(), nil, greaderHeaders(h))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	var ids struct {
+		ItemRefs []greaderItemRef `json:"itemRefs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &ids); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(ids.ItemRefs) != 2 || ids.ItemRefs[0].ID != "3" || ids.ItemRefs[1].ID != "4" {
+		t.Fatalf("unexpected item refs: %+v", ids.ItemRefs)
+	}
+}
+
+func TestGReaderEditTagAndMarkAllAsRead(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+	r := newGReaderTestRouter(t, h)
+
+	feed := mustCreateGReaderFeed(t, st, 1, "Feed", "https://example.com/feed.xml")
+	older, err := st.CreateItem(feed.ID, "a", "Older", "https://example.com/a", "A", 100)
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	newer, err := st.CreateItem(feed.ID, "b", "Newer", "https://example.com/b", "B", 200)
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+
+	form := url.Values{
+		"i": {formatGReaderItemID(older.ID), "2"},
+		"a": {greaderStreamRead, greaderStreamStarred},
+	}
+	w := performRequest(r, http.MethodPost, greaderTestBase+"/edit-tag", strings.NewReader(form.Encode()), greaderFormHeaders(h))
+	if w.Code != http.StatusOK || w.Body.String() != "OK" {
+		t.Fatalf("expected OK, got %d %q", w.Code, w.Body.String())
+	}
+
+	for _, id := range []int64{older.ID, newer.ID} {
+		item, err := st.GetItem(id)
+		if err != nil {
+			t.Fatalf("GetItem: %v", err)
+		}
+		if item.Unread {
+			t.Errorf("expected item %d to be read", id)
+		}
+	}
+	saved, err := st.ListSavedItemIDs()
+	if err != nil {
+		t.Fatalf("ListSavedItemIDs: %v", err)
+	}
+	if len(saved) != 2 {
+		t.Fatalf("expected 2 saved items, got %v", saved)
+	}
+
+	form = url.Values{"i": {"1", "2"}, "r": {greaderStreamRead, greaderStreamStarred}}
+	w = performRequest(r, http.MethodPost, greaderTestBase+"/edit-tag", strings.NewReader(form.Encode()), greaderFormHeaders(h))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	saved, err = st.ListSavedItemIDs()
+	if err != nil {
+		t.Fatalf("ListSavedItemIDs: %v", err)
+	}
+	if len(saved) != 0 {
+		t.Fatalf("expected no saved items, got %v", saved)
+	}
+
+	form = url.Values{"s": {greaderFeedStreamID(feed.ID)}, "ts": {"150"}}
+	w = performRequest(r, http.MethodPost, greaderTestBase+"/mark-all-as-read", strings.NewReader(form.Encode()), greaderFormHeaders(h))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+
+	olderItem, err := st.GetItem(older.ID)
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+	newerItem, err := st.GetItem(newer.ID)
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+	if olderItem.Unread || !newerItem.Unread {
+		t.Fatalf("expected only older item marked read, got older.unread=%v newer.unread=%v", olderItem.Unread, newerItem.Unread)
+	}
+}
+
+func TestGReaderSubscriptionEditMovesFeedToLabel(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+	r := newGReaderTestRouter(t, h)
+
+	feed := mustCreateGReaderFeed(t, st, 1, "Feed", "https://example.com/feed.xml")
+
+	form := url.Values{
+		"ac": {"edit"},
+		"s":  {greaderFeedPrefix + feed.Link},
+		"t":  {"Renamed"},
+		"a":  {"user/-/label/News"},
+	}
+	w := performRequest(r, http.MethodPost, greaderTestBase+"/subscription/edit", strings.NewReader(form.Encode()), greaderFormHeaders(h))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+
+	updated, err := st.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+	group, err := st.GetGroup(updated.GroupID)
+	if err != nil {
+		t.Fatalf("GetGroup: %v", err)
+	}
+	if updated.Name != "Renamed" || group.Name != "News" {
+		t.Fatalf("unexpected feed after edit: name=%q group=%q", updated.Name, group.Name)
+	}
+
+	form = url.Values{"ac": {"unsubscribe"}, "s": {greaderFeedStreamID(feed.ID)}}
+	w = performRequest(r, http.MethodPost, greaderTestBase+"/subscription/edit", strings.NewReader(form.Encode()), greaderFormHeaders(h))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	if _, err := st.GetFeed(feed.ID); err == nil {
+		t.Fatal("expected feed to be deleted")
+	}
+}
+
+func TestGReaderSubscribeTwiceReturnsExistingFeed(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+	h.config.AllowPrivateFeeds = true
+	r := newGReaderTestRouter(t, h)
+
+	const link = "http://127.0.0.1/feed.xml"
+	for i := range 2 {
+		form := url.Values{"ac": {"subscribe"}, "s": {greaderFeedPrefix + link}}
+		w := performRequest(r, http.MethodPost, greaderTestBase+"/subscription/edit", strings.NewReader(form.Encode()), greaderFormHeaders(h))
+		if w.Code != http.StatusOK {
+			t.Fatalf("subscribe #%d: expected status 200, got %d (body=%s)", i+1, w.Code, w.Body.String())
+		}
+	}
+
+	form := url.Values{"quickadd": {link}}
+	w := performRequest(r, http.MethodPost, greaderTestBase+"/subscription/quickadd", strings.NewReader(form.Encode()), greaderFormHeaders(h))
+	if w.Code != http.StatusOK {
+		t.Fatalf("quickadd: expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+
+	feeds, err := st.ListFeeds()
+	if err != nil {
+		t.Fatalf("ListFeeds: %v", err)
+	}
+	if len(feeds) != 1 {
+		t.Fatalf("expected one feed, got %d", len(feeds))
+	}
+
+	var resp struct {
+		StreamID string `json:"streamId"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode quickadd response: %v", err)
+	}
+	if resp.StreamID != greaderFeedStreamID(feeds[0].ID) {
+		t.Fatalf("quickadd streamId = %q, want existing feed %q", resp.StreamID, greaderFeedStreamID(feeds[0].ID))
+	}
+}
+
+func TestParseGReaderItemIDs(t *testing.T) {
+	ids, err := parseGReaderItemIDs([]string{"tag:google.com,2005:reader/item/000000000000001f", "42"})
+	if err != nil {
+		t.Fatalf("parseGReaderItemIDs() failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 31 || ids[1] != 42 {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+
+	if _, err := parseGReaderItemIDs([]string{"tag:google.com,2005:reader/item/zz"}); err == nil {
+		t.Fatal("expected error for malformed id")
+	}
+
+	if got := normalizeGReaderTimestamp(1_700_000_000_000_000); got != 1_700_000_000 {
+		t.Errorf("normalizeGReaderTimestamp(us) = %d", got)
+	}
+	if got := normalizeGReaderTimestamp(1_700_000_000_000); got != 1_700_000_000 {
+		t.Errorf("normalizeGReaderTimestamp(ms) = %d", got)
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
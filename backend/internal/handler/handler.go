@@ -11,34 +11,56 @@ import (
 
 	"github.com/0x2E/fusion/internal/auth"
 	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/events"
+	"github.com/0x2E/fusion/internal/jobs"
+	"github.com/0x2E/fusion/internal/mediaproxy"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pull"
 	"github.com/0x2E/fusion/internal/store"
+	"github.com/0x2E/fusion/internal/websub"
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	store        *store.Store
-	config       *config.Config
-	passwordHash string // bcrypt hash computed at startup
-	feverAPIKey  string // md5(username:password) used by Fever API
-	allowAnonAPI bool   // true when both password and OIDC auth are disabled
-	puller       interface {
+	store            *store.Store
+	config           *config.Config
+	passwordHash     string // bcrypt hash computed at startup
+	feverAPIKey      string // md5(username:password) used by Fever API
+	greaderAuthToken string // sha256-derived token returned by GReader ClientLogin
+	allowAnonAPI     bool   // true when both password and OIDC auth are disabled
+	puller           interface {
 		RefreshFeed(ctx context.Context, feedID int64) error
-		RefreshAll(ctx context.Context) (int, error)
+		RefreshAll(ctx context.Context, progress pull.Progress) (int, error)
+		RefreshFeeds(ctx context.Context, feedIDs []int64, progress pull.Progress) (int, error)
+		IngestPayload(ctx context.Context, feedID int64, body []byte) (int, error)
+		ReadableContent(ctx context.Context, itemID int64) (string, error)
+		ApplySchedule(feedID int64) error
+		Reschedule(feedID int64)
+		Status() model.PullStatus
+		Events() *events.Bus
 	}
 	sessions  map[string]int64        // sessionID -> unix expiry seconds
 	mu        sync.RWMutex            // protects sessions state
 	oidcAuth  *auth.OIDCAuthenticator // nil when OIDC is disabled
+	websub    *websub.Subscriber      // nil when FUSION_PUBLIC_URL is unset
+	events    *events.Bus             // live notifications, shared with the puller
+	media     *mediaproxy.Proxy       // nil when FUSION_MEDIA_PROXY is disabled
+	jobs      *jobs.Manager           // refresh-all, imports and initial pulls
 	limiter   *loginLimiter
 	lastSweep int64
-
-	refreshAllMu      sync.Mutex
-	refreshAllRunning bool
 }
 
 func New(store *store.Store, config *config.Config, puller interface {
 	RefreshFeed(ctx context.Context, feedID int64) error
-	RefreshAll(ctx context.Context) (int, error)
-}) (*Handler, error) {
+	RefreshAll(ctx context.Context, progress pull.Progress) (int, error)
+	RefreshFeeds(ctx context.Context, feedIDs []int64, progress pull.Progress) (int, error)
+	IngestPayload(ctx context.Context, feedID int64, body []byte) (int, error)
+	ReadableContent(ctx context.Context, itemID int64) (string, error)
+	ApplySchedule(feedID int64) error
+	Reschedule(feedID int64)
+	Status() model.PullStatus
+	Events() *events.Bus
+}, subscriber *websub.Subscriber) (*Handler, error) {
 	// Hash password at startup for later verification
 	passwordHash, err := auth.HashPassword(config.Password)
 	if err != nil {
@@ -46,14 +68,26 @@ func New(store *store.Store, config *config.Config, puller interface {
 	}
 
 	h := &Handler{
-		store:        store,
-		config:       config,
-		passwordHash: passwordHash,
-		feverAPIKey:  deriveFeverAPIKey(config.FeverUsername, config.Password),
-		allowAnonAPI: strings.TrimSpace(config.Password) == "" && strings.TrimSpace(config.OIDCIssuer) == "",
-		puller:       puller,
-		sessions:     make(map[string]int64),
-		limiter:      newLoginLimiter(config.LoginRateLimit, config.LoginWindow, config.LoginBlock),
+		store:            store,
+		config:           config,
+		passwordHash:     passwordHash,
+		feverAPIKey:      deriveFeverAPIKey(config.FeverUsername, config.Password),
+		greaderAuthToken: deriveGReaderAuthToken(config.FeverUsername, config.Password),
+		allowAnonAPI:     strings.TrimSpace(config.Password) == "" && strings.TrimSpace(config.OIDCIssuer) == "",
+		puller:           puller,
+		websub:           subscriber,
+		events:           puller.Events(),
+		sessions:         make(map[string]int64),
+		jobs:             jobs.New(config.JobWorkers),
+		limiter:          newLoginLimiter(config.LoginRateLimit, config.LoginWindow, config.LoginBlock),
+	}
+
+	if config.MediaProxy {
+		media, err := mediaproxy.New(config)
+		if err != nil {
+			return nil, fmt.Errorf("initialize media proxy: %w", err)
+		}
+		h.media = media
 	}
 
 	if h.allowAnonAPI {
@@ -112,6 +146,43 @@ func (h *Handler) SetupRouter() *gin.Engine {
 			r.GET("/oidc/callback", h.oidcCallback)
 		}
 
+		// WebSub hub callbacks (public; content is authenticated by HMAC signature)
+		if h.websub != nil {
+			api.GET("/websub/callback/:id", h.webSubVerify)
+			api.POST("/websub/callback/:id", h.webSubReceive)
+		}
+
+		// Media proxy (public so external clients can load images; URLs are
+		// authorized by their HMAC signature)
+		if h.media != nil {
+			api.GET("/media", h.getMedia)
+		}
+
+		// Google Reader compatible API for third-party clients. It shares the
+		// Fever username and authenticates with its own token.
+		greader := api.Group("/greader")
+		{
+			greader.POST("/accounts/ClientLogin", h.greaderClientLogin)
+
+			reader := greader.Group("/reader/api/0")
+			reader.Use(h.greaderAuthMiddleware())
+			{
+				reader.GET("/token", h.greaderToken)
+				reader.GET("/user-info", h.greaderUserInfo)
+				reader.GET("/subscription/list", h.greaderSubscriptionList)
+				reader.POST("/subscription/edit", h.greaderSubscriptionEdit)
+				reader.POST("/subscription/quickadd", h.greaderQuickAdd)
+				reader.GET("/tag/list", h.greaderTagList)
+				reader.GET("/stream/contents", h.greaderStreamContents)
+				reader.GET("/stream/contents/*streamId", h.greaderStreamContents)
+				reader.GET("/stream/items/ids", h.greaderStreamItemIDs)
+				reader.GET("/stream/items/contents", h.greaderStreamItemsContents)
+				reader.POST("/stream/items/contents", h.greaderStreamItemsContents)
+				reader.POST("/edit-tag", h.greaderEditTag)
+				reader.POST("/mark-all-as-read", h.greaderMarkAllAsRead)
+			}
+		}
+
 		auth := api.Group("")
 		auth.Use(h.authMiddleware())
 		{
@@ -125,19 +196,45 @@ func (h *Handler) SetupRouter() *gin.Engine {
 			auth.POST("/feeds", h.createFeed)
 			auth.POST("/feeds/batch", h.batchCreateFeeds)
 			auth.POST("/feeds/refresh", h.refreshAllFeeds)
+			auth.GET("/feeds/health", h.listFeedHealth)
 			auth.GET("/feeds/:id", h.getFeed)
+			auth.GET("/feeds/:id/icon", h.getFeedIcon)
+			auth.GET("/feeds/:id/links", h.getFeedLinkHistory)
+			auth.GET("/feeds/:id/history", h.getFeedFetchHistory)
 			auth.PATCH("/feeds/:id", h.updateFeed)
 			auth.DELETE("/feeds/:id", h.deleteFeed)
 			auth.POST("/feeds/validate", h.validateFeed)
+			auth.POST("/feeds/scrape/preview", h.previewScrape)
 			auth.POST("/feeds/:id/refresh", h.refreshFeed)
 
+			auth.GET("/rules", h.listRules)
+			auth.POST("/rules", h.createRule)
+			auth.POST("/rules/dry-run", h.dryRunRule)
+			auth.GET("/rules/:id", h.getRule)
+			auth.PATCH("/rules/:id", h.updateRule)
+			auth.DELETE("/rules/:id", h.deleteRule)
+
+			auth.GET("/opml", h.exportOPML)
+			auth.POST("/opml", h.importOPML)
+
 			auth.GET("/items", h.listItems)
 			auth.GET("/items/:id", h.getItem)
+			auth.GET("/items/:id/readable", h.getItemReadable)
+			auth.GET("/items/:id/revisions", h.getItemRevisions)
+			auth.PUT("/items/:id/playback", h.updateItemPlayback)
 			auth.PATCH("/items/-/read", h.markItemsRead)
 			auth.PATCH("/items/-/unread", h.markItemsUnread)
 
 			auth.GET("/search", h.search)
 
+			auth.GET("/admin/pull-status", h.getPullStatus)
+
+			auth.GET("/events", h.streamEvents)
+
+			auth.GET("/jobs", h.listJobs)
+			auth.GET("/jobs/:id", h.getJob)
+			auth.DELETE("/jobs/:id", h.deleteJob)
+
 			auth.GET("/bookmarks", h.listBookmarks)
 			auth.POST("/bookmarks", h.createBookmark)
 			auth.GET("/bookmarks/:id", h.getBookmark)
@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 
@@ -17,6 +18,10 @@ type markItemsReadRequest struct {
 	IDs []int64 `json:"ids" binding:"required"`
 }
 
+type updatePlaybackRequest struct {
+	Position *int64 `json:"position" binding:"required"`
+}
+
 func (h *Handler) listItems(c *gin.Context) {
 	params := store.ListItemsParams{}
 
@@ -47,6 +52,14 @@ func (h *Handler) listItems(c *gin.Context) {
 		params.Unread = &val
 	}
 
+	if media := c.Query("media"); media != "" {
+		if media != "audio" && media != "video" && media != "any" {
+			badRequestError(c, "invalid media")
+			return
+		}
+		params.Media = media
+	}
+
 	if limit := c.Query("limit"); limit != "" {
 		val, err := strconv.Atoi(limit)
 		if err != nil || val <= 0 {
@@ -121,10 +134,68 @@ func (h *Handler) getItem(c *gin.Context) {
 		internalError(c, err, "get item")
 		return
 	}
+	h.rewriteItemMedia(item, "")
 
 	dataResponse(c, item)
 }
 
+type itemReadableResponse struct {
+	ItemID  int64  `json:"item_id"`
+	Content string `json:"content"`
+}
+
+// getItemReadable returns the extracted article of an item, fetching the
+// item link on first use. Works whether or not the feed has full_text on.
+func (h *Handler) getItemReadable(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	content, err := h.puller.ReadableContent(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "item")
+			return
+		}
+		slog.Warn("full-text extraction failed", "item_id", id, "error", err)
+		badGatewayError(c, "failed to extract article")
+		return
+	}
+
+	if h.media != nil {
+		content = h.media.RewriteHTML(content, "")
+	}
+
+	dataResponse(c, itemReadableResponse{ItemID: id, Content: content})
+}
+
+func (h *Handler) getItemRevisions(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	if _, err := h.store.GetItem(id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "item")
+			return
+		}
+		internalError(c, err, "get item")
+		return
+	}
+
+	revisions, err := h.store.ListItemRevisions(id)
+	if err != nil {
+		internalError(c, err, "list item revisions")
+		return
+	}
+
+	dataResponse(c, revisions)
+}
+
 func (h *Handler) markItemsRead(c *gin.Context) {
 	var req markItemsReadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -140,6 +211,7 @@ func (h *Handler) markItemsRead(c *gin.Context) {
 		internalError(c, err, "mark items as read")
 		return
 	}
+	h.publishUnreadCounts()
 
 	c.Status(http.StatusNoContent)
 }
@@ -159,6 +231,37 @@ func (h *Handler) markItemsUnread(c *gin.Context) {
 		internalError(c, err, "mark items as unread")
 		return
 	}
+	h.publishUnreadCounts()
+
+	c.Status(http.StatusNoContent)
+}
+
+// updateItemPlayback saves the playback position (seconds) of an item's media.
+func (h *Handler) updateItemPlayback(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	var req updatePlaybackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+	if *req.Position < 0 {
+		badRequestError(c, "invalid position")
+		return
+	}
+
+	if err := h.store.UpdateItemPlayback(id, *req.Position); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "item")
+			return
+		}
+		internalError(c, err, "update item playback")
+		return
+	}
 
 	c.Status(http.StatusNoContent)
 }
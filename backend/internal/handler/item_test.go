@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
 	"github.com/gin-gonic/gin"
 )
 
@@ -148,3 +150,136 @@ func TestListItemsCursorWithOrderByCreatedAt(t *testing.T) {
 		t.Fatalf("expected status 400 for before cursor with order_by=created_at, got %d", w.Code)
 	}
 }
+
+func TestListItemsMediaFilterAndPlayback(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	feed, err := st.CreateFeed(1, "Podcast", "https://example.com/podcast", "https://example.com", "")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if _, err := st.BatchCreateItemsIgnore(feed.ID, []store.BatchCreateItemInput{
+		{GUID: "audio", Title: "Audio", PubDate: 300, Duration: 1800, Enclosures: []model.Enclosure{{URL: "https://example.com/a.mp3", Type: "audio/mpeg"}}},
+		{GUID: "video", Title: "Video", PubDate: 200, Enclosures: []model.Enclosure{{URL: "https://example.com/v.mp4", Type: "video/mp4"}}},
+		{GUID: "text", Title: "Text", PubDate: 100, Enclosures: []model.Enclosure{{URL: "https://example.com/f.pdf", Type: "application/pdf"}}},
+	}); err != nil {
+		t.Fatalf("BatchCreateItemsIgnore: %v", err)
+	}
+
+	r := newTestRouter()
+	r.GET("/api/items", h.listItems)
+	r.GET("/api/items/:id", h.getItem)
+	r.PUT("/api/items/:id/playback", h.updateItemPlayback)
+
+	titles := func(query string) []string {
+		t.Helper()
+		w := performRequest(r, http.MethodGet, "/api/items?"+query, nil, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /api/items?%s: status %d (body=%s)", query, w.Code, w.Body.String())
+		}
+		var page struct {
+			Data  []model.Item `json:"data"`
+			Total int          `json:"total"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("unmarshal response: %v", err)
+		}
+		if page.Total != len(page.Data) {
+			t.Fatalf("total %d does not match %d items", page.Total, len(page.Data))
+		}
+		out := make([]string, 0, len(page.Data))
+		for _, item := range page.Data {
+			out = append(out, item.Title)
+		}
+		return out
+	}
+
+	for query, want := range map[string]string{
+		"media=audio": "Audio",
+		"media=video": "Video",
+		"media=any":   "Audio,Video",
+	} {
+		if got := strings.Join(titles(query), ","); got != want {
+			t.Errorf("%s: got %q, want %q", query, got, want)
+		}
+	}
+	if w := performRequest(r, http.MethodGet, "/api/items?media=pdf", nil, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown media, got %d", w.Code)
+	}
+
+	items, err := st.ListItems(store.ListItemsParams{Media: "audio"})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("ListItems(audio) = %d items, %v", len(items), err)
+	}
+	path := "/api/items/" + strconv.FormatInt(items[0].ID, 10)
+
+	w := performRequest(r, http.MethodPut, path+"/playback", mustJSONBody(t, gin.H{"position": 95}), map[string]string{"Content-Type": "application/json"})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	for _, body := range []any{gin.H{"position": -1}, gin.H{}} {
+		if w := performRequest(r, http.MethodPut, path+"/playback", mustJSONBody(t, body), map[string]string{"Content-Type": "application/json"}); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %v, got %d", body, w.Code)
+		}
+	}
+	if w := performRequest(r, http.MethodPut, "/api/items/999999/playback", mustJSONBody(t, gin.H{"position": 1}), map[string]string{"Content-Type": "application/json"}); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown item, got %d", w.Code)
+	}
+
+	w = performRequest(r, http.MethodGet, path, nil, nil)
+	var resp struct {
+		Data model.Item `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal item: %v", err)
+	}
+	if resp.Data.PlaybackPosition != 95 || resp.Data.Duration != 1800 || len(resp.Data.Enclosures) != 1 {
+		t.Fatalf("unexpected item: %+v", resp.Data)
+	}
+}
+
+func TestGetItemRevisions(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	group, err := st.CreateGroup("G")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	feed, err := st.CreateFeed(group.ID, "Feed", "https://example.com/feed", "https://example.com", "")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	input := store.BatchCreateItemInput{GUID: "g1", Title: "Old", Link: "https://example.com/1", Content: "v1", PubDate: 100}
+	if _, err := st.BatchUpsertItems(feed.ID, model.ItemUpdateSilent, []store.BatchCreateItemInput{input}); err != nil {
+		t.Fatalf("BatchUpsertItems: %v", err)
+	}
+	input.Title, input.Content = "New", "v2"
+	if _, err := st.BatchUpsertItems(feed.ID, model.ItemUpdateSilent, []store.BatchCreateItemInput{input}); err != nil {
+		t.Fatalf("BatchUpsertItems: %v", err)
+	}
+	items, err := st.ListItems(store.ListItemsParams{FeedID: &feed.ID})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("ListItems = %d items, %v", len(items), err)
+	}
+
+	r := newTestRouter()
+	r.GET("/api/items/:id/revisions", h.getItemRevisions)
+
+	w := performRequest(r, http.MethodGet, "/api/items/"+strconv.FormatInt(items[0].ID, 10)+"/revisions", nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data []model.ItemRevision `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal revisions: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].Title != "Old" || resp.Data[0].Content != "v1" {
+		t.Fatalf("unexpected revisions: %+v", resp.Data)
+	}
+
+	if w := performRequest(r, http.MethodGet, "/api/items/999999/revisions", nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown item, got %d", w.Code)
+	}
+}
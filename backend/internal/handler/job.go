@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/0x2E/fusion/internal/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// Shutdown stops accepting background jobs and waits for the submitted ones;
+// those still unfinished when ctx ends are cancelled.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	return h.jobs.Shutdown(ctx)
+}
+
+func (h *Handler) listJobs(c *gin.Context) {
+	list := h.jobs.List()
+	listResponse(c, list, len(list))
+}
+
+func (h *Handler) getJob(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	job, err := h.jobs.Get(id)
+	if err != nil {
+		notFoundError(c, "job")
+		return
+	}
+
+	dataResponse(c, job)
+}
+
+// deleteJob cancels a queued or running job, or forgets a finished one.
+func (h *Handler) deleteJob(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	if err := h.jobs.Cancel(id); err != nil {
+		if errors.Is(err, jobs.ErrNotFound) {
+			notFoundError(c, "job")
+			return
+		}
+		internalError(c, err, "cancel job")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
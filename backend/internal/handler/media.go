@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/0x2E/fusion/internal/mediaproxy"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// getMedia serves a signed remote image or video from the media cache.
+func (h *Handler) getMedia(c *gin.Context) {
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		badRequestError(c, "missing url")
+		return
+	}
+	if !h.media.Verify(rawURL, c.Query("sig")) {
+		forbiddenError(c, "invalid signature")
+		return
+	}
+
+	media, err := h.media.Open(c.Request.Context(), rawURL)
+	if err != nil {
+		slog.Debug("media proxy fetch failed", "url", rawURL, "error", err)
+		switch {
+		case errors.Is(err, mediaproxy.ErrTooLarge):
+			badGatewayError(c, "media too large")
+		case errors.Is(err, mediaproxy.ErrUnsupportedType):
+			badGatewayError(c, "unsupported media type")
+		default:
+			badGatewayError(c, "failed to fetch media")
+		}
+		return
+	}
+	defer media.File.Close()
+
+	// Signed URLs always map to the same upstream resource.
+	c.Header("Content-Type", media.ContentType)
+	c.Header("Cache-Control", "public, max-age=604800")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'; sandbox")
+	http.ServeContent(c.Writer, c.Request, "", media.ModTime, media.File)
+}
+
+// rewriteItemMedia points item images and videos at the media proxy when it
+// is enabled. base is empty for same-origin clients, see mediaBaseURL.
+func (h *Handler) rewriteItemMedia(item *model.Item, base string) {
+	if h.media == nil {
+		return
+	}
+
+	item.Content = h.media.RewriteHTML(item.Content, base)
+	item.Summary = h.media.RewriteHTML(item.Summary, base)
+	item.ReadableContent = h.media.RewriteHTML(item.ReadableContent, base)
+}
+
+// mediaBaseURL is the absolute origin used in proxied URLs for third-party
+// clients, which resolve relative URLs against the article instead of us.
+func (h *Handler) mediaBaseURL(c *gin.Context) string {
+	if h.config.PublicURL != "" {
+		return h.config.PublicURL
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/mediaproxy"
+	"github.com/0x2E/fusion/internal/model"
+)
+
+func TestMediaProxyServesSignedURLsAndRewritesItems(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+	media, err := mediaproxy.New(&config.Config{
+		PullTimeout:       5,
+		AllowPrivateFeeds: true,
+		MediaCacheDir:     t.TempDir(),
+		MediaCacheSize:    1,
+		MediaMaxSize:      1,
+	})
+	if err != nil {
+		t.Fatalf("mediaproxy.New() failed: %v", err)
+	}
+	h.media = media
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/gif")
+		_, _ = w.Write([]byte("GIF89a"))
+	}))
+	defer upstream.Close()
+	imageURL := upstream.URL + "/pic.gif"
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/feed.xml", "", "")
+	if err != nil {
+		t.Fatalf("CreateFeed() failed: %v", err)
+	}
+	item, err := st.CreateItem(feed.ID, "g1", "Item", "https://example.com/1", `<p><img src="`+imageURL+`"/></p>`, 100)
+	if err != nil {
+		t.Fatalf("CreateItem() failed: %v", err)
+	}
+
+	r := newTestRouter()
+	r.GET("/api/media", h.getMedia)
+	r.GET("/api/items/:id", h.getItem)
+
+	w := performRequest(r, http.MethodGet, "/api/items/"+strconv.FormatInt(item.ID, 10), nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get item: status %d (body=%s)", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data model.Item `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal item: %v", err)
+	}
+	proxied := media.URL("", imageURL)
+	if !strings.Contains(resp.Data.Content, strings.ReplaceAll(proxied, "&", "&amp;")) {
+		t.Fatalf("expected proxied image in content, got %s", resp.Data.Content)
+	}
+
+	w = performRequest(r, http.MethodGet, proxied, nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "GIF89a" || w.Header().Get("Content-Type") != "image/gif" {
+		t.Fatalf("media: status %d type %q body %q", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+
+	forged := mediaproxy.Path + "?url=" + url.QueryEscape(upstream.URL+"/other.gif") + "&sig=" + media.Sign(imageURL)
+	if w := performRequest(r, http.MethodGet, forged, nil, nil); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a mismatched signature, got %d", w.Code)
+	}
+}
@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"github.com/0x2E/fusion/internal/pull"
+	"github.com/0x2E/fusion/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// opmlNamespace qualifies Fusion-specific outline attributes (fusion:proxy,
+	// fusion:suspended, fusion:scrape) so other readers can safely ignore them.
+	opmlNamespace      = "https://github.com/0x2E/fusion"
+	opmlNamespacePfx   = "fusion"
+	maxOPMLUploadBytes = 10 << 20
+)
+
+const (
+	opmlStatusCreated   = "created"
+	opmlStatusDuplicate = "duplicate"
+	opmlStatusInvalid   = "invalid"
+	opmlStatusFailed    = "failed"
+)
+
+type opmlDocument struct {
+	XMLName   xml.Name `xml:"opml"`
+	Version   string   `xml:"version,attr"`
+	Namespace string   `xml:"xmlns:fusion,attr,omitempty"`
+	Head      opmlHead `xml:"head"`
+	Body      opmlBody `xml:"body"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlOutline struct {
+	Text    string `xml:"text,attr"`
+	Title   string `xml:"title,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	XMLURL  string `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+	// Extensions holds namespaced attributes; on import only the Fusion
+	// namespace is interpreted.
+	Extensions []xml.Attr    `xml:",any,attr"`
+	Outlines   []opmlOutline `xml:"outline"`
+}
+
+// opmlEntry is a feed outline flattened with its resolved group name.
+type opmlEntry struct {
+	Name      string
+	Link      string
+	SiteURL   string
+	GroupName string
+	Proxy     string
+	Suspended bool
+	Scrape    *model.ScrapeRecipe
+}
+
+type opmlImportResult struct {
+	Title  string `json:"title"`
+	Link   string `json:"link"`
+	Group  string `json:"group"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type opmlImportResponse struct {
+	Created   int                `json:"created"`
+	Duplicate int                `json:"duplicate"`
+	Failed    int                `json:"failed"`
+	Results   []opmlImportResult `json:"results"`
+	JobID     int64              `json:"job_id,omitempty"` // initial pulls of the created feeds
+}
+
+func (h *Handler) exportOPML(c *gin.Context) {
+	groups, err := h.store.ListGroups()
+	if err != nil {
+		internalError(c, err, "list groups for opml")
+		return
+	}
+
+	feeds, err := h.store.ListFeeds()
+	if err != nil {
+		internalError(c, err, "list feeds for opml")
+		return
+	}
+
+	body, err := xml.MarshalIndent(buildOPMLDocument(groups, feeds, time.Now()), "", "  ")
+	if err != nil {
+		internalError(c, err, "encode opml")
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="fusion.opml"`)
+	c.Data(http.StatusOK, "text/x-opml; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+func buildOPMLDocument(groups []*model.Group, feeds []*model.Feed, now time.Time) opmlDocument {
+	feedsByGroup := make(map[int64][]*model.Feed, len(groups))
+	for _, feed := range feeds {
+		feedsByGroup[feed.GroupID] = append(feedsByGroup[feed.GroupID], feed)
+	}
+
+	doc := opmlDocument{
+		Version:   "2.0",
+		Namespace: opmlNamespace,
+		Head: opmlHead{
+			Title:       "Fusion Subscriptions",
+			DateCreated: now.UTC().Format(time.RFC1123),
+		},
+	}
+
+	for _, group := range groups {
+		groupFeeds := feedsByGroup[group.ID]
+		if len(groupFeeds) == 0 {
+			continue
+		}
+
+		category := opmlOutline{Text: group.Name, Title: group.Name}
+		for _, feed := range groupFeeds {
+			category.Outlines = append(category.Outlines, feedToOPMLOutline(feed))
+		}
+		doc.Body.Outlines = append(doc.Body.Outlines, category)
+	}
+
+	return doc
+}
+
+func feedToOPMLOutline(feed *model.Feed) opmlOutline {
+	outline := opmlOutline{
+		Text:    feed.Name,
+		Title:   feed.Name,
+		Type:    "rss",
+		XMLURL:  feed.Link,
+		HTMLURL: feed.SiteURL,
+	}
+
+	if feed.Proxy != "" {
+		outline.Extensions = append(outline.Extensions, xml.Attr{
+			Name:  xml.Name{Local: opmlNamespacePfx + ":proxy"},
+			Value: feed.Proxy,
+		})
+	}
+	if feed.Suspended {
+		outline.Extensions = append(outline.Extensions, xml.Attr{
+			Name:  xml.Name{Local: opmlNamespacePfx + ":suspended"},
+			Value: "true",
+		})
+	}
+	if feed.Scrape != nil {
+		if recipe, err := json.Marshal(feed.Scrape); err == nil {
+			outline.Type = "scrape"
+			outline.Extensions = append(outline.Extensions, xml.Attr{
+				Name:  xml.Name{Local: opmlNamespacePfx + ":scrape"},
+				Value: string(recipe),
+			})
+		}
+	}
+
+	return outline
+}
+
+func (h *Handler) importOPML(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		badRequestError(c, "file is required")
+		return
+	}
+	if fileHeader.Size > maxOPMLUploadBytes {
+		badRequestError(c, "file too large")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		internalError(c, err, "open opml upload")
+		return
+	}
+	defer file.Close()
+
+	entries, err := parseOPML(io.LimitReader(file, maxOPMLUploadBytes))
+	if err != nil {
+		badRequestError(c, "invalid opml")
+		return
+	}
+
+	groups, err := h.store.ListGroups()
+	if err != nil {
+		internalError(c, err, "list groups for opml import")
+		return
+	}
+	groupIDs := make(map[string]int64, len(groups))
+	for _, group := range groups {
+		groupIDs[group.Name] = group.ID
+	}
+
+	feeds, err := h.store.ListFeeds()
+	if err != nil {
+		internalError(c, err, "list feeds for opml import")
+		return
+	}
+	existingLinks := make(map[string]struct{}, len(feeds))
+	for _, feed := range feeds {
+		existingLinks[feed.Link] = struct{}{}
+	}
+
+	results := make([]opmlImportResult, len(entries))
+	inputs := make([]store.BatchCreateFeedsInput, 0, len(entries))
+	inputIndexes := make([]int, 0, len(entries))
+
+	for i, entry := range entries {
+		results[i] = opmlImportResult{Title: entry.Name, Link: entry.Link, Group: entry.GroupName}
+
+		if err := httpc.ValidateRequestURL(c.Request.Context(), entry.Link, h.config.AllowPrivateFeeds); err != nil {
+			results[i].Status = opmlStatusInvalid
+			results[i].Error = "invalid link"
+			continue
+		}
+		if err := httpc.ValidateProxyURL(c.Request.Context(), entry.Proxy, h.config.AllowPrivateFeeds); err != nil {
+			results[i].Status = opmlStatusInvalid
+			results[i].Error = "invalid proxy"
+			continue
+		}
+		if _, exists := existingLinks[entry.Link]; exists {
+			results[i].Status = opmlStatusDuplicate
+			continue
+		}
+
+		groupID := store.DefaultGroupID
+		if entry.GroupName != "" {
+			id, ok := groupIDs[entry.GroupName]
+			if !ok {
+				group, err := h.store.CreateGroup(entry.GroupName)
+				if err != nil {
+					results[i].Status = opmlStatusFailed
+					results[i].Error = "failed to create group"
+					continue
+				}
+				id = group.ID
+				groupIDs[group.Name] = id
+			}
+			groupID = id
+		}
+
+		inputs = append(inputs, store.BatchCreateFeedsInput{
+			GroupID:   groupID,
+			Name:      entry.Name,
+			Link:      entry.Link,
+			SiteURL:   entry.SiteURL,
+			Proxy:     entry.Proxy,
+			Suspended: entry.Suspended,
+			Scrape:    entry.Scrape,
+		})
+		inputIndexes = append(inputIndexes, i)
+	}
+
+	created, err := h.store.BatchCreateFeeds(inputs)
+	if err != nil {
+		internalError(c, err, "batch create feeds for opml import")
+		return
+	}
+
+	for j, outcome := range created.Outcomes {
+		result := &results[inputIndexes[j]]
+		switch {
+		case outcome == nil:
+			result.Status = opmlStatusCreated
+		case errors.Is(outcome, store.ErrConflict):
+			result.Status = opmlStatusDuplicate
+		default:
+			result.Status = opmlStatusFailed
+			result.Error = "failed to create feed"
+		}
+	}
+
+	response := opmlImportResponse{Results: results}
+	response.JobID = h.startInitialPulls(model.JobImport, created.CreatedIDs)
+	for _, result := range results {
+		switch result.Status {
+		case opmlStatusCreated:
+			response.Created++
+		case opmlStatusDuplicate:
+			response.Duplicate++
+		default:
+			response.Failed++
+		}
+	}
+
+	dataResponse(c, response)
+}
+
+// parseOPML flattens feed outlines. Outlines without xmlUrl are treated as
+// categories; a feed belongs to its nearest named category, matching the web UI.
+func parseOPML(r io.Reader) ([]opmlEntry, error) {
+	var doc opmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode opml: %w", err)
+	}
+
+	entries := []opmlEntry{}
+	var walk func(outlines []opmlOutline, groupName string)
+	walk = func(outlines []opmlOutline, groupName string) {
+		for _, outline := range outlines {
+			title := strings.TrimSpace(outline.Title)
+			if title == "" {
+				title = strings.TrimSpace(outline.Text)
+			}
+
+			link := strings.TrimSpace(outline.XMLURL)
+			if link == "" {
+				childGroup := groupName
+				if title != "" {
+					childGroup = title
+				}
+				walk(outline.Outlines, childGroup)
+				continue
+			}
+
+			if title == "" {
+				title = link
+			}
+			entry := opmlEntry{
+				Name:      title,
+				Link:      link,
+				SiteURL:   strings.TrimSpace(outline.HTMLURL),
+				GroupName: groupName,
+			}
+			for _, attr := range outline.Extensions {
+				if attr.Name.Space != opmlNamespace && attr.Name.Space != opmlNamespacePfx {
+					continue
+				}
+				switch attr.Name.Local {
+				case "proxy":
+					entry.Proxy = strings.TrimSpace(attr.Value)
+				case "suspended":
+					entry.Suspended, _ = strconv.ParseBool(strings.TrimSpace(attr.Value))
+				case "scrape":
+					recipe := &model.ScrapeRecipe{}
+					if err := json.Unmarshal([]byte(attr.Value), recipe); err == nil && pull.ValidateScrapeRecipe(recipe) == nil {
+						entry.Scrape = recipe
+					}
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	walk(doc.Body.Outlines, "")
+
+	return entries, nil
+}
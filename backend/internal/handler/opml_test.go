@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+func TestExportOPMLIncludesExtensionAttributes(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	group, err := st.CreateGroup("Tech")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	feed, err := st.CreateFeed(group.ID, "Proxied", "https://example.com/feed.xml", "https://example.com", "http://127.0.0.1:8888")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	suspended := true
+	recipe := &model.ScrapeRecipe{Item: "article", Title: "h2", Date: "time@datetime"}
+	if err := st.UpdateFeed(feed.ID, store.UpdateFeedParams{Suspended: &suspended, Scrape: recipe}); err != nil {
+		t.Fatalf("UpdateFeed: %v", err)
+	}
+
+	r := newTestRouter()
+	r.GET("/api/opml", h.exportOPML)
+
+	w := performRequest(r, http.MethodGet, "/api/opml", nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+
+	entries, err := parseOPML(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("parse exported opml: %v (body=%s)", err, w.Body.String())
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	got := entries[0]
+	if got.GroupName != "Tech" || got.Link != feed.Link || got.SiteURL != feed.SiteURL {
+		t.Errorf("unexpected entry: %+v", got)
+	}
+	if got.Proxy != "http://127.0.0.1:8888" || !got.Suspended {
+		t.Errorf("expected proxy and suspended to round-trip, got %+v", got)
+	}
+	if got.Scrape == nil || *got.Scrape != *recipe {
+		t.Errorf("expected scrape recipe to round-trip, got %+v", got.Scrape)
+	}
+}
+
+func TestParseOPMLNestedOutlines(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<opml version="2.0"><body>
+  <outline text="Top" xmlUrl="https://example.com/top.xml"/>
+  <outline text="News">
+    <outline title="Daily" text="ignored" xmlUrl="https://example.com/daily.xml" htmlUrl="https://example.com"/>
+    <outline text="World">
+      <outline xmlUrl="https://example.com/world.xml"/>
+    </outline>
+  </outline>
+</body></opml>`
+
+	entries, err := parseOPML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("parseOPML() failed: %v", err)
+	}
+
+	want := []opmlEntry{
+		{Name: "Top", Link: "https://example.com/top.xml"},
+		{Name: "Daily", Link: "https://example.com/daily.xml", SiteURL: "https://example.com", GroupName: "News"},
+		{Name: "https://example.com/world.xml", Link: "https://example.com/world.xml", GroupName: "World"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d (%+v)", len(want), len(entries), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestImportOPMLReportsPerOutlineResults(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+	h.config.AllowPrivateFeeds = true
+
+	if _, err := st.CreateFeed(1, "Existing", "https://example.com/existing.xml", "", ""); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	doc := `<?xml version="1.0"?>
+<opml version="2.0" xmlns:fusion="https://github.com/0x2E/fusion"><body>
+  <outline text="Existing" xmlUrl="https://example.com/existing.xml"/>
+  <outline text="Imported">
+    <outline text="New" xmlUrl="https://example.com/new.xml" fusion:suspended="true"/>
+    <outline text="New again" xmlUrl="https://example.com/new.xml"/>
+    <outline text="Bad" xmlUrl="ftp://example.com/bad.xml"/>
+    <outline text="Bad proxy" xmlUrl="https://example.com/proxied.xml" fusion:proxy="ftp://proxy.example.com"/>
+  </outline>
+</body></opml>`
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "subs.opml")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	if _, err := part.Write([]byte(doc)); err != nil {
+		t.Fatalf("write form file: %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+
+	r := newTestRouter()
+	r.POST("/api/opml", h.importOPML)
+
+	w := performRequest(r, http.MethodPost, "/api/opml", &body, map[string]string{"Content-Type": mw.FormDataContentType()})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Data opmlImportResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	wantStatuses := []string{opmlStatusDuplicate, opmlStatusCreated, opmlStatusDuplicate, opmlStatusInvalid, opmlStatusInvalid}
+	if len(resp.Data.Results) != len(wantStatuses) {
+		t.Fatalf("expected %d results, got %+v", len(wantStatuses), resp.Data.Results)
+	}
+	for i, want := range wantStatuses {
+		if resp.Data.Results[i].Status != want {
+			t.Errorf("result %d status = %q, want %q", i, resp.Data.Results[i].Status, want)
+		}
+	}
+	if resp.Data.Created != 1 || resp.Data.Duplicate != 2 || resp.Data.Failed != 2 {
+		t.Errorf("unexpected summary: %+v", resp.Data)
+	}
+
+	if got := resp.Data.Results[4].Error; got != "invalid proxy" {
+		t.Errorf("result 4 error = %q, want %q", got, "invalid proxy")
+	}
+
+	feeds, err := st.ListFeeds()
+	if err != nil {
+		t.Fatalf("ListFeeds: %v", err)
+	}
+	var imported bool
+	for _, feed := range feeds {
+		if feed.Link != "https://example.com/new.xml" {
+			continue
+		}
+		imported = true
+		group, err := st.GetGroup(feed.GroupID)
+		if err != nil {
+			t.Fatalf("GetGroup: %v", err)
+		}
+		if group.Name != "Imported" {
+			t.Errorf("expected feed in group %q, got %q", "Imported", group.Name)
+		}
+		if !feed.Suspended {
+			t.Error("expected fusion:suspended to be applied")
+		}
+	}
+	if !imported {
+		t.Fatal("expected imported feed to exist")
+	}
+}
@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/rules"
+	"github.com/0x2E/fusion/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDryRunLimit = 500
+	maxDryRunLimit     = 5000
+	maxDryRunMatches   = 100
+)
+
+type createRuleRequest struct {
+	Name string `json:"name" binding:"required"`
+	// At most one of FeedID and GroupID; neither makes the rule global.
+	FeedID        int64  `json:"feed_id"`
+	GroupID       int64  `json:"group_id"`
+	Field         string `json:"field" binding:"required"`
+	MatchType     string `json:"match_type" binding:"required"`
+	Pattern       string `json:"pattern" binding:"required"`
+	Action        string `json:"action" binding:"required"`
+	TargetGroupID int64  `json:"target_group_id"`
+	// Defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+type updateRuleRequest struct {
+	Name *string `json:"name"`
+	// 0 clears the scope.
+	FeedID        *int64  `json:"feed_id"`
+	GroupID       *int64  `json:"group_id"`
+	Field         *string `json:"field"`
+	MatchType     *string `json:"match_type"`
+	Pattern       *string `json:"pattern"`
+	Action        *string `json:"action"`
+	TargetGroupID *int64  `json:"target_group_id"`
+	Enabled       *bool   `json:"enabled"`
+}
+
+// dryRunRuleRequest tests a saved rule (RuleID) or an unsaved definition.
+type dryRunRuleRequest struct {
+	RuleID        int64  `json:"rule_id"`
+	FeedID        int64  `json:"feed_id"`
+	GroupID       int64  `json:"group_id"`
+	Field         string `json:"field"`
+	MatchType     string `json:"match_type"`
+	Pattern       string `json:"pattern"`
+	Action        string `json:"action"`
+	TargetGroupID int64  `json:"target_group_id"`
+	// Number of newest items in scope to evaluate.
+	Limit int `json:"limit"`
+}
+
+type dryRunMatch struct {
+	ID      int64  `json:"id"`
+	FeedID  int64  `json:"feed_id"`
+	Title   string `json:"title"`
+	Link    string `json:"link"`
+	PubDate int64  `json:"pub_date"`
+}
+
+type dryRunRuleResponse struct {
+	Scanned int           `json:"scanned"`
+	Matched int           `json:"matched"`
+	Items   []dryRunMatch `json:"items"`
+}
+
+func (h *Handler) listRules(c *gin.Context) {
+	list, err := h.store.ListRules()
+	if err != nil {
+		internalError(c, err, "list rules")
+		return
+	}
+
+	listResponse(c, list, len(list))
+}
+
+func (h *Handler) getRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	rule, err := h.store.GetRule(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "rule")
+			return
+		}
+		internalError(c, err, "get rule")
+		return
+	}
+
+	dataResponse(c, rule)
+}
+
+func (h *Handler) createRule(c *gin.Context) {
+	var req createRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+
+	rule := &model.Rule{
+		Name:          strings.TrimSpace(req.Name),
+		FeedID:        req.FeedID,
+		GroupID:       req.GroupID,
+		Field:         req.Field,
+		MatchType:     req.MatchType,
+		Pattern:       req.Pattern,
+		Action:        req.Action,
+		TargetGroupID: req.TargetGroupID,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+	}
+	if !h.validateRule(c, rule) {
+		return
+	}
+
+	created, err := h.store.CreateRule(rule)
+	if err != nil {
+		internalError(c, err, "create rule")
+		return
+	}
+
+	dataResponse(c, created)
+}
+
+func (h *Handler) updateRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	var req updateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+
+	rule, err := h.store.GetRule(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "rule")
+			return
+		}
+		internalError(c, err, "get rule")
+		return
+	}
+
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.FeedID != nil {
+		rule.FeedID = *req.FeedID
+	}
+	if req.GroupID != nil {
+		rule.GroupID = *req.GroupID
+	}
+	if req.Field != nil {
+		rule.Field = *req.Field
+	}
+	if req.MatchType != nil {
+		rule.MatchType = *req.MatchType
+	}
+	if req.Pattern != nil {
+		rule.Pattern = *req.Pattern
+	}
+	if req.Action != nil {
+		rule.Action = *req.Action
+		// Switching away from move drops the now meaningless target.
+		if rule.Action != model.RuleActionMove && req.TargetGroupID == nil {
+			rule.TargetGroupID = 0
+		}
+	}
+	if req.TargetGroupID != nil {
+		rule.TargetGroupID = *req.TargetGroupID
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if !h.validateRule(c, rule) {
+		return
+	}
+
+	if err := h.store.UpdateRule(id, rule); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "rule")
+			return
+		}
+		internalError(c, err, "update rule")
+		return
+	}
+
+	updated, err := h.store.GetRule(id)
+	if err != nil {
+		internalError(c, err, "get rule after update")
+		return
+	}
+
+	dataResponse(c, updated)
+}
+
+func (h *Handler) deleteRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	if err := h.store.DeleteRule(id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "rule")
+			return
+		}
+		internalError(c, err, "delete rule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// dryRunRule evaluates a rule against the newest stored items in its scope.
+// Nothing is changed and hit counters are not touched.
+func (h *Handler) dryRunRule(c *gin.Context) {
+	var req dryRunRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequestError(c, "invalid request")
+		return
+	}
+	if req.Limit < 0 || req.Limit > maxDryRunLimit {
+		badRequestError(c, "invalid limit")
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultDryRunLimit
+	}
+
+	var rule *model.Rule
+	if req.RuleID != 0 {
+		saved, err := h.store.GetRule(req.RuleID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				notFoundError(c, "rule")
+				return
+			}
+			internalError(c, err, "get rule")
+			return
+		}
+		rule = saved
+	} else {
+		rule = &model.Rule{
+			Name:          "dry-run",
+			FeedID:        req.FeedID,
+			GroupID:       req.GroupID,
+			Field:         req.Field,
+			MatchType:     req.MatchType,
+			Pattern:       req.Pattern,
+			Action:        req.Action,
+			TargetGroupID: req.TargetGroupID,
+		}
+		if !h.validateRule(c, rule) {
+			return
+		}
+	}
+	rule.Enabled = true
+
+	set, err := rules.Compile([]*model.Rule{rule})
+	if err != nil {
+		badRequestError(c, "invalid rule: "+err.Error())
+		return
+	}
+
+	params := store.ListItemsParams{Limit: req.Limit}
+	if rule.FeedID != 0 {
+		params.FeedID = &rule.FeedID
+	}
+	if rule.GroupID != 0 {
+		params.GroupID = &rule.GroupID
+	}
+	items, err := h.store.ListItems(params)
+	if err != nil {
+		internalError(c, err, "list items for rule dry run")
+		return
+	}
+
+	resp := dryRunRuleResponse{Scanned: len(items), Items: []dryRunMatch{}}
+	for _, item := range items {
+		outcome := set.Evaluate(rules.Fields{Title: item.Title, Content: item.Content, Link: item.Link, Author: item.Author})
+		if !outcome.Matched() {
+			continue
+		}
+		resp.Matched++
+		if len(resp.Items) < maxDryRunMatches {
+			resp.Items = append(resp.Items, dryRunMatch{
+				ID:      item.ID,
+				FeedID:  item.FeedID,
+				Title:   item.Title,
+				Link:    item.Link,
+				PubDate: item.PubDate,
+			})
+		}
+	}
+
+	dataResponse(c, resp)
+}
+
+// validateRule checks a rule definition and that the feed and groups it
+// references exist, writing a 400 response otherwise.
+func (h *Handler) validateRule(c *gin.Context, rule *model.Rule) bool {
+	if err := rules.Validate(rule); err != nil {
+		badRequestError(c, "invalid rule: "+err.Error())
+		return false
+	}
+	if rule.FeedID != 0 {
+		if _, err := h.store.GetFeed(rule.FeedID); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				badRequestError(c, "unknown feed_id")
+				return false
+			}
+			internalError(c, err, "get rule feed")
+			return false
+		}
+	}
+	for _, groupID := range []int64{rule.GroupID, rule.TargetGroupID} {
+		if groupID == 0 {
+			continue
+		}
+		if _, err := h.store.GetGroup(groupID); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				badRequestError(c, "unknown group")
+				return false
+			}
+			internalError(c, err, "get rule group")
+			return false
+		}
+	}
+	return true
+}
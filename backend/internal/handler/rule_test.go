@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+func TestRuleCRUD(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/feed", "", "")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	r := newTestRouter()
+	r.GET("/api/rules", h.listRules)
+	r.POST("/api/rules", h.createRule)
+	r.GET("/api/rules/:id", h.getRule)
+	r.PATCH("/api/rules/:id", h.updateRule)
+	r.DELETE("/api/rules/:id", h.deleteRule)
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	w := performRequest(r, http.MethodPost, "/api/rules", mustJSONBody(t, map[string]any{
+		"name": "No ads", "feed_id": feed.ID, "field": "link", "match_type": "regex", "pattern": "/ads?/", "action": "drop",
+	}), jsonHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	var created struct {
+		Data model.Rule `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if created.Data.ID == 0 || created.Data.FeedID != feed.ID || !created.Data.Enabled {
+		t.Fatalf("unexpected rule: %+v", created.Data)
+	}
+
+	invalid := []map[string]any{
+		{"name": "bad regex", "field": "title", "match_type": "regex", "pattern": "(", "action": "drop"},
+		{"name": "unknown feed", "feed_id": 999, "field": "title", "match_type": "contains", "pattern": "x", "action": "drop"},
+		{"name": "move nowhere", "field": "title", "match_type": "contains", "pattern": "x", "action": "move"},
+	}
+	for _, body := range invalid {
+		w := performRequest(r, http.MethodPost, "/api/rules", mustJSONBody(t, body), jsonHeaders)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%v: expected status 400, got %d", body["name"], w.Code)
+		}
+	}
+
+	w = performRequest(r, http.MethodPatch, "/api/rules/1", mustJSONBody(t, map[string]any{
+		"action": "move", "target_group_id": 1, "enabled": false,
+	}), jsonHeaders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+	}
+	rule, err := st.GetRule(1)
+	if err != nil || rule.Action != model.RuleActionMove || rule.TargetGroupID != 1 || rule.Enabled {
+		t.Fatalf("rule not updated: %+v, %v", rule, err)
+	}
+
+	w = performRequest(r, http.MethodGet, "/api/rules", nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	w = performRequest(r, http.MethodDelete, "/api/rules/1", nil, nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", w.Code)
+	}
+	w = performRequest(r, http.MethodGet, "/api/rules/1", nil, nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestDryRunRule(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/feed", "", "")
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	for _, title := range []string{"Sponsored: buy now", "Release notes", "SPONSORED post"} {
+		if _, err := st.CreateItem(feed.ID, title, title, "https://example.com/"+title, "", 100); err != nil {
+			t.Fatalf("CreateItem: %v", err)
+		}
+	}
+	saved, err := st.CreateRule(&model.Rule{Name: "sponsored", Field: model.RuleFieldTitle, MatchType: model.RuleMatchContains, Pattern: "sponsored", Action: model.RuleActionRead, Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	r := newTestRouter()
+	r.POST("/api/rules/dry-run", h.dryRunRule)
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	for _, body := range []map[string]any{
+		{"rule_id": saved.ID},
+		{"feed_id": feed.ID, "field": "title", "match_type": "regex", "pattern": "(?i)^sponsored", "action": "drop"},
+	} {
+		w := performRequest(r, http.MethodPost, "/api/rules/dry-run", mustJSONBody(t, body), jsonHeaders)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d (body=%s)", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Data dryRunRuleResponse `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if resp.Data.Scanned != 3 || resp.Data.Matched != 2 || len(resp.Data.Items) != 2 {
+			t.Fatalf("%v: unexpected dry run result: %+v", body, resp.Data)
+		}
+	}
+
+	// Dry runs never count hits.
+	if rule, err := st.GetRule(saved.ID); err != nil || rule.HitCount != 0 {
+		t.Fatalf("dry run changed hit counter: %+v, %v", rule, err)
+	}
+}
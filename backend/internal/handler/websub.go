@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/0x2E/fusion/internal/store"
+	"github.com/0x2E/fusion/internal/websub"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebSubPayloadBytes caps the size of content pushed by hubs.
+const maxWebSubPayloadBytes = 10 << 20
+
+// webSubVerify answers hub intent verification requests.
+func (h *Handler) webSubVerify(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	leaseSeconds, _ := strconv.ParseInt(c.Query("hub.lease_seconds"), 10, 64)
+	challenge, err := h.websub.VerifyIntent(
+		id,
+		c.Query("hub.mode"),
+		c.Query("hub.topic"),
+		c.Query("hub.challenge"),
+		leaseSeconds,
+		c.Query("hub.reason"),
+		time.Now().Unix(),
+	)
+	if err != nil {
+		if errors.Is(err, websub.ErrUnknownSubscription) {
+			notFoundError(c, "websub subscription")
+			return
+		}
+		badRequestError(c, err.Error())
+		return
+	}
+
+	c.String(http.StatusOK, challenge)
+}
+
+// webSubReceive ingests content distributed by a hub. Requests with a missing
+// or invalid signature are acknowledged but discarded, as the spec requires.
+func (h *Handler) webSubReceive(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestError(c, "invalid id")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebSubPayloadBytes))
+	if err != nil {
+		badRequestError(c, "invalid payload")
+		return
+	}
+
+	if err := h.websub.VerifySignature(id, body, c.GetHeader("X-Hub-Signature")); err != nil {
+		if errors.Is(err, websub.ErrUnknownSubscription) {
+			notFoundError(c, "websub subscription")
+			return
+		}
+		if errors.Is(err, websub.ErrInvalidSignature) {
+			slog.Warn("discarding websub payload with invalid signature", "feed_id", id)
+			c.Status(http.StatusAccepted)
+			return
+		}
+		internalError(c, err, "verify websub signature")
+		return
+	}
+
+	if _, err := h.puller.IngestPayload(c.Request.Context(), id, body); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundError(c, "feed")
+			return
+		}
+		internalError(c, err, "ingest websub payload")
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
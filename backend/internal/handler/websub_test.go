@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+	"github.com/0x2E/fusion/internal/websub"
+)
+
+type ingestRecorder struct {
+	noopPuller
+	payloads [][]byte
+}
+
+func (r *ingestRecorder) IngestPayload(_ context.Context, _ int64, body []byte) (int, error) {
+	r.payloads = append(r.payloads, body)
+	return 1, nil
+}
+
+func TestWebSubCallback(t *testing.T) {
+	h, st := newFeverTestHandler(t)
+	h.config.PublicURL = "https://reader.example.com"
+	h.websub = websub.New(st, h.config)
+	recorder := &ingestRecorder{}
+	h.puller = recorder
+	r := h.SetupRouter()
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/feed.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	if err := st.UpsertWebSubSubscription(feed.ID, store.UpsertWebSubSubscriptionParams{
+		HubURL:   "https://hub.example.com",
+		TopicURL: feed.Link,
+		Secret:   "s3cret",
+		State:    model.WebSubStatePending,
+	}); err != nil {
+		t.Fatalf("upsert subscription: %v", err)
+	}
+	callback := fmt.Sprintf("/api/websub/callback/%d", feed.ID)
+
+	query := url.Values{
+		"hub.mode":          {"subscribe"},
+		"hub.topic":         {feed.Link},
+		"hub.challenge":     {"abc123"},
+		"hub.lease_seconds": {"86400"},
+	}
+	w := performRequest(r, http.MethodGet, callback+"?"+query.Encode(), nil, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "abc123" {
+		t.Fatalf("verify: status=%d body=%q", w.Code, w.Body.String())
+	}
+
+	query.Set("hub.topic", "https://example.com/other.xml")
+	w = performRequest(r, http.MethodGet, callback+"?"+query.Encode(), nil, nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("verify with foreign topic: expected 404, got %d", w.Code)
+	}
+
+	payload := []byte(`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`)
+	mac := hmac.New(sha1.New, []byte("s3cret"))
+	mac.Write(payload)
+	signature := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+	w = performRequest(r, http.MethodPost, callback, bytes.NewReader(payload), map[string]string{"X-Hub-Signature": "sha1=deadbeef"})
+	if w.Code != http.StatusAccepted || len(recorder.payloads) != 0 {
+		t.Fatalf("invalid signature: status=%d ingested=%d", w.Code, len(recorder.payloads))
+	}
+
+	w = performRequest(r, http.MethodPost, callback, bytes.NewReader(payload), map[string]string{"X-Hub-Signature": signature})
+	if w.Code != http.StatusAccepted || len(recorder.payloads) != 1 || !bytes.Equal(recorder.payloads[0], payload) {
+		t.Fatalf("valid signature: status=%d ingested=%d", w.Code, len(recorder.payloads))
+	}
+
+	w = performRequest(r, http.MethodPost, fmt.Sprintf("/api/websub/callback/%d", feed.ID+1), bytes.NewReader(payload), map[string]string{"X-Hub-Signature": signature})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unknown subscription: expected 404, got %d", w.Code)
+	}
+}
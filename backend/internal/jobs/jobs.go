@@ -0,0 +1,295 @@
+// Package jobs runs background tasks (refresh-all, feed imports, initial
+// pulls) on a bounded worker pool and keeps their state and progress in
+// memory for the jobs API.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// maxFinishedJobs bounds the finished jobs kept for listing; the oldest are
+// forgotten first.
+const maxFinishedJobs = 100
+
+// maxJobErrors bounds the step errors kept per job; Failed keeps counting.
+const maxJobErrors = 50
+
+var (
+	ErrNotFound = errors.New("job not found")
+	ErrClosed   = errors.New("job manager is shutting down")
+
+	errCancelled = errors.New("cancelled")
+	errShutdown  = errors.New("cancelled by server shutdown")
+)
+
+// Spec describes a job to submit.
+type Spec struct {
+	Kind string
+	// Unique makes Submit return the queued or running job of the same kind
+	// instead of starting another one.
+	Unique bool
+	// Timeout bounds the run, including time queued; 0 means none.
+	Timeout time.Duration
+	Run     func(ctx context.Context, progress *Progress) error
+}
+
+type job struct {
+	info      model.Job
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	cancelled bool // by a user, not by shutdown or timeout
+}
+
+func (j *job) active() bool {
+	return j.info.State == model.JobQueued || j.info.State == model.JobRunning
+}
+
+// Manager runs submitted jobs, at most workers at a time.
+type Manager struct {
+	logger  *slog.Logger
+	workers chan struct{}
+	ctx     context.Context // parent of all job contexts
+	stop    context.CancelCauseFunc
+	wg      sync.WaitGroup
+
+	mu     sync.Mutex
+	nextID int64
+	jobs   map[int64]*job
+	closed bool
+}
+
+func New(workers int) *Manager {
+	ctx, stop := context.WithCancelCause(context.Background())
+	return &Manager{
+		logger:  slog.Default(),
+		workers: make(chan struct{}, max(workers, 1)),
+		ctx:     ctx,
+		stop:    stop,
+		jobs:    make(map[int64]*job),
+	}
+}
+
+// Submit queues a job and returns a snapshot of it and true. With spec.Unique,
+// an active job of the same kind is returned instead, with false.
+func (m *Manager) Submit(spec Spec) (model.Job, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return model.Job{}, false, ErrClosed
+	}
+	if spec.Unique {
+		for _, j := range m.jobs {
+			if j.info.Kind == spec.Kind && j.active() {
+				return snapshot(j), false, nil
+			}
+		}
+	}
+
+	m.nextID++
+	ctx, cancel := context.WithCancelCause(m.ctx)
+	j := &job{
+		info: model.Job{
+			ID:        m.nextID,
+			Kind:      spec.Kind,
+			State:     model.JobQueued,
+			Errors:    []string{},
+			CreatedAt: time.Now().Unix(),
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	m.jobs[j.info.ID] = j
+	m.wg.Add(1)
+	go m.run(j, spec)
+
+	return snapshot(j), true, nil
+}
+
+func (m *Manager) run(j *job, spec Spec) {
+	defer m.wg.Done()
+	defer j.cancel(nil)
+
+	ctx := j.ctx
+	if spec.Timeout > 0 {
+		var stopTimeout context.CancelFunc
+		ctx, stopTimeout = context.WithTimeout(ctx, spec.Timeout)
+		defer stopTimeout()
+	}
+
+	select {
+	case m.workers <- struct{}{}:
+	case <-ctx.Done():
+		m.finish(j, context.Cause(ctx))
+		return
+	}
+	defer func() { <-m.workers }()
+
+	m.mu.Lock()
+	j.info.State = model.JobRunning
+	j.info.StartedAt = time.Now().Unix()
+	m.mu.Unlock()
+
+	err := runSafely(ctx, spec.Run, &Progress{m: m, j: j})
+	if err != nil && ctx.Err() != nil {
+		err = context.Cause(ctx)
+	}
+	m.finish(j, err)
+}
+
+func runSafely(ctx context.Context, run func(context.Context, *Progress) error, progress *Progress) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx, progress)
+}
+
+func (m *Manager) finish(j *job, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j.info.FinishedAt = time.Now().Unix()
+	switch {
+	case j.cancelled || errors.Is(err, errCancelled) || errors.Is(err, errShutdown):
+		j.info.State = model.JobCancelled
+	case err != nil:
+		j.info.State = model.JobFailed
+	default:
+		j.info.State = model.JobSucceeded
+	}
+	if err != nil {
+		j.info.Error = err.Error()
+		m.logger.Warn("job did not complete", "job_id", j.info.ID, "kind", j.info.Kind, "state", j.info.State, "error", err)
+	}
+	m.prune()
+}
+
+// prune forgets the oldest finished jobs beyond maxFinishedJobs.
+func (m *Manager) prune() {
+	var finished []int64
+	for id, j := range m.jobs {
+		if !j.active() {
+			finished = append(finished, id)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	slices.Sort(finished)
+	for _, id := range finished[:len(finished)-maxFinishedJobs] {
+		delete(m.jobs, id)
+	}
+}
+
+// List returns snapshots of the known jobs, newest first.
+func (m *Manager) List() []model.Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]model.Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		list = append(list, snapshot(j))
+	}
+	slices.SortFunc(list, func(a, b model.Job) int { return int(b.ID - a.ID) })
+	return list
+}
+
+// Get returns a snapshot of one job.
+func (m *Manager) Get(id int64) (model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return model.Job{}, ErrNotFound
+	}
+	return snapshot(j), nil
+}
+
+// Cancel stops a queued or running job; the job reports cancelled once its
+// run returns. A finished job is forgotten instead.
+func (m *Manager) Cancel(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if !j.active() {
+		delete(m.jobs, id)
+		return nil
+	}
+	j.cancelled = true
+	j.cancel(errCancelled)
+	return nil
+}
+
+// Shutdown stops accepting jobs and waits for the submitted ones to finish.
+// Jobs still unfinished when ctx ends are cancelled and waited for, and
+// ctx's error is returned.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		m.stop(errShutdown)
+		<-done
+		return ctx.Err()
+	}
+}
+
+func snapshot(j *job) model.Job {
+	info := j.info
+	info.Errors = slices.Clone(j.info.Errors)
+	return info
+}
+
+// Progress reports a running job's progress. Its methods are safe for
+// concurrent use.
+type Progress struct {
+	m *Manager
+	j *job
+}
+
+// SetTotal sets the number of steps.
+func (p *Progress) SetTotal(total int) {
+	p.m.mu.Lock()
+	defer p.m.mu.Unlock()
+	p.j.info.Total = total
+}
+
+// Step records a finished step and its error, if any.
+func (p *Progress) Step(err error) {
+	p.m.mu.Lock()
+	defer p.m.mu.Unlock()
+
+	p.j.info.Done++
+	if err == nil {
+		return
+	}
+	p.j.info.Failed++
+	if len(p.j.info.Errors) < maxJobErrors {
+		p.j.info.Errors = append(p.j.info.Errors, err.Error())
+	}
+}
@@ -0,0 +1,159 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+func waitState(t *testing.T, m *Manager, id int64, state string) model.Job {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%d): %v", id, err)
+		}
+		if job.State == state {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %d is %s, want %s", id, job.State, state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// blocking returns a Run func that waits for release or cancellation.
+func blocking(release <-chan struct{}) func(context.Context, *Progress) error {
+	return func(ctx context.Context, _ *Progress) error {
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestManagerRunsJobsOnBoundedPool(t *testing.T) {
+	m := New(1)
+	release := make(chan struct{})
+
+	first, created, err := m.Submit(Spec{Kind: model.JobImport, Run: blocking(release)})
+	if err != nil || !created {
+		t.Fatalf("Submit() = %v, %v", created, err)
+	}
+	waitState(t, m, first.ID, model.JobRunning)
+
+	second, _, err := m.Submit(Spec{Kind: model.JobImport, Run: func(_ context.Context, p *Progress) error {
+		p.SetTotal(3)
+		p.Step(nil)
+		p.Step(errors.New("feed 2: boom"))
+		p.Step(nil)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Submit(): %v", err)
+	}
+	if job, _ := m.Get(second.ID); job.State != model.JobQueued {
+		t.Fatalf("second job is %s while the only worker is busy, want queued", job.State)
+	}
+
+	close(release)
+	waitState(t, m, first.ID, model.JobSucceeded)
+	job := waitState(t, m, second.ID, model.JobSucceeded)
+	if job.Total != 3 || job.Done != 3 || job.Failed != 1 || len(job.Errors) != 1 || job.Errors[0] != "feed 2: boom" {
+		t.Fatalf("unexpected progress: %+v", job)
+	}
+
+	list := m.List()
+	if len(list) != 2 || list[0].ID != second.ID {
+		t.Fatalf("List() = %+v, want newest first", list)
+	}
+}
+
+func TestManagerUniqueJobs(t *testing.T) {
+	m := New(2)
+	release := make(chan struct{})
+
+	first, _, err := m.Submit(Spec{Kind: model.JobRefreshAll, Unique: true, Run: blocking(release)})
+	if err != nil {
+		t.Fatalf("Submit(): %v", err)
+	}
+	again, created, err := m.Submit(Spec{Kind: model.JobRefreshAll, Unique: true, Run: blocking(release)})
+	if err != nil || created || again.ID != first.ID {
+		t.Fatalf("second unique Submit() = %d, %v, %v; want job %d, false", again.ID, created, err, first.ID)
+	}
+
+	close(release)
+	waitState(t, m, first.ID, model.JobSucceeded)
+	if _, created, _ := m.Submit(Spec{Kind: model.JobRefreshAll, Unique: true, Run: blocking(release)}); !created {
+		t.Fatal("expected a new job once the previous one finished")
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	m := New(1)
+	release := make(chan struct{})
+	defer close(release)
+
+	running, _, _ := m.Submit(Spec{Kind: model.JobImport, Run: blocking(release)})
+	waitState(t, m, running.ID, model.JobRunning)
+	queued, _, _ := m.Submit(Spec{Kind: model.JobImport, Run: blocking(release)})
+
+	for _, id := range []int64{queued.ID, running.ID} {
+		if err := m.Cancel(id); err != nil {
+			t.Fatalf("Cancel(%d): %v", id, err)
+		}
+		if job := waitState(t, m, id, model.JobCancelled); job.Error != "cancelled" {
+			t.Fatalf("job %d error = %q, want cancelled", id, job.Error)
+		}
+	}
+
+	// Cancelling a finished job forgets it.
+	if err := m.Cancel(running.ID); err != nil {
+		t.Fatalf("Cancel(finished): %v", err)
+	}
+	if _, err := m.Get(running.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(forgotten) = %v, want ErrNotFound", err)
+	}
+	if err := m.Cancel(999); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Cancel(999) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestManagerTimeout(t *testing.T) {
+	m := New(1)
+	job, _, _ := m.Submit(Spec{Kind: model.JobInitialPull, Timeout: 20 * time.Millisecond, Run: blocking(nil)})
+	if got := waitState(t, m, job.ID, model.JobFailed); got.Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("error = %q, want deadline exceeded", got.Error)
+	}
+}
+
+func TestManagerShutdown(t *testing.T) {
+	m := New(2)
+	finished := make(chan struct{})
+	close(finished)
+	quick, _, _ := m.Submit(Spec{Kind: model.JobImport, Run: blocking(finished)})
+	stuck, _, _ := m.Submit(Spec{Kind: model.JobImport, Run: blocking(nil)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() = %v, want deadline exceeded", err)
+	}
+
+	if job, _ := m.Get(quick.ID); job.State != model.JobSucceeded {
+		t.Fatalf("quick job is %s, want succeeded", job.State)
+	}
+	if job, _ := m.Get(stuck.ID); job.State != model.JobCancelled || job.Error != errShutdown.Error() {
+		t.Fatalf("stuck job = %s %q, want cancelled by shutdown", job.State, job.Error)
+	}
+	if _, _, err := m.Submit(Spec{Kind: model.JobImport, Run: blocking(nil)}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Submit() after shutdown = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,200 @@
+package mediaproxy
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dataSuffix = ".bin"
+	typeSuffix = ".type"
+)
+
+// diskCache stores fetched media as files and evicts the least recently used
+// entries once the total size exceeds maxBytes. Recency survives restarts
+// through file modification times.
+type diskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key         string
+	size        int64
+	contentType string
+}
+
+func newDiskCache(dir string, maxBytes int64) (*diskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create media cache dir: %w", err)
+	}
+
+	c := &diskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// load indexes existing entries, oldest first, and drops incomplete ones.
+func (c *diskCache) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("read media cache dir: %w", err)
+	}
+
+	type found struct {
+		entry   *cacheEntry
+		modTime time.Time
+	}
+	var entries []found
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasSuffix(name, dataSuffix) {
+			if strings.HasPrefix(name, "tmp-") {
+				_ = os.Remove(filepath.Join(c.dir, name))
+			}
+			continue
+		}
+
+		key := strings.TrimSuffix(name, dataSuffix)
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		contentType, err := os.ReadFile(c.path(key, typeSuffix))
+		if err != nil {
+			c.removeFiles(key)
+			continue
+		}
+		entries = append(entries, found{
+			entry:   &cacheEntry{key: key, size: info.Size(), contentType: string(contentType)},
+			modTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range entries {
+		c.entries[f.entry.key] = c.order.PushFront(f.entry)
+		c.size += f.entry.size
+	}
+	c.evictLocked()
+
+	return nil
+}
+
+// get opens a cached entry and marks it as recently used.
+func (c *diskCache) get(key string) (*os.File, *cacheEntry, bool) {
+	c.mu.Lock()
+	elem, ok := c.entries[key]
+	if ok {
+		c.order.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	file, err := os.Open(c.path(key, dataSuffix))
+	if err != nil {
+		c.remove(key)
+		return nil, nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(c.path(key, dataSuffix), now, now)
+
+	return file, entry, true
+}
+
+// put stores body under key. The data is written to a temporary file first so
+// readers never see partial entries.
+func (c *diskCache) put(key, contentType string, body io.Reader) error {
+	tmp, err := os.CreateTemp(c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(c.path(key, typeSuffix), []byte(contentType), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key, dataSuffix)); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.size -= elem.Value.(*cacheEntry).size
+		c.order.Remove(elem)
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, size: size, contentType: contentType})
+	c.size += size
+	c.evictLocked()
+
+	return nil
+}
+
+func (c *diskCache) remove(key string) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.size -= elem.Value.(*cacheEntry).size
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	c.removeFiles(key)
+}
+
+func (c *diskCache) evictLocked() {
+	for c.size > c.maxBytes {
+		elem := c.order.Back()
+		if elem == nil {
+			return
+		}
+		entry := elem.Value.(*cacheEntry)
+		c.order.Remove(elem)
+		delete(c.entries, entry.key)
+		c.size -= entry.size
+		c.removeFiles(entry.key)
+	}
+}
+
+func (c *diskCache) removeFiles(key string) {
+	_ = os.Remove(c.path(key, dataSuffix))
+	_ = os.Remove(c.path(key, typeSuffix))
+}
+
+func (c *diskCache) path(key, suffix string) string {
+	return filepath.Join(c.dir, key+suffix)
+}
@@ -0,0 +1,242 @@
+// Package mediaproxy serves remote images and media through the server so
+// clients never contact third-party hosts directly. URLs are signed with a
+// server secret, fetched through the SSRF-guarded client and cached on disk.
+package mediaproxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"golang.org/x/sync/singleflight"
+)
+
+// Path is the route serving proxied media.
+const Path = "/api/media"
+
+const secretFile = "secret"
+
+var (
+	ErrTooLarge        = errors.New("media exceeds size limit")
+	ErrUnsupportedType = errors.New("unsupported media type")
+)
+
+type Proxy struct {
+	secret       []byte
+	cache        *diskCache
+	fetches      singleflight.Group
+	maxBytes     int64
+	timeout      time.Duration
+	allowPrivate bool
+	logger       *slog.Logger
+}
+
+// Media is a cached media file ready to be served. Callers must close File.
+type Media struct {
+	File        *os.File
+	ContentType string
+	ModTime     time.Time
+}
+
+func New(cfg *config.Config) (*Proxy, error) {
+	cache, err := newDiskCache(cfg.MediaCacheDir, int64(cfg.MediaCacheSize)<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := []byte(cfg.MediaProxySecret)
+	if len(secret) == 0 {
+		if secret, err = loadOrCreateSecret(filepath.Join(cfg.MediaCacheDir, secretFile)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Proxy{
+		secret:       secret,
+		cache:        cache,
+		maxBytes:     int64(cfg.MediaMaxSize) << 20,
+		timeout:      time.Duration(cfg.PullTimeout) * time.Second,
+		allowPrivate: cfg.AllowPrivateFeeds,
+		logger:       slog.Default(),
+	}, nil
+}
+
+// loadOrCreateSecret keeps the generated signing key next to the cache so
+// proxied URLs stay valid across restarts.
+func loadOrCreateSecret(path string) ([]byte, error) {
+	if data, err := os.ReadFile(path); err == nil && len(bytes.TrimSpace(data)) > 0 {
+		return bytes.TrimSpace(data), nil
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generate media proxy secret: %w", err)
+	}
+	secret := []byte(hex.EncodeToString(buf))
+	if err := os.WriteFile(path, secret, 0o600); err != nil {
+		return nil, fmt.Errorf("store media proxy secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// Sign returns the signature authorizing rawURL.
+func (p *Proxy) Sign(rawURL string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(rawURL))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether sig was produced by Sign for rawURL.
+func (p *Proxy) Verify(rawURL, sig string) bool {
+	want, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(rawURL))
+	return hmac.Equal(mac.Sum(nil), want)
+}
+
+// URL returns the proxied URL of rawURL, prefixed with base (empty for a
+// same-origin path, or an absolute origin for external clients).
+func (p *Proxy) URL(base, rawURL string) string {
+	return base + Path + "?url=" + url.QueryEscape(rawURL) + "&sig=" + p.Sign(rawURL)
+}
+
+// Open returns rawURL from the cache, fetching it first on a miss. Concurrent
+// requests for the same URL share one upstream fetch, which runs detached from
+// any one caller's cancellation and is bounded by the proxy's timeout instead.
+func (p *Proxy) Open(ctx context.Context, rawURL string) (*Media, error) {
+	key := cacheKey(rawURL)
+	if media, ok := p.openCached(key); ok {
+		return media, nil
+	}
+
+	result := p.fetches.DoChan(key, func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.timeout)
+		defer cancel()
+		return nil, p.fetch(fetchCtx, rawURL, key)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+	}
+
+	media, ok := p.openCached(key)
+	if !ok {
+		return nil, fmt.Errorf("media evicted before it could be served")
+	}
+	return media, nil
+}
+
+func (p *Proxy) openCached(key string) (*Media, bool) {
+	file, entry, ok := p.cache.get(key)
+	if !ok {
+		return nil, false
+	}
+
+	media := &Media{File: file, ContentType: entry.contentType}
+	if info, err := file.Stat(); err == nil {
+		media.ModTime = info.ModTime()
+	}
+	return media, true
+}
+
+func (p *Proxy) fetch(ctx context.Context, rawURL, key string) error {
+	if err := httpc.ValidateRequestURL(ctx, rawURL, p.allowPrivate); err != nil {
+		return fmt.Errorf("validate media url: %w", err)
+	}
+
+	client, err := httpc.NewClient(p.timeout, "", p.allowPrivate)
+	if err != nil {
+		return fmt.Errorf("create client: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	httpc.SetDefaultHeaders(req)
+	req.Header.Set("Accept", "image/*,video/*,audio/*;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if resp.ContentLength > p.maxBytes {
+		return ErrTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBytes+1))
+	if err != nil {
+		return fmt.Errorf("read media: %w", err)
+	}
+	if int64(len(data)) > p.maxBytes {
+		return ErrTooLarge
+	}
+
+	contentType, ok := mediaType(resp.Header.Get("Content-Type"), data)
+	if !ok {
+		return ErrUnsupportedType
+	}
+
+	if err := p.cache.put(key, contentType, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("cache media: %w", err)
+	}
+	p.logger.Debug("media cached", "url", rawURL, "content_type", contentType, "size", len(data))
+
+	return nil
+}
+
+// mediaType returns the content type to serve, sniffing when the upstream
+// type is missing or generic. SVG is refused because it can carry scripts.
+func mediaType(header string, data []byte) (string, bool) {
+	contentType, _, err := mime.ParseMediaType(header)
+	if err != nil || contentType == "" || contentType == "application/octet-stream" {
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	contentType = strings.ToLower(contentType)
+
+	if contentType == "image/svg+xml" {
+		return "", false
+	}
+	for _, prefix := range []string{"image/", "video/", "audio/"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return contentType, true
+		}
+	}
+	return "", false
+}
+
+func cacheKey(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return hex.EncodeToString(sum[:])
+}
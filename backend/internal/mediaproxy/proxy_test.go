@@ -0,0 +1,235 @@
+package mediaproxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/config"
+)
+
+// pngHeader is enough for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newTestProxy(t *testing.T, dir string) *Proxy {
+	t.Helper()
+
+	p, err := New(&config.Config{
+		PullTimeout:       5,
+		AllowPrivateFeeds: true,
+		MediaCacheDir:     dir,
+		MediaCacheSize:    1,
+		MediaMaxSize:      1,
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return p
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	p := newTestProxy(t, dir)
+
+	raw := "https://cdn.example.com/a.png?x=1"
+	sig := p.Sign(raw)
+	if !p.Verify(raw, sig) {
+		t.Fatal("expected signature to verify")
+	}
+	if p.Verify("https://cdn.example.com/b.png", sig) || p.Verify(raw, "") || p.Verify(raw, sig+"x") {
+		t.Fatal("expected mismatched signatures to be rejected")
+	}
+
+	// The generated secret is reused after a restart.
+	if restarted := newTestProxy(t, dir); !restarted.Verify(raw, sig) {
+		t.Fatal("expected signature to survive a restart")
+	}
+
+	proxied, err := url.Parse(p.URL("https://reader.example.com", raw))
+	if err != nil {
+		t.Fatalf("parse proxied url: %v", err)
+	}
+	if proxied.Path != Path || proxied.Query().Get("url") != raw || proxied.Query().Get("sig") != sig {
+		t.Fatalf("unexpected proxied url: %s", proxied)
+	}
+}
+
+func TestOpenFetchesOnceAndEnforcesLimits(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/a.png":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write(pngHeader)
+		case "/big.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write(make([]byte, 2<<20))
+		case "/logo.svg":
+			w.Header().Set("Content-Type", "image/svg+xml")
+			_, _ = w.Write([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>x()</script></svg>`))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := newTestProxy(t, t.TempDir())
+	ctx := context.Background()
+
+	for range 2 {
+		media, err := p.Open(ctx, server.URL+"/a.png")
+		if err != nil {
+			t.Fatalf("Open() failed: %v", err)
+		}
+		data, _ := io.ReadAll(media.File)
+		media.File.Close()
+		if media.ContentType != "image/png" || string(data) != string(pngHeader) {
+			t.Fatalf("unexpected media: type=%q data=%q", media.ContentType, data)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("expected one upstream request, got %d", requests.Load())
+	}
+
+	if _, err := p.Open(ctx, server.URL+"/big.jpg"); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	for _, path := range []string{"/logo.svg", "/page.html"} {
+		if _, err := p.Open(ctx, server.URL+path); !errors.Is(err, ErrUnsupportedType) {
+			t.Fatalf("Open(%s) = %v, want ErrUnsupportedType", path, err)
+		}
+	}
+	if _, err := p.Open(ctx, server.URL+"/missing.png"); err == nil {
+		t.Fatal("expected error for upstream 404")
+	}
+}
+
+func TestOpenSharedFetchSurvivesCancelledCaller(t *testing.T) {
+	var requests atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(pngHeader)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	p := newTestProxy(t, t.TempDir())
+	target := server.URL + "/a.png"
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := p.Open(firstCtx, target)
+		firstErr <- err
+	}()
+	<-started
+
+	type result struct {
+		media *Media
+		err   error
+	}
+	second := make(chan result, 1)
+	go func() {
+		media, err := p.Open(context.Background(), target)
+		second <- result{media, err}
+	}()
+
+	// The first client disconnects; only its own call is abandoned.
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first Open() = %v, want context.Canceled", err)
+	}
+	release <- struct{}{}
+
+	res := <-second
+	if res.err != nil {
+		t.Fatalf("second Open() failed: %v", res.err)
+	}
+	res.media.File.Close()
+	if requests.Load() != 1 {
+		t.Fatalf("expected one upstream request, got %d", requests.Load())
+	}
+}
+
+func TestDiskCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	c, err := newDiskCache(dir, 10)
+	if err != nil {
+		t.Fatalf("newDiskCache() failed: %v", err)
+	}
+
+	for _, key := range []string{"a", "b"} {
+		if err := c.put(key, "image/png", strings.NewReader("12345")); err != nil {
+			t.Fatalf("put(%s) failed: %v", key, err)
+		}
+	}
+	// Touch "a" so "b" becomes the eviction candidate.
+	file, _, ok := c.get("a")
+	if !ok {
+		t.Fatal("expected a to be cached")
+	}
+	file.Close()
+
+	if err := c.put("c", "image/png", strings.NewReader("12345")); err != nil {
+		t.Fatalf("put(c) failed: %v", err)
+	}
+	if _, _, ok := c.get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b"+dataSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("expected evicted file to be removed, got %v", err)
+	}
+
+	// The index is rebuilt from disk on restart.
+	reloaded, err := newDiskCache(dir, 10)
+	if err != nil {
+		t.Fatalf("newDiskCache() failed: %v", err)
+	}
+	for _, key := range []string{"a", "c"} {
+		file, entry, ok := reloaded.get(key)
+		if !ok || entry.contentType != "image/png" {
+			t.Fatalf("expected %s to survive a restart", key)
+		}
+		file.Close()
+	}
+}
+
+func TestRewriteHTML(t *testing.T) {
+	p := newTestProxy(t, t.TempDir())
+
+	img := "https://cdn.example.com/a.png"
+	content := `<p>x</p><img src="` + img + `" srcset="` + img + ` 1x, https://cdn.example.com/b.png 2x"/><video src="https://cdn.example.com/v.mp4" poster="data:image/png;base64,AA"></video><a href="https://example.com/">link</a>`
+	got := p.RewriteHTML(content, "")
+
+	if strings.Contains(got, `src="https://cdn.example.com`) || !strings.Contains(got, `src="`+Path+`?url=`+url.QueryEscape(img)+`&amp;sig=`+p.Sign(img)+`"`) {
+		t.Fatalf("expected img src to be proxied, got %s", got)
+	}
+	if strings.Count(got, Path+"?url=") != 4 {
+		t.Fatalf("expected src, both srcset candidates and video src to be proxied, got %s", got)
+	}
+	if !strings.Contains(got, `poster="data:image/png;base64,AA"`) || !strings.Contains(got, `href="https://example.com/"`) {
+		t.Fatalf("expected data URLs and links to be left alone, got %s", got)
+	}
+
+	if plain := "<p>no media</p>"; p.RewriteHTML(plain, "") != plain {
+		t.Fatal("expected content without media to be returned unchanged")
+	}
+}
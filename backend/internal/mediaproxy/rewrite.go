@@ -0,0 +1,92 @@
+package mediaproxy
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// RewriteHTML points image and video sources of stored (already sanitized)
+// HTML at the proxy. base prefixes the proxy path, see URL. Only absolute
+// http(s) URLs are rewritten.
+func (p *Proxy) RewriteHTML(content, base string) string {
+	if !strings.Contains(content, "<img") && !strings.Contains(content, "<video") && !strings.Contains(content, "<source") {
+		return content
+	}
+
+	container := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return content
+	}
+	for _, node := range nodes {
+		container.AppendChild(node)
+	}
+
+	p.rewriteNode(container, base)
+
+	var buf bytes.Buffer
+	for child := container.FirstChild; child != nil; child = child.NextSibling {
+		if err := html.Render(&buf, child); err != nil {
+			return content
+		}
+	}
+	return buf.String()
+}
+
+func (p *Proxy) rewriteNode(node *html.Node, base string) {
+	if node.Type == html.ElementNode {
+		switch node.DataAtom {
+		case atom.Img, atom.Source:
+			p.rewriteAttrs(node, base, "src", "srcset")
+		case atom.Video:
+			p.rewriteAttrs(node, base, "src", "poster")
+		}
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		p.rewriteNode(child, base)
+	}
+}
+
+func (p *Proxy) rewriteAttrs(node *html.Node, base string, keys ...string) {
+	for i, attr := range node.Attr {
+		for _, key := range keys {
+			if attr.Key != key {
+				continue
+			}
+			if key == "srcset" {
+				node.Attr[i].Val = p.rewriteSrcset(attr.Val, base)
+			} else {
+				node.Attr[i].Val = p.rewriteURL(attr.Val, base)
+			}
+		}
+	}
+}
+
+func (p *Proxy) rewriteURL(raw, base string) string {
+	lower := strings.ToLower(raw)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return raw
+	}
+	return p.URL(base, raw)
+}
+
+func (p *Proxy) rewriteSrcset(raw, base string) string {
+	candidates := strings.Split(raw, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = p.rewriteURL(fields[0], base)
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
@@ -19,11 +19,79 @@ type Feed struct {
 	Proxy     string `json:"proxy,omitempty"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
+	// UserAgent overrides FUSION_USER_AGENT for this feed's requests.
+	UserAgent string `json:"user_agent,omitempty"`
+	// Kind is FeedKindFeed for RSS/Atom/JSON feeds or FeedKindScrape for HTML
+	// pages turned into items by Scrape.
+	Kind   string        `json:"kind"`
+	Scrape *ScrapeRecipe `json:"scrape,omitempty"`
+
+	// RetentionDays and RetentionMaxItems override the global retention
+	// policy. -1 inherits the global default; 0 keeps items forever.
+	RetentionDays     int64 `json:"retention_days"`
+	RetentionMaxItems int64 `json:"retention_max_items"`
+
+	// PullInterval and MaxBackoff override FUSION_PULL_INTERVAL and
+	// FUSION_PULL_MAX_BACKOFF in seconds. 0 inherits the global default.
+	PullInterval int64 `json:"pull_interval"`
+	MaxBackoff   int64 `json:"max_backoff"`
+
+	// FullText fetches each new item's link and stores the extracted article.
+	FullText bool `json:"full_text"`
+	// UpdateMode controls changed items that were already ingested, see
+	// ItemUpdateIgnore and friends.
+	UpdateMode string `json:"update_mode"`
+
+	// Auth holds decrypted request credentials. It is never serialized;
+	// HasAuth tells clients whether any are configured.
+	Auth    *FeedAuth `json:"-"`
+	HasAuth bool      `json:"has_auth"`
 
 	FetchState FeedFetchState `json:"fetch_state"`
 
 	UnreadCount int64 `json:"unread_count"`
 	ItemCount   int64 `json:"item_count"`
+	// HasIcon reports whether a favicon is cached for GET /api/feeds/:id/icon.
+	HasIcon bool `json:"has_icon"`
+	// WebSubActive reports whether a verified, unexpired WebSub subscription
+	// delivers updates, in which case polling only runs as a safety net.
+	WebSubActive bool `json:"websub_active"`
+}
+
+// FeedAuth is sent with every request for a feed: HTTP Basic credentials or a
+// bearer token, a Cookie header and arbitrary extra headers.
+type FeedAuth struct {
+	Username string            `json:"username,omitempty"`
+	Password string            `json:"password,omitempty"`
+	Token    string            `json:"token,omitempty"`
+	Cookie   string            `json:"cookie,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// IsZero reports whether no credential or header is set.
+func (a *FeedAuth) IsZero() bool {
+	return a == nil || (a.Username == "" && a.Password == "" && a.Token == "" && a.Cookie == "" && len(a.Headers) == 0)
+}
+
+// Feed kinds.
+const (
+	FeedKindFeed   = "feed"
+	FeedKindScrape = "scrape"
+)
+
+// ScrapeRecipe extracts items from an HTML page. Each selector is a CSS
+// selector evaluated inside the item element, optionally followed by
+// "@attr" to read an attribute instead of the text; "@attr" alone reads the
+// item element itself.
+type ScrapeRecipe struct {
+	// Item selects one element per item on the page.
+	Item    string `json:"item"`
+	Title   string `json:"title"`
+	Link    string `json:"link,omitempty"`
+	Date    string `json:"date,omitempty"`
+	Content string `json:"content,omitempty"`
+	// DateLayout is a Go time layout for Date; empty tries common formats.
+	DateLayout string `json:"date_layout,omitempty"`
 }
 
 // FeedFetchState stores runtime pull metadata for a feed.
@@ -53,21 +121,221 @@ type FeedFetchState struct {
 	LastError string `json:"last_error,omitempty"`
 	// ConsecutiveFailures is reset on success and incremented on each failure.
 	ConsecutiveFailures int64 `json:"consecutive_failures"`
+	// AdaptiveInterval is the interval in seconds chosen by adaptive polling for
+	// the latest schedule; 0 when adaptive polling is off or overridden.
+	AdaptiveInterval int64 `json:"adaptive_interval"`
+	// PublishInterval is the median gap in seconds between recent items; 0 if unknown.
+	PublishInterval int64 `json:"publish_interval"`
+	// NotModifiedRatio is the smoothed share (0-1) of successful checks that
+	// returned no new items, including 304 responses.
+	NotModifiedRatio float64 `json:"not_modified_ratio"`
+	// RedirectURL is where the latest check ended after only permanent
+	// (301/308) redirects; empty when the feed link was served directly.
+	RedirectURL string `json:"redirect_url,omitempty"`
+	// RedirectCount is the number of consecutive checks that ended at RedirectURL.
+	RedirectCount int64 `json:"redirect_count"`
+	// SuspendReason explains an automatic suspension (e.g. HTTP 410 Gone).
+	SuspendReason string `json:"suspend_reason,omitempty"`
+}
+
+// FeedLinkChange records a feed link replaced after a stable permanent redirect.
+type FeedLinkChange struct {
+	ID        int64  `json:"id"`
+	FeedID    int64  `json:"feed_id"`
+	OldLink   string `json:"old_link"`
+	NewLink   string `json:"new_link"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// FeedFetchLog is one pull attempt of a feed.
+type FeedFetchLog struct {
+	ID         int64 `json:"id"`
+	FeedID     int64 `json:"feed_id"`
+	FetchedAt  int64 `json:"fetched_at"`
+	DurationMs int64 `json:"duration_ms"`
+	// HTTPStatus is 0 when no response was received.
+	HTTPStatus int   `json:"http_status"`
+	Bytes      int64 `json:"bytes"`
+	NewItems   int64 `json:"new_items"`
+	// ErrorClass is empty for successful attempts (including 304).
+	ErrorClass string `json:"error_class"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Fetch error classes.
+const (
+	FetchErrorTimeout  = "timeout"
+	FetchErrorNetwork  = "network"
+	FetchErrorHTTP     = "http"
+	FetchErrorParse    = "parse"
+	FetchErrorInternal = "internal"
+)
+
+// FeedHealth aggregates the fetch log and recent items of a feed.
+type FeedHealth struct {
+	FeedID int64  `json:"feed_id"`
+	Name   string `json:"name"`
+	// Attempts, Successes, AvgDurationMs and Bytes cover the entries kept in
+	// the fetch log.
+	Attempts      int64 `json:"attempts"`
+	Successes     int64 `json:"successes"`
+	AvgDurationMs int64 `json:"avg_duration_ms"`
+	Bytes         int64 `json:"bytes"`
+	// SuccessRate is Successes/Attempts, 0 without attempts.
+	SuccessRate float64 `json:"success_rate"`
+	// ItemsPerDay averages new items over the last 30 days, or the feed's age
+	// when younger.
+	ItemsPerDay    float64 `json:"items_per_day"`
+	LastNewItemAt  int64   `json:"last_new_item_at"`
+	LastFetchAt    int64   `json:"last_fetch_at"`
+	LastErrorClass string  `json:"last_error_class"`
+}
+
+// Job states.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job kinds.
+const (
+	JobRefreshAll  = "refresh_all"
+	JobImport      = "import"
+	JobInitialPull = "initial_pull"
+)
+
+// Job is a background task tracked by the job manager. Done counts finished
+// steps out of Total, Failed those that failed; Errors keeps the first step
+// errors. Error is why the job as a whole failed or was cancelled.
+type Job struct {
+	ID         int64    `json:"id"`
+	Kind       string   `json:"kind"`
+	State      string   `json:"state"`
+	Total      int      `json:"total"`
+	Done       int      `json:"done"`
+	Failed     int      `json:"failed"`
+	Errors     []string `json:"errors"`
+	Error      string   `json:"error,omitempty"`
+	CreatedAt  int64    `json:"created_at"`
+	StartedAt  int64    `json:"started_at"`
+	FinishedAt int64    `json:"finished_at"`
 }
 
+// FeedUnreadCount is the number of unread items of a feed.
+type FeedUnreadCount struct {
+	FeedID      int64 `json:"feed_id"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// PullStatus reports the per-host limits of the puller and its counters for
+// every host requested since startup.
+type PullStatus struct {
+	HostConcurrency int              `json:"host_concurrency"`
+	HostDelay       int              `json:"host_delay"` // seconds
+	Hosts           []PullHostStatus `json:"hosts"`
+}
+
+// PullHostStatus holds the limiter counters of one host. Throttled counts
+// Retry-After responses, Deferred the pulls skipped while the host was blocked.
+type PullHostStatus struct {
+	Host          string `json:"host"`
+	InFlight      int    `json:"in_flight"`
+	Waiting       int    `json:"waiting"`
+	Requests      int64  `json:"requests"`
+	Throttled     int64  `json:"throttled"`
+	Deferred      int64  `json:"deferred"`
+	LastRequestAt int64  `json:"last_request_at"`
+	BlockedUntil  int64  `json:"blocked_until"` // 0 when not blocked
+}
+
+// FeedIcon is a cached favicon for a feed. Data is empty when discovery failed;
+// RefreshAfter then throttles the next attempt.
+type FeedIcon struct {
+	FeedID       int64
+	MimeType     string
+	Data         []byte
+	SourceURL    string
+	FetchedAt    int64
+	RefreshAfter int64
+}
+
+// WebSub subscription states.
+const (
+	WebSubStatePending = "pending"
+	WebSubStateActive  = "active"
+	WebSubStateFailed  = "failed"
+)
+
+// WebSubSubscription is a feed's push subscription at a WebSub hub.
+type WebSubSubscription struct {
+	FeedID         int64
+	HubURL         string
+	TopicURL       string
+	Secret         string
+	State          string
+	LeaseSeconds   int64
+	LeaseExpiresAt int64
+	LastError      string
+	CreatedAt      int64
+	UpdatedAt      int64
+}
+
+// Feed update modes for items that change upstream after ingestion.
+const (
+	ItemUpdateIgnore = "ignore"
+	ItemUpdateSilent = "update"
+	ItemUpdateUnread = "update_unread"
+)
+
 // Item represents a feed item.
 type Item struct {
+	ID         int64       `json:"id"`
+	FeedID     int64       `json:"feed_id"`
+	GUID       string      `json:"guid"`
+	Title      string      `json:"title"`
+	Link       string      `json:"link"`
+	Content    string      `json:"content"`
+	Author     string      `json:"author"`
+	Summary    string      `json:"summary"`
+	Categories []string    `json:"categories"`
+	Enclosures []Enclosure `json:"enclosures"`
+	// Duration (seconds), Episode and ImageURL come from podcast metadata.
+	Duration int64  `json:"duration"`
+	Episode  int64  `json:"episode"`
+	ImageURL string `json:"image_url"`
+	// ReadableContent is the article extracted from Link, empty until fetched.
+	ReadableContent string `json:"readable_content"`
+	// PlaybackPosition is the saved media position in seconds.
+	PlaybackPosition int64 `json:"playback_position"`
+	PubDate          int64 `json:"pub_date"`
+	Unread           bool  `json:"unread"`
+	// GroupID is set when a rule moved the item into another group's view.
+	GroupID   int64 `json:"group_id,omitempty"`
+	CreatedAt int64 `json:"created_at"`
+}
+
+// ItemRevision is a previous version of an item, recorded when an update
+// replaced it. CreatedAt is when it was replaced.
+type ItemRevision struct {
 	ID        int64  `json:"id"`
-	FeedID    int64  `json:"feed_id"`
-	GUID      string `json:"guid"`
+	ItemID    int64  `json:"item_id"`
 	Title     string `json:"title"`
 	Link      string `json:"link"`
 	Content   string `json:"content"`
-	PubDate   int64  `json:"pub_date"`
-	Unread    bool   `json:"unread"`
 	CreatedAt int64  `json:"created_at"`
 }
 
+// Enclosure is a media file attached to an item. Length is the size in bytes
+// as declared by the feed (0 when unknown).
+type Enclosure struct {
+	URL    string `json:"url"`
+	Type   string `json:"type"`
+	Length int64  `json:"length"`
+}
+
 // Bookmark represents a saved item snapshot.
 type Bookmark struct {
 	ID       int64  `json:"id"`
@@ -85,3 +353,48 @@ type Bookmark struct {
 	Unread    bool  `json:"unread"`
 	CreatedAt int64 `json:"created_at"`
 }
+
+// Rule is an ingest-time filter evaluated against new items. FeedID or
+// GroupID limit it to one feed or group; both zero makes it global.
+type Rule struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	FeedID  int64  `json:"feed_id,omitempty"`
+	GroupID int64  `json:"group_id,omitempty"`
+	// Field is RuleFieldTitle and friends; MatchType is RuleMatchContains
+	// (case-insensitive substring) or RuleMatchRegex.
+	Field     string `json:"field"`
+	MatchType string `json:"match_type"`
+	Pattern   string `json:"pattern"`
+	Action    string `json:"action"`
+	// TargetGroupID is the group an item is shown in for RuleActionMove.
+	TargetGroupID int64 `json:"target_group_id,omitempty"`
+	Enabled       bool  `json:"enabled"`
+	HitCount      int64 `json:"hit_count"`
+	LastHitAt     int64 `json:"last_hit_at"`
+	CreatedAt     int64 `json:"created_at"`
+	UpdatedAt     int64 `json:"updated_at"`
+}
+
+// Rule fields.
+const (
+	RuleFieldTitle   = "title"
+	RuleFieldContent = "content"
+	RuleFieldLink    = "link"
+	RuleFieldAuthor  = "author"
+	RuleFieldAny     = "any"
+)
+
+// Rule match types.
+const (
+	RuleMatchContains = "contains"
+	RuleMatchRegex    = "regex"
+)
+
+// Rule actions.
+const (
+	RuleActionDrop     = "drop"
+	RuleActionRead     = "read"
+	RuleActionBookmark = "bookmark"
+	RuleActionMove     = "move"
+)
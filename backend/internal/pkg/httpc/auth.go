@@ -0,0 +1,135 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/0x2E/fusion/internal/model"
+	"golang.org/x/net/http/httpguts"
+)
+
+// reservedHeaders are managed by the client or the pull policy and cannot be
+// overridden per feed.
+var reservedHeaders = map[string]struct{}{
+	"Connection":          {},
+	"Content-Length":      {},
+	"Host":                {},
+	"If-Modified-Since":   {},
+	"If-None-Match":       {},
+	"Proxy-Authorization": {},
+	"Te":                  {},
+	"Trailer":             {},
+	"Transfer-Encoding":   {},
+	"Upgrade":             {},
+}
+
+// ValidateFeedAuth rejects credentials that cannot be sent as HTTP headers.
+func ValidateFeedAuth(auth *model.FeedAuth) error {
+	if auth == nil {
+		return nil
+	}
+	if auth.Token != "" && (auth.Username != "" || auth.Password != "") {
+		return fmt.Errorf("token and username/password are mutually exclusive")
+	}
+	for _, value := range []string{auth.Username, auth.Password, auth.Token, auth.Cookie} {
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return fmt.Errorf("invalid credential value")
+		}
+	}
+	for name, value := range auth.Headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if _, ok := reservedHeaders[http.CanonicalHeaderKey(name)]; ok {
+			return fmt.Errorf("header %q cannot be overridden", name)
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return fmt.Errorf("invalid value for header %q", name)
+		}
+	}
+	return nil
+}
+
+// SetFeedAuth adds per-feed headers and credentials to req. Explicit Basic,
+// bearer and cookie settings win over same-named custom headers. net/http
+// drops only Authorization and Cookie when a redirect leaves the original
+// host; send req with AuthClient so custom headers are dropped as well.
+func SetFeedAuth(req *http.Request, auth *model.FeedAuth) {
+	if auth.IsZero() {
+		return
+	}
+	for name, value := range auth.Headers {
+		req.Header.Set(name, value)
+	}
+	switch {
+	case auth.Token != "":
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(auth.Token))
+	case auth.Username != "" || auth.Password != "":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	if auth.Cookie != "" {
+		req.Header.Set("Cookie", auth.Cookie)
+	}
+}
+
+// AuthClient returns a copy of client that removes auth's custom headers
+// from redirects to a host other than the one first requested. client is
+// returned as is when auth has no custom headers.
+func AuthClient(client *http.Client, auth *model.FeedAuth) *http.Client {
+	if auth.IsZero() || len(auth.Headers) == 0 {
+		return client
+	}
+
+	scoped := *client
+	next := client.CheckRedirect
+	scoped.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if next != nil {
+			if err := next(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if len(via) > 0 && !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+			for name := range auth.Headers {
+				req.Header.Del(name)
+			}
+		}
+		return nil
+	}
+	return &scoped
+}
+
+// FeedAuthClient returns AuthClient(client, auth) with a transport that adds
+// auth to every request for host. It lets libraries that build their own
+// requests, such as feed discovery, send a feed's credentials; requests to
+// other hosts, redirects included, go out without them.
+func FeedAuthClient(client *http.Client, auth *model.FeedAuth, host string) *http.Client {
+	if auth.IsZero() {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	scoped := *AuthClient(client, auth)
+	scoped.Transport = &authTransport{base: base, auth: auth, host: host}
+	return &scoped
+}
+
+type authTransport struct {
+	base http.RoundTripper
+	auth *model.FeedAuth
+	host string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.EqualFold(req.URL.Host, t.host) {
+		req = req.Clone(req.Context())
+		SetFeedAuth(req, t.auth)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,164 @@
+package httpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+func TestSetFeedAuth(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/feed", nil)
+	SetFeedAuth(req, &model.FeedAuth{
+		Username: "alice",
+		Password: "secret",
+		Cookie:   "session=abc",
+		Headers:  map[string]string{"Authorization": "ignored", "X-Api-Key": "k1"},
+	})
+
+	if user, pass, ok := req.BasicAuth(); !ok || user != "alice" || pass != "secret" {
+		t.Fatalf("BasicAuth() = %q, %q, %v", user, pass, ok)
+	}
+	if got := req.Header.Get("Cookie"); got != "session=abc" {
+		t.Errorf("Cookie = %q", got)
+	}
+	if got := req.Header.Get("X-Api-Key"); got != "k1" {
+		t.Errorf("X-Api-Key = %q", got)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://example.com/feed", nil)
+	SetFeedAuth(req, &model.FeedAuth{Token: "t0k"})
+	if got := req.Header.Get("Authorization"); got != "Bearer t0k" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
+
+func TestAuthClientDropsHeadersOnCrossHostRedirect(t *testing.T) {
+	var gotKey, gotToken string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey, gotToken = r.Header.Get("X-Api-Key"), r.Header.Get("Private-Token")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer other.Close()
+
+	var sameHostKey string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/away", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/feed", http.StatusFound)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/feed", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		sameHostKey = r.Header.Get("X-Api-Key")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	origin := httptest.NewServer(mux)
+	defer origin.Close()
+
+	base, err := NewClient(5*time.Second, "", true)
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	auth := &model.FeedAuth{Headers: map[string]string{"X-Api-Key": "k1", "Private-Token": "p1"}}
+	client := AuthClient(base, auth)
+
+	do := func(target string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		SetFeedAuth(req, auth)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Do(%s) failed: %v", target, err)
+		}
+		resp.Body.Close()
+	}
+
+	do(origin.URL + "/away")
+	if gotKey != "" || gotToken != "" {
+		t.Fatalf("custom headers leaked to %s: X-Api-Key=%q Private-Token=%q", other.URL, gotKey, gotToken)
+	}
+	do(origin.URL + "/moved")
+	if sameHostKey != "k1" {
+		t.Fatalf("same-host redirect lost X-Api-Key: %q", sameHostKey)
+	}
+	if AuthClient(base, &model.FeedAuth{Token: "t"}) != base {
+		t.Fatal("AuthClient() copied the client without custom headers")
+	}
+}
+
+func TestFeedAuthClientAddsCredentialsForHost(t *testing.T) {
+	var otherAuth, otherKey string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAuth, otherKey = r.Header.Get("Authorization"), r.Header.Get("X-Api-Key")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer other.Close()
+
+	var originUser, originKey string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/away", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/feed", http.StatusFound)
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		originUser, _, _ = r.BasicAuth()
+		originKey = r.Header.Get("X-Api-Key")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	origin := httptest.NewServer(mux)
+	defer origin.Close()
+
+	base, err := NewClient(5*time.Second, "", true)
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	auth := &model.FeedAuth{Username: "alice", Password: "secret", Headers: map[string]string{"X-Api-Key": "k1"}}
+	host := strings.TrimPrefix(origin.URL, "http://")
+	client := FeedAuthClient(base, auth, host)
+
+	// Requests are built without credentials, as a library would.
+	for _, target := range []string{origin.URL + "/page", origin.URL + "/away", other.URL + "/direct"} {
+		resp, err := client.Get(target)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", target, err)
+		}
+		resp.Body.Close()
+		if strings.HasPrefix(target, other.URL) || strings.HasSuffix(target, "/away") {
+			if otherAuth != "" || otherKey != "" {
+				t.Fatalf("%s: credentials sent to another host: Authorization=%q X-Api-Key=%q", target, otherAuth, otherKey)
+			}
+		}
+	}
+	if originUser != "alice" || originKey != "k1" {
+		t.Fatalf("credentials missing for %s: user=%q X-Api-Key=%q", host, originUser, originKey)
+	}
+	if FeedAuthClient(base, nil, host) != base {
+		t.Fatal("FeedAuthClient() copied the client without credentials")
+	}
+}
+
+func TestValidateFeedAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    *model.FeedAuth
+		wantErr bool
+	}{
+		{name: "nil", auth: nil},
+		{name: "basic and headers", auth: &model.FeedAuth{Username: "u", Password: "p", Headers: map[string]string{"PRIVATE-TOKEN": "x"}}},
+		{name: "token with basic", auth: &model.FeedAuth{Username: "u", Token: "t"}, wantErr: true},
+		{name: "reserved header", auth: &model.FeedAuth{Headers: map[string]string{"host": "evil"}}, wantErr: true},
+		{name: "invalid header name", auth: &model.FeedAuth{Headers: map[string]string{"Bad Header": "x"}}, wantErr: true},
+		{name: "header injection", auth: &model.FeedAuth{Cookie: "a=b\r\nX-Evil: 1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFeedAuth(tt.auth); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateFeedAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,14 +2,20 @@ package httpc
 
 import (
 	"context"
+	"errors"
 	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/net/http/httpguts"
 )
 
+const maxUserAgentLength = 512
+
 type clientPool struct {
 	mu      sync.RWMutex
 	clients map[string]*http.Client
@@ -94,7 +100,70 @@ func validateDialTarget(ctx context.Context, addr string, allowPrivate bool) err
 	return validatePublicHost(ctx, host)
 }
 
+// DefaultUserAgent is sent unless FUSION_USER_AGENT or a per-feed override is set.
+const DefaultUserAgent = "fusion/1.0 (+https://github.com/0x2E/fusion)"
+
+var userAgent atomic.Pointer[string]
+
+// SetUserAgent changes the User-Agent used by SetDefaultHeaders; an empty
+// value restores DefaultUserAgent.
+func SetUserAgent(ua string) {
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	userAgent.Store(&ua)
+}
+
+// UserAgent returns the User-Agent used by SetDefaultHeaders.
+func UserAgent() string {
+	if ua := userAgent.Load(); ua != nil {
+		return *ua
+	}
+	return DefaultUserAgent
+}
+
 // SetDefaultHeaders adds default headers required for feed fetching.
 func SetDefaultHeaders(req *http.Request) {
-	req.Header.Set("User-Agent", "fusion/1.0")
+	req.Header.Set("User-Agent", UserAgent())
+}
+
+// SetFeedUserAgent applies a per-feed User-Agent override when one is set.
+func SetFeedUserAgent(req *http.Request, ua string) {
+	if ua != "" {
+		req.Header.Set("User-Agent", ua)
+	}
+}
+
+// UserAgentClient returns a copy of client that sends ua, or UserAgent() when
+// ua is empty, on every request. It lets libraries that build their own
+// requests, such as feed discovery, honor the configured User-Agent.
+func UserAgentClient(client *http.Client, ua string) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	scoped := *client
+	scoped.Transport = &userAgentTransport{base: base, userAgent: ua}
+	return &scoped
+}
+
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	SetDefaultHeaders(req)
+	SetFeedUserAgent(req, t.userAgent)
+	return t.base.RoundTrip(req)
+}
+
+// ValidateUserAgent rejects values that cannot be sent as a header.
+func ValidateUserAgent(ua string) error {
+	if len(ua) > maxUserAgentLength || !httpguts.ValidHeaderFieldValue(ua) {
+		return errors.New("invalid user agent")
+	}
+	return nil
 }
@@ -0,0 +1,35 @@
+package httpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUserAgentClientSetsHeader(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.UserAgent()
+	}))
+	defer server.Close()
+
+	base := &http.Client{Timeout: 5 * time.Second}
+	for _, tt := range []struct{ override, want string }{
+		{"", UserAgent()},
+		{"custom/1.0", "custom/1.0"},
+	} {
+		// Go's default User-Agent would be sent if the transport did not set one.
+		resp, err := UserAgentClient(base, tt.override).Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		resp.Body.Close()
+		if got != tt.want {
+			t.Errorf("override %q: User-Agent = %q, want %q", tt.override, got, tt.want)
+		}
+	}
+	if base.Transport != nil {
+		t.Fatal("UserAgentClient modified the original client")
+	}
+}
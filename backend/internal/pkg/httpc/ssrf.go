@@ -32,6 +32,38 @@ func ValidateRequestURL(ctx context.Context, rawURL string, allowPrivate bool) e
 	return nil
 }
 
+// ValidateProxyURL applies the checks of ValidateRequestURL to a per-feed
+// proxy, also accepting the SOCKS5 schemes http.Transport supports. An empty
+// proxy is valid.
+func ValidateProxyURL(ctx context.Context, rawURL string, allowPrivate bool) error {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url: %w", err)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme: %s", parsed.Scheme)
+	}
+
+	host := strings.TrimSpace(parsed.Hostname())
+	if host == "" {
+		return fmt.Errorf("proxy host is required")
+	}
+
+	if !allowPrivate {
+		if err := validatePublicHost(ctx, host); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func validatePublicHost(ctx context.Context, host string) error {
 	if strings.EqualFold(host, "localhost") {
 		return fmt.Errorf("private host is not allowed")
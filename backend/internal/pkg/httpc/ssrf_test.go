@@ -32,6 +32,32 @@ func TestValidateRequestURL(t *testing.T) {
 	}
 }
 
+func TestValidateProxyURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		rawURL       string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{name: "empty", rawURL: "", wantErr: false},
+		{name: "public http", rawURL: "http://93.184.216.34:8080", wantErr: false},
+		{name: "public socks5", rawURL: "socks5://93.184.216.34:1080", wantErr: false},
+		{name: "missing host", rawURL: "socks5://", wantErr: true},
+		{name: "unsupported scheme", rawURL: "ftp://93.184.216.34", wantErr: true},
+		{name: "loopback blocked", rawURL: "socks5h://127.0.0.1:1080", wantErr: true},
+		{name: "allow private", rawURL: "socks5h://127.0.0.1:1080", allowPrivate: true, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProxyURL(context.Background(), tt.rawURL, tt.allowPrivate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateProxyURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestRedirectValidatorBlocksPrivateTargets(t *testing.T) {
 	t.Run("block private target", func(t *testing.T) {
 		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://127.0.0.1/feed.xml", nil)
@@ -0,0 +1,435 @@
+// Package readability extracts the main article body from an HTML page using
+// a simplified version of the Readability scoring heuristics: paragraphs
+// score their ancestors, class/id names and link density adjust the scores,
+// and the best container plus related siblings form the article.
+package readability
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrNoContent is returned when no element holds enough text to be an article.
+var ErrNoContent = errors.New("no article content found")
+
+const (
+	// minParagraphLength is the text length below which a paragraph does not
+	// contribute to its ancestors' scores.
+	minParagraphLength = 25
+	// minArticleLength is the text length an extracted article must reach.
+	minArticleLength = 200
+)
+
+var (
+	unlikelyCandidates = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote`)
+	maybeCandidate     = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveNames      = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativeNames      = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// removedTags never contain article text.
+var removedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true,
+	atom.Textarea: true, atom.Nav: true, atom.Aside: true, atom.Footer: true,
+	atom.Svg: true, atom.Canvas: true, atom.Object: true, atom.Embed: true,
+	atom.Link: true, atom.Meta: true, atom.Template: true,
+}
+
+// Extract parses an HTML document and returns the main article as an HTML
+// fragment. Relative URLs are resolved against pageURL when it is non-nil.
+func Extract(r io.Reader, pageURL *url.URL) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", fmt.Errorf("parse html: %w", err)
+	}
+
+	body := findFirst(doc, atom.Body)
+	if body == nil {
+		return "", ErrNoContent
+	}
+
+	prune(body)
+
+	article := selectArticle(body)
+	if article == nil || textLength(article) < minArticleLength {
+		return "", ErrNoContent
+	}
+
+	clean(article)
+	fixLazyImages(article)
+	if pageURL != nil {
+		resolveURLs(article, pageURL)
+	}
+
+	var buf bytes.Buffer
+	for child := article.FirstChild; child != nil; child = child.NextSibling {
+		if err := html.Render(&buf, child); err != nil {
+			return "", fmt.Errorf("render article: %w", err)
+		}
+	}
+
+	content := strings.TrimSpace(buf.String())
+	if content == "" {
+		return "", ErrNoContent
+	}
+	return content, nil
+}
+
+// prune drops elements that never hold article text: non-content tags, hidden
+// elements and blocks whose class/id marks them as page chrome.
+func prune(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		switch {
+		case child.Type == html.CommentNode:
+			n.RemoveChild(child)
+		case child.Type == html.ElementNode && (removedTags[child.DataAtom] || isHidden(child) || isUnlikely(child)):
+			n.RemoveChild(child)
+		default:
+			prune(child)
+		}
+		child = next
+	}
+}
+
+func isHidden(n *html.Node) bool {
+	if hasAttr(n, "hidden") || strings.EqualFold(attr(n, "aria-hidden"), "true") {
+		return true
+	}
+	style := strings.ToLower(strings.ReplaceAll(attr(n, "style"), " ", ""))
+	return strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden")
+}
+
+func isUnlikely(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Body, atom.A, atom.Article, atom.Main:
+		return false
+	}
+	names := attr(n, "class") + " " + attr(n, "id")
+	if strings.TrimSpace(names) == "" {
+		return false
+	}
+	return unlikelyCandidates.MatchString(names) && !maybeCandidate.MatchString(names) && !hasAncestor(n, atom.Table, atom.Code)
+}
+
+// selectArticle scores paragraph containers and returns a node holding the
+// best candidate and its related siblings.
+func selectArticle(body *html.Node) *html.Node {
+	scores := map[*html.Node]float64{}
+	var order []*html.Node
+
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			order = append(order, n)
+		}
+		scores[n] += score
+	}
+
+	walk(body, func(n *html.Node) {
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		case atom.Div:
+			// Divs without block children act as paragraphs.
+			if hasBlockChild(n) {
+				return
+			}
+		default:
+			return
+		}
+
+		text := innerText(n)
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphLength {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(length/100), 3)
+		addScore(n.Parent, score)
+		if n.Parent != nil {
+			addScore(n.Parent.Parent, score/2)
+		}
+	})
+
+	var top *html.Node
+	topScore := 0.0
+	for _, n := range order {
+		score := scores[n] * (1 - linkDensity(n))
+		scores[n] = score
+		if top == nil || score > topScore {
+			top, topScore = n, score
+		}
+	}
+
+	if top == nil {
+		if article := findFirst(body, atom.Article); article != nil {
+			return article
+		}
+		return nil
+	}
+
+	if top.Parent == nil {
+		return top
+	}
+
+	// Siblings that scored well, or read like prose, belong to the article.
+	container := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	threshold := math.Max(10, topScore*0.2)
+	for sibling := top.Parent.FirstChild; sibling != nil; {
+		next := sibling.NextSibling
+		if sibling == top || isRelatedSibling(sibling, scores, threshold) {
+			top.Parent.RemoveChild(sibling)
+			container.AppendChild(sibling)
+		}
+		sibling = next
+	}
+	return container
+}
+
+func isRelatedSibling(n *html.Node, scores map[*html.Node]float64, threshold float64) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if score, ok := scores[n]; ok && score >= threshold {
+		return true
+	}
+	if n.DataAtom != atom.P {
+		return false
+	}
+	text := innerText(n)
+	length := utf8.RuneCountInString(text)
+	density := linkDensity(n)
+	return (length > 80 && density < 0.25) || (length > 0 && density == 0 && strings.Contains(text, ". "))
+}
+
+func initialScore(n *html.Node) float64 {
+	score := classWeight(n)
+	switch n.DataAtom {
+	case atom.Div, atom.Article, atom.Main, atom.Section:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+	return score
+}
+
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, name := range []string{attr(n, "class"), attr(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if negativeNames.MatchString(name) {
+			weight -= 25
+		}
+		if positiveNames.MatchString(name) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// clean removes link-heavy or negatively named blocks left inside the article.
+func clean(article *html.Node) {
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; {
+			next := child.NextSibling
+			if child.Type == html.ElementNode && isClutter(child) {
+				n.RemoveChild(child)
+			} else {
+				visit(child)
+			}
+			child = next
+		}
+	}
+	visit(article)
+}
+
+func isClutter(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Div, atom.Section, atom.Ul, atom.Ol, atom.Table:
+	default:
+		return false
+	}
+	if classWeight(n) < 0 {
+		return true
+	}
+	length := textLength(n)
+	if length == 0 {
+		return findFirst(n, atom.Img) == nil && findFirst(n, atom.Video) == nil && findFirst(n, atom.Picture) == nil
+	}
+	return length < 500 && linkDensity(n) > 0.5
+}
+
+// fixLazyImages promotes common lazy-loading attributes to src.
+func fixLazyImages(article *html.Node) {
+	walk(article, func(n *html.Node) {
+		if n.DataAtom != atom.Img {
+			return
+		}
+		if src := strings.TrimSpace(attr(n, "src")); src != "" && !strings.HasPrefix(src, "data:") {
+			return
+		}
+		for _, key := range []string{"data-src", "data-original", "data-lazy-src"} {
+			if value := strings.TrimSpace(attr(n, key)); value != "" {
+				setAttr(n, "src", value)
+				return
+			}
+		}
+	})
+}
+
+func resolveURLs(article *html.Node, base *url.URL) {
+	walk(article, func(n *html.Node) {
+		for i, a := range n.Attr {
+			switch a.Key {
+			case "href", "src", "poster":
+				if resolved, err := base.Parse(strings.TrimSpace(a.Val)); err == nil && !strings.HasPrefix(strings.TrimSpace(a.Val), "#") {
+					n.Attr[i].Val = resolved.String()
+				}
+			case "srcset":
+				n.Attr[i].Val = resolveSrcset(a.Val, base)
+			}
+		}
+	})
+}
+
+func resolveSrcset(srcset string, base *url.URL) string {
+	candidates := strings.Split(srcset, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		if resolved, err := base.Parse(fields[0]); err == nil {
+			fields[0] = resolved.String()
+		}
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
+
+func linkDensity(n *html.Node) float64 {
+	length := textLength(n)
+	if length == 0 {
+		return 0
+	}
+	linkLength := 0
+	walk(n, func(child *html.Node) {
+		if child.DataAtom == atom.A {
+			linkLength += textLength(child)
+		}
+	})
+	return math.Min(float64(linkLength)/float64(length), 1)
+}
+
+func hasBlockChild(n *html.Node) bool {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch child.DataAtom {
+		case atom.P, atom.Div, atom.Section, atom.Article, atom.Table, atom.Ul, atom.Ol,
+			atom.Pre, atom.Blockquote, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			return true
+		}
+	}
+	return false
+}
+
+func textLength(n *html.Node) int {
+	return utf8.RuneCountInString(innerText(n))
+}
+
+// innerText returns the whitespace-collapsed text of a node.
+func innerText(n *html.Node) string {
+	var b strings.Builder
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	visit(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// walk calls fn for every element below n in document order.
+func walk(n *html.Node, fn func(*html.Node)) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			fn(child)
+		}
+		walk(child, fn)
+	}
+}
+
+func findFirst(n *html.Node, tag atom.Atom) *html.Node {
+	var found *html.Node
+	walk(n, func(child *html.Node) {
+		if found == nil && child.DataAtom == tag {
+			found = child
+		}
+	})
+	return found
+}
+
+func hasAncestor(n *html.Node, tags ...atom.Atom) bool {
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		for _, tag := range tags {
+			if parent.DataAtom == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func setAttr(n *html.Node, key, value string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: value})
+}
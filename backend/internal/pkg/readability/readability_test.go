@@ -0,0 +1,58 @@
+package readability
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const articlePage = `<!doctype html>
+<html><head><title>Post</title><script>var tracking = 1;</script></head>
+<body>
+  <header class="site-header"><a href="/">Home</a> <a href="/about">About</a></header>
+  <nav><a href="/a">A</a><a href="/b">B</a></nav>
+  <div id="main">
+    <div class="post-content">
+      <p>The first paragraph of the article explains the topic in detail, with enough words, commas, and clauses to score well.</p>
+      <p>A second paragraph continues the story, adding context, examples, and a link to <a href="/docs/guide">the guide</a>.</p>
+      <img data-src="/images/figure.png" src="data:image/gif;base64,R0lGOD" alt="figure">
+      <p>The closing paragraph wraps things up, summarising the key points, the caveats, and the next steps.</p>
+      <div class="share-widget"><a href="https://social.example/share">Share</a></div>
+    </div>
+    <div class="comments"><p>First comment, which is long enough to be scored as a paragraph by the extractor.</p></div>
+  </div>
+  <aside class="sidebar"><p>Sidebar text that should never be part of the article body at all.</p></aside>
+  <footer>Copyright</footer>
+</body></html>`
+
+func TestExtractReturnsMainArticle(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post")
+	content, err := Extract(strings.NewReader(articlePage), base)
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"The first paragraph",
+		"The closing paragraph",
+		`href="https://example.com/docs/guide"`,
+		`src="https://example.com/images/figure.png"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected article to contain %q, got:\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{"tracking", "About", "Sidebar", "Copyright", "First comment", "Share"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("expected article to drop %q, got:\n%s", unwanted, content)
+		}
+	}
+}
+
+func TestExtractRejectsPagesWithoutArticle(t *testing.T) {
+	_, err := Extract(strings.NewReader(`<html><body><nav><a href="/">Home</a></nav><p>Short.</p></body></html>`), nil)
+	if !errors.Is(err, ErrNoContent) {
+		t.Fatalf("expected ErrNoContent, got %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+// Package sanitize cleans untrusted HTML from feeds before it is stored. An
+// allowlist decides which elements and attributes survive; everything else is
+// unwrapped (unknown elements) or dropped with its content (active content).
+package sanitize
+
+import (
+	"bytes"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// PolicyVersion identifies the current policy. Bump it whenever the policy
+// changes so stored content is re-sanitized in the background.
+const PolicyVersion = 1
+
+// globalAttrs are allowed on every allowed element.
+var globalAttrs = map[string]bool{"title": true, "lang": true, "dir": true}
+
+// allowedElements maps each allowed element to its extra attributes.
+var allowedElements = map[atom.Atom]map[string]bool{
+	atom.A:          {"href": true},
+	atom.Abbr:       {},
+	atom.Address:    {},
+	atom.Article:    {},
+	atom.Aside:      {},
+	atom.Audio:      {"src": true, "controls": true, "loop": true, "muted": true, "preload": true},
+	atom.B:          {},
+	atom.Bdi:        {},
+	atom.Bdo:        {},
+	atom.Blockquote: {"cite": true},
+	atom.Br:         {},
+	atom.Caption:    {},
+	atom.Cite:       {},
+	atom.Code:       {},
+	atom.Col:        {"span": true},
+	atom.Colgroup:   {"span": true},
+	atom.Dd:         {},
+	atom.Del:        {"cite": true, "datetime": true},
+	atom.Details:    {"open": true},
+	atom.Dfn:        {},
+	atom.Div:        {},
+	atom.Dl:         {},
+	atom.Dt:         {},
+	atom.Em:         {},
+	atom.Figcaption: {},
+	atom.Figure:     {},
+	atom.Footer:     {},
+	atom.H1:         {},
+	atom.H2:         {},
+	atom.H3:         {},
+	atom.H4:         {},
+	atom.H5:         {},
+	atom.H6:         {},
+	atom.Header:     {},
+	atom.Hr:         {},
+	atom.I:          {},
+	atom.Iframe:     {"src": true, "width": true, "height": true, "allowfullscreen": true},
+	atom.Img:        {"src": true, "srcset": true, "alt": true, "width": true, "height": true},
+	atom.Ins:        {"cite": true, "datetime": true},
+	atom.Kbd:        {},
+	atom.Li:         {"value": true},
+	atom.Mark:       {},
+	atom.Ol:         {"start": true, "reversed": true, "type": true},
+	atom.P:          {},
+	atom.Picture:    {},
+	atom.Pre:        {},
+	atom.Q:          {"cite": true},
+	atom.Rp:         {},
+	atom.Rt:         {},
+	atom.Ruby:       {},
+	atom.S:          {},
+	atom.Samp:       {},
+	atom.Section:    {},
+	atom.Small:      {},
+	atom.Source:     {"src": true, "srcset": true, "type": true, "media": true},
+	atom.Span:       {},
+	atom.Strong:     {},
+	atom.Sub:        {},
+	atom.Summary:    {},
+	atom.Sup:        {},
+	atom.Table:      {},
+	atom.Tbody:      {},
+	atom.Td:         {"colspan": true, "rowspan": true},
+	atom.Tfoot:      {},
+	atom.Th:         {"colspan": true, "rowspan": true, "scope": true},
+	atom.Thead:      {},
+	atom.Time:       {"datetime": true},
+	atom.Tr:         {},
+	atom.Track:      {"src": true, "kind": true, "srclang": true, "label": true},
+	atom.U:          {},
+	atom.Ul:         {},
+	atom.Var:        {},
+	atom.Video:      {"src": true, "poster": true, "controls": true, "loop": true, "muted": true, "preload": true, "width": true, "height": true},
+	atom.Wbr:        {},
+}
+
+// droppedElements are removed together with their content.
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Object: true, atom.Embed: true, atom.Applet: true, atom.Param: true,
+	atom.Form: true, atom.Input: true, atom.Button: true, atom.Select: true,
+	atom.Option: true, atom.Textarea: true, atom.Link: true, atom.Meta: true,
+	atom.Base: true, atom.Head: true, atom.Title: true, atom.Svg: true,
+	atom.Math: true, atom.Frame: true, atom.Frameset: true, atom.Canvas: true,
+	atom.Dialog: true,
+}
+
+// trackerPrefixes are host+path prefixes of well-known feed tracking pixels.
+var trackerPrefixes = []string{
+	"feeds.feedburner.com/~r/",
+	"feedproxy.google.com/~r/",
+	"feeds.wordpress.com/1.0/",
+	"pixel.wp.com/",
+	"stats.wordpress.com/",
+	"www.google-analytics.com/",
+	"pixel.quantserve.com/",
+	"counter.theconversation.com/",
+}
+
+// HTML applies the allowlist policy to an HTML fragment. Relative URLs are
+// resolved against baseURL (usually the item link) when it is absolute.
+func HTML(content, baseURL string) string {
+	if strings.TrimSpace(content) == "" {
+		return ""
+	}
+
+	var base *url.URL
+	if parsed, err := url.Parse(baseURL); err == nil && parsed.IsAbs() {
+		base = parsed
+	}
+
+	container := &nethtml.Node{Type: nethtml.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := nethtml.ParseFragment(strings.NewReader(content), &nethtml.Node{
+		Type:     nethtml.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return html.EscapeString(content)
+	}
+	for _, node := range nodes {
+		container.AppendChild(node)
+	}
+
+	sanitizeChildren(container, base)
+
+	var buf bytes.Buffer
+	for child := container.FirstChild; child != nil; child = child.NextSibling {
+		if err := nethtml.Render(&buf, child); err != nil {
+			return html.EscapeString(content)
+		}
+	}
+
+	return buf.String()
+}
+
+func sanitizeChildren(parent *nethtml.Node, base *url.URL) {
+	for node := parent.FirstChild; node != nil; {
+		next := node.NextSibling
+		switch node.Type {
+		case nethtml.TextNode:
+		case nethtml.ElementNode:
+			next = sanitizeElement(node, base)
+		default:
+			// Comments, doctypes and stray documents.
+			parent.RemoveChild(node)
+		}
+		node = next
+	}
+}
+
+// sanitizeElement cleans node in place and returns the next node to visit.
+func sanitizeElement(node *nethtml.Node, base *url.URL) *nethtml.Node {
+	parent, next := node.Parent, node.NextSibling
+
+	if droppedElements[node.DataAtom] {
+		parent.RemoveChild(node)
+		return next
+	}
+
+	allowed, ok := allowedElements[node.DataAtom]
+	if !ok || node.Namespace != "" {
+		// Unwrap: keep the children in place so their text survives.
+		first := node.FirstChild
+		for child := node.FirstChild; child != nil; child = node.FirstChild {
+			node.RemoveChild(child)
+			parent.InsertBefore(child, node)
+		}
+		parent.RemoveChild(node)
+		if first != nil {
+			return first
+		}
+		return next
+	}
+
+	node.Attr = filterAttrs(node, allowed, base)
+
+	switch node.DataAtom {
+	case atom.Img:
+		if isTrackingPixel(node) || (attrValue(node, "src") == "" && attrValue(node, "srcset") == "") {
+			parent.RemoveChild(node)
+			return next
+		}
+	case atom.Iframe, atom.Track:
+		if attrValue(node, "src") == "" {
+			parent.RemoveChild(node)
+			return next
+		}
+		if node.DataAtom == atom.Iframe {
+			node.Attr = append(node.Attr,
+				nethtml.Attribute{Key: "sandbox", Val: "allow-scripts allow-same-origin allow-popups allow-presentation"},
+				nethtml.Attribute{Key: "referrerpolicy", Val: "no-referrer"},
+			)
+		}
+	case atom.A:
+		if attrValue(node, "href") != "" {
+			node.Attr = append(node.Attr, nethtml.Attribute{Key: "rel", Val: "noopener noreferrer"})
+		}
+	}
+
+	sanitizeChildren(node, base)
+	return next
+}
+
+func filterAttrs(node *nethtml.Node, allowed map[string]bool, base *url.URL) []nethtml.Attribute {
+	attrs := make([]nethtml.Attribute, 0, len(node.Attr))
+	seen := map[string]bool{}
+	for _, attr := range node.Attr {
+		key := strings.ToLower(attr.Key)
+		if attr.Namespace != "" || seen[key] || (!allowed[key] && !globalAttrs[key]) {
+			continue
+		}
+
+		val := attr.Val
+		switch key {
+		case "href", "src", "poster", "cite":
+			val = safeURL(val, base, key == "href", key == "src" && node.DataAtom == atom.Img)
+		case "srcset":
+			val = safeSrcset(val, base)
+		case "width", "height":
+			if !isDimension(val) {
+				continue
+			}
+		}
+		if val == "" && (key == "href" || key == "src" || key == "poster" || key == "cite" || key == "srcset") {
+			continue
+		}
+
+		seen[key] = true
+		attrs = append(attrs, nethtml.Attribute{Key: key, Val: val})
+	}
+
+	return attrs
+}
+
+// safeURL resolves raw against base and returns "" for disallowed schemes.
+// Links may also use mailto:, images may embed data:image/* (except SVG).
+func safeURL(raw string, base *url.URL, isLink, isImage bool) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if raw[0] == '#' && isLink {
+		return raw
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return parsed.String()
+	case "mailto":
+		if isLink {
+			return parsed.String()
+		}
+	case "data":
+		lower := strings.ToLower(raw)
+		if isImage && strings.HasPrefix(lower, "data:image/") && !strings.HasPrefix(lower, "data:image/svg") {
+			return raw
+		}
+	case "":
+		// Relative URL without a usable base; harmless to keep.
+		if base == nil {
+			return raw
+		}
+	}
+
+	return ""
+}
+
+func safeSrcset(raw string, base *url.URL) string {
+	var candidates []string
+	for candidate := range strings.SplitSeq(raw, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		resolved := safeURL(fields[0], base, false, false)
+		if resolved == "" {
+			continue
+		}
+		fields[0] = resolved
+		candidates = append(candidates, strings.Join(fields, " "))
+	}
+
+	return strings.Join(candidates, ", ")
+}
+
+func isDimension(val string) bool {
+	val = strings.TrimSuffix(strings.TrimSpace(val), "%")
+	if val == "" {
+		return false
+	}
+	_, err := strconv.ParseUint(strings.TrimSuffix(val, "px"), 10, 32)
+	return err == nil
+}
+
+// isTrackingPixel reports images that only exist to count impressions:
+// 1x1 (or smaller) images and known feed analytics endpoints.
+func isTrackingPixel(node *nethtml.Node) bool {
+	width, height := attrValue(node, "width"), attrValue(node, "height")
+	if width != "" && height != "" && pixelSize(width) <= 1 && pixelSize(height) <= 1 {
+		return true
+	}
+
+	src, err := url.Parse(attrValue(node, "src"))
+	if err != nil {
+		return false
+	}
+	target := strings.ToLower(src.Host + src.Path)
+	for _, prefix := range trackerPrefixes {
+		if strings.HasPrefix(target, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pixelSize(val string) int64 {
+	if strings.HasSuffix(val, "%") {
+		return 100
+	}
+	n, err := strconv.ParseInt(strings.TrimSuffix(val, "px"), 10, 64)
+	if err != nil {
+		return 100
+	}
+	return n
+}
+
+func attrValue(node *nethtml.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "keeps allowed markup",
+			content: `<p>Hello <strong>world</strong><br/><code>x &lt; y</code></p>`,
+			want:    `<p>Hello <strong>world</strong><br/><code>x &lt; y</code></p>`,
+		},
+		{
+			name:    "drops scripts, styles and comments",
+			content: `<p>a</p><script>alert(1)</script><style>p{}</style><!-- note --><noscript><img src="x"></noscript>`,
+			want:    `<p>a</p>`,
+		},
+		{
+			name:    "strips event handlers and inline styles",
+			content: `<p onclick="steal()" style="color:red" class="x" title="t">a</p>`,
+			want:    `<p title="t">a</p>`,
+		},
+		{
+			name:    "unwraps unknown elements",
+			content: `<font color="red"><center>kept <custom-tag>text</custom-tag></center></font>`,
+			want:    `kept text`,
+		},
+		{
+			name:    "resolves relative urls against the item link",
+			content: `<a href="other">o</a><img src="/img/a.png" srcset="a-1x.png 1x, /a-2x.png 2x">`,
+			want:    `<a href="https://example.com/posts/other" rel="noopener noreferrer">o</a><img src="https://example.com/img/a.png" srcset="https://example.com/posts/a-1x.png 1x, https://example.com/a-2x.png 2x"/>`,
+		},
+		{
+			name:    "removes dangerous url schemes",
+			content: `<a href="javascript:alert(1)">x</a><img src="vbscript:x"><a href="mailto:me@example.com">m</a>`,
+			want:    `<a>x</a><a href="mailto:me@example.com" rel="noopener noreferrer">m</a>`,
+		},
+		{
+			name:    "keeps fragment links and raster data images",
+			content: `<a href="#fn1">1</a><img src="data:image/png;base64,AAAA"><img src="data:image/svg+xml;base64,AAAA">`,
+			want:    `<a href="#fn1" rel="noopener noreferrer">1</a><img src="data:image/png;base64,AAAA"/>`,
+		},
+		{
+			name:    "strips tracking pixels",
+			content: `<p>a<img src="https://t.example.com/p.gif" width="1" height="1"><img src="http://feeds.feedburner.com/~r/Blog/~4/abc" alt=""><img src="https://example.com/photo.jpg" width="640" height="1"></p>`,
+			want:    `<p>a<img src="https://example.com/photo.jpg" width="640" height="1"/></p>`,
+		},
+		{
+			name:    "sandboxes iframes",
+			content: `<iframe src="https://www.youtube.com/embed/x" width="560" height="315" onload="x()"></iframe><iframe src="javascript:x"></iframe>`,
+			want:    `<iframe src="https://www.youtube.com/embed/x" width="560" height="315" sandbox="allow-scripts allow-same-origin allow-popups allow-presentation" referrerpolicy="no-referrer"></iframe>`,
+		},
+		{
+			name:    "drops forms and svg",
+			content: `<form action="/x"><input name="q"><button>go</button></form><svg><script>x</script></svg><p>b</p>`,
+			want:    `<p>b</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HTML(tt.content, "https://example.com/posts/1")
+			if got != tt.want {
+				t.Fatalf("HTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLIsIdempotent(t *testing.T) {
+	content := `<div><p>Text <a href="/x">link</a></p><img src="a.png" width="10" height="10"><iframe src="https://player.example.com/1"></iframe></div>`
+	once := HTML(content, "https://example.com/")
+	if twice := HTML(once, "https://example.com/"); twice != once {
+		t.Fatalf("second pass changed content:\n%s\n%s", once, twice)
+	}
+	if strings.Count(once, `rel="noopener noreferrer"`) != 1 || strings.Count(once, "sandbox=") != 1 {
+		t.Fatalf("expected added attributes once, got %s", once)
+	}
+}
+
+func TestHTMLWithoutBaseKeepsRelativeURLs(t *testing.T) {
+	if got := HTML(`<img src="/a.png">`, ""); got != `<img src="/a.png"/>` {
+		t.Fatalf("HTML() = %q", got)
+	}
+	if got := HTML("  ", "https://example.com/"); got != "" {
+		t.Fatalf("expected empty output, got %q", got)
+	}
+}
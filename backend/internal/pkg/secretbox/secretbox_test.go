@@ -0,0 +1,77 @@
+package secretbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	box, err := New([]byte("passphrase"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	sealed, err := box.Seal([]byte("token"))
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+	if strings.Contains(sealed, "token") {
+		t.Fatalf("sealed value leaks plaintext: %q", sealed)
+	}
+	again, _ := box.Seal([]byte("token"))
+	if again == sealed {
+		t.Fatal("expected a fresh nonce per Seal")
+	}
+
+	plaintext, err := box.Open(sealed)
+	if err != nil || string(plaintext) != "token" {
+		t.Fatalf("Open() = %q, %v", plaintext, err)
+	}
+
+	other, _ := New([]byte("other"))
+	if _, err := other.Open(sealed); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid with another key, got %v", err)
+	}
+	if _, err := box.Open("plain"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for unsealed value, got %v", err)
+	}
+}
+
+func TestLoadGeneratesAndReusesKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.key")
+
+	first, err := Load("", path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expected key file: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	sealed, err := first.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+	second, err := Load("", path)
+	if err != nil {
+		t.Fatalf("second Load() failed: %v", err)
+	}
+	if plaintext, err := second.Open(sealed); err != nil || string(plaintext) != "secret" {
+		t.Fatalf("reloaded key cannot open value: %q, %v", plaintext, err)
+	}
+
+	configured, err := Load("configured", path)
+	if err != nil {
+		t.Fatalf("Load(configured) failed: %v", err)
+	}
+	if _, err := configured.Open(sealed); err == nil {
+		t.Fatal("expected configured key to take precedence over the key file")
+	}
+}
@@ -0,0 +1,124 @@
+package pull
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/events"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+func TestRefreshAllPublishesPullEvents(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			http.Error(w, "gone fishing", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>T</title>
+			<item><guid>1</guid><title>One</title></item>
+			<item><guid>2</guid><title>Two</title></item>
+		</channel></rss>`))
+	}))
+	defer server.Close()
+
+	ok, err := st.CreateFeed(1, "OK", server.URL+"/ok", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	broken, err := st.CreateFeed(1, "Broken", server.URL+"/broken", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	p := New(st, &config.Config{PullInterval: 1800, PullTimeout: 5, PullConcurrency: 1, PullMaxBackoff: 604800, AllowPrivateFeeds: true}, nil)
+	ch, unsubscribe := p.Events().Subscribe()
+	defer unsubscribe()
+
+	progress := &progressRecorder{}
+	if count, err := p.RefreshAll(context.Background(), progress); err != nil || count != 2 {
+		t.Fatalf("RefreshAll() = %d, %v; want 2, nil", count, err)
+	}
+	if progress.total != 2 || progress.steps != 2 || len(progress.errs) != 1 || !strings.Contains(progress.errs[0].Error(), "(Broken)") {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+
+	seen := map[string]int{}
+	var last events.RefreshProgress
+	var unread events.Unread
+	for len(ch) > 0 {
+		e := <-ch
+		seen[e.Type]++
+		switch data := e.Data.(type) {
+		case events.Pull:
+			if e.Type == events.PullSucceeded && (data.FeedID != ok.ID || data.NewItems != 2) {
+				t.Errorf("unexpected success event: %+v", data)
+			}
+			if e.Type == events.PullFailed && (data.FeedID != broken.ID || data.HTTPStatus != http.StatusInternalServerError || data.Error == "") {
+				t.Errorf("unexpected failure event: %+v", data)
+			}
+		case events.Items:
+			if data.FeedID != ok.ID || data.Count != 2 || data.Source != "pull" {
+				t.Errorf("unexpected items event: %+v", data)
+			}
+		case events.Unread:
+			unread = data
+		case events.RefreshProgress:
+			last = data
+		}
+	}
+
+	want := map[string]int{
+		events.PullStarted:        2,
+		events.PullSucceeded:      1,
+		events.PullFailed:         1,
+		events.ItemsCreated:       1,
+		events.UnreadCounts:       1,
+		events.RefreshAllProgress: 3,
+	}
+	for typ, n := range want {
+		if seen[typ] != n {
+			t.Errorf("%s published %d times, want %d (all: %v)", typ, seen[typ], n, seen)
+		}
+	}
+	if last != (events.RefreshProgress{Done: 2, Total: 2, Failed: 1}) {
+		t.Errorf("final progress = %+v, want 2/2 with 1 failure", last)
+	}
+	if unread.Total != 2 {
+		t.Errorf("unread total = %d, want 2", unread.Total)
+	}
+}
+
+type progressRecorder struct {
+	mu    sync.Mutex
+	total int
+	steps int
+	errs  []error
+}
+
+func (r *progressRecorder) SetTotal(total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total = total
+}
+
+func (r *progressRecorder) Step(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps++
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+}
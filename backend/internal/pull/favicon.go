@@ -0,0 +1,251 @@
+package pull
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"github.com/0x2E/fusion/internal/store"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// faviconRefreshInterval is how long a discovered icon is kept before it
+	// is fetched again.
+	faviconRefreshInterval = 7 * 24 * time.Hour
+	// faviconRetryInterval throttles discovery for sites without a usable icon.
+	faviconRetryInterval = 24 * time.Hour
+
+	maxFaviconBytes     = 512 << 10
+	maxFaviconPageBytes = 1 << 20
+)
+
+var errFaviconNotFound = errors.New("no usable favicon found")
+
+// Favicon is a fetched icon image.
+type Favicon struct {
+	URL      string
+	MimeType string
+	Data     []byte
+}
+
+// FetchFavicon discovers and downloads an icon for a site. Candidates are tried
+// in order: <link rel="icon"> declared by the site's HTML, /favicon.ico at the
+// site root, then the feed's own <image>.
+func FetchFavicon(ctx context.Context, siteURL, imageURL, proxy string, timeout time.Duration, allowPrivateFeeds bool) (*Favicon, error) {
+	client, err := httpc.NewClient(timeout, proxy, allowPrivateFeeds)
+	if err != nil {
+		return nil, fmt.Errorf("create client: %w", err)
+	}
+
+	candidates := []string{}
+	if site, err := url.Parse(strings.TrimSpace(siteURL)); err == nil && site.Host != "" && (site.Scheme == "http" || site.Scheme == "https") {
+		candidates = append(candidates, discoverIconLinks(ctx, client, site.String(), allowPrivateFeeds)...)
+		candidates = append(candidates, (&url.URL{Scheme: site.Scheme, Host: site.Host, Path: "/favicon.ico"}).String())
+	}
+	if imageURL = strings.TrimSpace(imageURL); imageURL != "" {
+		candidates = append(candidates, imageURL)
+	}
+
+	seen := make(map[string]struct{}, len(candidates))
+	lastErr := errFaviconNotFound
+	for _, candidate := range candidates {
+		if _, ok := seen[candidate]; ok {
+			continue
+		}
+		seen[candidate] = struct{}{}
+
+		icon, err := fetchIcon(ctx, client, candidate, allowPrivateFeeds)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return icon, nil
+	}
+
+	return nil, lastErr
+}
+
+// discoverIconLinks returns icon URLs declared in the page's <head>, with
+// rel="icon" links ahead of apple-touch-icon ones. Failures yield no links.
+func discoverIconLinks(ctx context.Context, client *http.Client, pageURL string, allowPrivateFeeds bool) []string {
+	if err := httpc.ValidateRequestURL(ctx, pageURL, allowPrivateFeeds); err != nil {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil
+	}
+	httpc.SetDefaultHeaders(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	return parseIconLinks(io.LimitReader(resp.Body, maxFaviconPageBytes), resp.Request.URL)
+}
+
+func parseIconLinks(r io.Reader, base *url.URL) []string {
+	var icons, touchIcons []string
+
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return append(icons, touchIcons...)
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Head {
+				return append(icons, touchIcons...)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				return append(icons, touchIcons...)
+			case atom.Link:
+			default:
+				continue
+			}
+
+			var rel, href string
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				switch string(key) {
+				case "rel":
+					rel = strings.ToLower(string(val))
+				case "href":
+					href = strings.TrimSpace(string(val))
+				}
+			}
+			if href == "" {
+				continue
+			}
+
+			resolved, err := base.Parse(href)
+			if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+				continue
+			}
+
+			for _, token := range strings.Fields(rel) {
+				if token == "icon" {
+					icons = append(icons, resolved.String())
+					break
+				}
+				if token == "apple-touch-icon" || token == "apple-touch-icon-precomposed" {
+					touchIcons = append(touchIcons, resolved.String())
+					break
+				}
+			}
+		}
+	}
+}
+
+func fetchIcon(ctx context.Context, client *http.Client, iconURL string, allowPrivateFeeds bool) (*Favicon, error) {
+	if err := httpc.ValidateRequestURL(ctx, iconURL, allowPrivateFeeds); err != nil {
+		return nil, fmt.Errorf("validate icon url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iconURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpc.SetDefaultHeaders(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch icon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch icon: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFaviconBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read icon: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty icon")
+	}
+	if len(data) > maxFaviconBytes {
+		return nil, fmt.Errorf("icon exceeds %d bytes", maxFaviconBytes)
+	}
+
+	mimeType := detectIconType(data, resp.Header.Get("Content-Type"))
+	if mimeType == "" {
+		return nil, fmt.Errorf("icon is not an image")
+	}
+
+	return &Favicon{URL: iconURL, MimeType: mimeType, Data: data}, nil
+}
+
+// detectIconType sniffs raster formats from the body. SVG cannot be sniffed,
+// so it is accepted only when the server declares it.
+func detectIconType(data []byte, declared string) string {
+	sniffed := http.DetectContentType(data)
+	if strings.HasPrefix(sniffed, "image/") {
+		return sniffed
+	}
+
+	declared = strings.ToLower(strings.TrimSpace(strings.Split(declared, ";")[0]))
+	if declared == "image/svg+xml" && strings.Contains(strings.ToLower(string(data)), "<svg") {
+		return declared
+	}
+
+	return ""
+}
+
+// refreshFavicon re-discovers the feed's icon when its cached copy is due.
+// Failures are recorded so unreachable sites are retried at a slower pace.
+func (p *Puller) refreshFavicon(ctx context.Context, feed *model.Feed, siteURL, imageURL string) {
+	now := time.Now()
+	refreshAfter, err := p.store.FeedIconRefreshAfter(feed.ID)
+	if err != nil {
+		p.logger.Warn("failed to read favicon state", "feed_id", feed.ID, "error", err)
+		return
+	}
+	if refreshAfter > now.Unix() {
+		return
+	}
+
+	if strings.TrimSpace(siteURL) == "" {
+		if link, err := url.Parse(feed.Link); err == nil && link.Host != "" {
+			siteURL = (&url.URL{Scheme: link.Scheme, Host: link.Host, Path: "/"}).String()
+		}
+	}
+
+	params := store.UpsertFeedIconParams{
+		FetchedAt:    now.Unix(),
+		RefreshAfter: now.Add(faviconRefreshInterval).Unix(),
+	}
+	icon, err := FetchFavicon(ctx, siteURL, imageURL, feed.Proxy, p.timeout, p.config.AllowPrivateFeeds)
+	if err != nil {
+		p.logger.Debug("favicon discovery failed", "feed_id", feed.ID, "site_url", siteURL, "error", err)
+		params.RefreshAfter = now.Add(faviconRetryInterval).Unix()
+	} else {
+		params.MimeType = icon.MimeType
+		params.Data = icon.Data
+		params.SourceURL = icon.URL
+	}
+
+	if err := p.store.UpsertFeedIcon(feed.ID, params); err != nil {
+		p.logger.Warn("failed to store favicon", "feed_id", feed.ID, "error", err)
+	}
+}
@@ -0,0 +1,148 @@
+package pull
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestFetchFaviconPrefersDeclaredIcon(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><head>
+<link rel="apple-touch-icon" href="/touch.png">
+<link rel="shortcut icon" href="/static/icon.png">
+</head><body><link rel="icon" href="/ignored.png"></body></html>`)
+	})
+	mux.HandleFunc("/static/icon.png", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testPNG)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	icon, err := FetchFavicon(context.Background(), server.URL, "", "", 5*time.Second, true)
+	if err != nil {
+		t.Fatalf("FetchFavicon() failed: %v", err)
+	}
+	if icon.URL != server.URL+"/static/icon.png" {
+		t.Errorf("expected declared icon, got %q", icon.URL)
+	}
+	if icon.MimeType != "image/png" || !bytes.Equal(icon.Data, testPNG) {
+		t.Errorf("unexpected icon: type=%q data=%q", icon.MimeType, icon.Data)
+	}
+}
+
+func TestFetchFaviconFallsBackToFaviconICOAndFeedImage(t *testing.T) {
+	ico := []byte{0x00, 0x00, 0x01, 0x00, 0x01, 0x00}
+	var serveICO bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><head><title>No icons</title></head></html>`)
+	})
+	mux.HandleFunc("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
+		if !serveICO {
+			// An HTML error page must not be accepted as an icon.
+			_, _ = fmt.Fprint(w, `<html>not found</html>`)
+			return
+		}
+		_, _ = w.Write(ico)
+	})
+	mux.HandleFunc("/feed-image.png", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testPNG)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	icon, err := FetchFavicon(context.Background(), server.URL+"/blog/", server.URL+"/feed-image.png", "", 5*time.Second, true)
+	if err != nil {
+		t.Fatalf("FetchFavicon() failed: %v", err)
+	}
+	if icon.URL != server.URL+"/feed-image.png" {
+		t.Errorf("expected feed image fallback, got %q", icon.URL)
+	}
+
+	serveICO = true
+	icon, err = FetchFavicon(context.Background(), server.URL+"/blog/", server.URL+"/feed-image.png", "", 5*time.Second, true)
+	if err != nil {
+		t.Fatalf("FetchFavicon() failed: %v", err)
+	}
+	if icon.URL != server.URL+"/favicon.ico" || icon.MimeType != "image/x-icon" {
+		t.Errorf("expected /favicon.ico, got %q (%s)", icon.URL, icon.MimeType)
+	}
+}
+
+func TestParseIconLinksResolvesRelativeHrefs(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post")
+	links := parseIconLinks(strings.NewReader(`<head>
+<link rel="stylesheet" href="/style.css">
+<link rel="ICON" href="favicon.svg">
+<link rel="icon" href="javascript:alert(1)">
+</head>`), base)
+
+	if len(links) != 1 || links[0] != "https://example.com/blog/favicon.svg" {
+		t.Fatalf("unexpected links: %v", links)
+	}
+}
+
+func TestRefreshFeedStoresFavicon(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Demo</title><link>%s/</link>
+<image><url>/logo.png</url></image>
+<item><guid>g1</guid><title>Item</title></item>
+</channel></rss>`, server.URL)
+	})
+	mux.HandleFunc("/logo.png", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testPNG)
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	feed, err := st.CreateFeed(1, "Feed", server.URL+"/feed.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	p := New(st, &config.Config{
+		PullInterval:      1800,
+		PullTimeout:       5,
+		PullConcurrency:   1,
+		PullMaxBackoff:    604800,
+		AllowPrivateFeeds: true,
+	}, nil)
+	if err := p.RefreshFeed(context.Background(), feed.ID); err != nil {
+		t.Fatalf("RefreshFeed() failed: %v", err)
+	}
+
+	icon, err := st.GetFeedIcon(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeedIcon() failed: %v", err)
+	}
+	if icon.SourceURL != server.URL+"/logo.png" {
+		t.Errorf("expected feed image as icon source, got %q", icon.SourceURL)
+	}
+	if icon.RefreshAfter <= time.Now().Unix() {
+		t.Errorf("expected refresh_after in the future, got %d", icon.RefreshAfter)
+	}
+}
@@ -0,0 +1,76 @@
+package pull
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/0x2E/fusion/internal/events"
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// recordFetch appends a finished pull attempt to the feed's fetch log.
+func (p *Puller) recordFetch(attempt *model.FeedFetchLog) {
+	if err := p.store.RecordFeedFetch(attempt); err != nil {
+		p.logger.Error("failed to record fetch attempt", "feed_id", attempt.FeedID, "error", err)
+	}
+}
+
+// finishPull records a pull attempt in the fetch log and announces its
+// outcome, and any new items, on the event bus.
+func (p *Puller) finishPull(feed *model.Feed, attempt *model.FeedFetchLog, notModified bool) {
+	p.recordFetch(attempt)
+
+	typ := events.PullSucceeded
+	switch {
+	case attempt.ErrorClass != "":
+		typ = events.PullFailed
+	case notModified:
+		typ = events.PullNotModified
+	}
+	p.events.Publish(events.Event{Type: typ, Data: events.Pull{
+		FeedID:     feed.ID,
+		FeedName:   feed.Name,
+		HTTPStatus: attempt.HTTPStatus,
+		NewItems:   attempt.NewItems,
+		ErrorClass: attempt.ErrorClass,
+		Error:      attempt.Error,
+	}})
+
+	if attempt.NewItems > 0 {
+		p.publishNewItems(feed.ID, int(attempt.NewItems), "pull")
+	}
+}
+
+// publishNewItems announces items stored for a feed, followed by the unread
+// counts they changed.
+func (p *Puller) publishNewItems(feedID int64, count int, source string) {
+	p.events.Publish(events.Event{Type: events.ItemsCreated, Data: events.Items{FeedID: feedID, Count: count, Source: source}})
+	if p.events.Subscribers() == 0 {
+		return
+	}
+
+	counts, err := p.store.ListUnreadCounts()
+	if err != nil {
+		p.logger.Warn("failed to list unread counts", "error", err)
+		return
+	}
+	p.events.Publish(events.Event{Type: events.UnreadCounts, Data: events.NewUnread(counts)})
+}
+
+// fetchErrorClass buckets a FetchAndParse failure for the fetch log.
+func fetchErrorClass(err error, result *FetchResult) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return model.FetchErrorTimeout
+	case result == nil:
+		// No response: DNS, connection, TLS or a blocked address.
+		return model.FetchErrorNetwork
+	case result.HTTPStatus != http.StatusOK:
+		return model.FetchErrorHTTP
+	default:
+		return model.FetchErrorParse
+	}
+}
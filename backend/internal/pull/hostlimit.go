@@ -0,0 +1,178 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// HostBlockedError reports a pull that was not started because the host asked
+// clients to back off with Retry-After.
+type HostBlockedError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *HostBlockedError) Error() string {
+	return fmt.Sprintf("host %s is rate limited until %s", e.Host, e.Until.UTC().Format(time.RFC3339))
+}
+
+type hostState struct {
+	inFlight  int
+	waiting   int
+	nextStart time.Time
+	blocked   time.Time
+	requests  int64
+	throttled int64
+	deferred  int64
+	lastStart time.Time
+	// released is closed and replaced whenever a slot is freed.
+	released chan struct{}
+}
+
+// hostLimiter caps concurrent requests per host, spaces their starts by a
+// minimum delay and blocks a host while its Retry-After lasts. A zero max or
+// delay disables that limit.
+type hostLimiter struct {
+	max   int
+	delay time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+func newHostLimiter(max int, delay time.Duration) *hostLimiter {
+	return &hostLimiter{max: max, delay: delay, hosts: make(map[string]*hostState)}
+}
+
+// feedHost is the limiter key of a feed link: its lower-cased host name.
+func feedHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+func (l *hostLimiter) state(host string) *hostState {
+	st, ok := l.hosts[host]
+	if !ok {
+		st = &hostState{released: make(chan struct{})}
+		l.hosts[host] = st
+	}
+	return st
+}
+
+// acquire waits for a request slot on host and returns its release func. It
+// fails with *HostBlockedError instead of waiting out a Retry-After block.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	st := l.state(host)
+	for {
+		now := time.Now()
+		if st.blocked.After(now) {
+			st.deferred++
+			l.mu.Unlock()
+			return nil, &HostBlockedError{Host: host, Until: st.blocked}
+		}
+
+		full := l.max > 0 && st.inFlight >= l.max
+		if !full && !st.nextStart.After(now) {
+			st.inFlight++
+			st.requests++
+			st.lastStart = now
+			st.nextStart = now.Add(l.delay)
+			l.mu.Unlock()
+			return func() { l.release(st) }, nil
+		}
+
+		// Wait for a free slot, or for the politeness delay to pass.
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if !full {
+			timer = time.NewTimer(st.nextStart.Sub(now))
+			expired = timer.C
+		}
+		released := st.released
+		st.waiting++
+		l.mu.Unlock()
+
+		var err error
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-released:
+		case <-expired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+
+		l.mu.Lock()
+		st.waiting--
+		if err != nil {
+			l.mu.Unlock()
+			return nil, err
+		}
+	}
+}
+
+func (l *hostLimiter) release(st *hostState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st.inFlight--
+	close(st.released)
+	st.released = make(chan struct{})
+}
+
+// block keeps new requests to host from starting before until.
+func (l *hostLimiter) block(host string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := l.state(host)
+	st.throttled++
+	if until.After(st.blocked) {
+		st.blocked = until
+	}
+}
+
+// status snapshots the per-host counters, busiest hosts first.
+func (l *hostLimiter) status() []model.PullHostStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	hosts := make([]model.PullHostStatus, 0, len(l.hosts))
+	for host, st := range l.hosts {
+		s := model.PullHostStatus{
+			Host:      host,
+			InFlight:  st.inFlight,
+			Waiting:   st.waiting,
+			Requests:  st.requests,
+			Throttled: st.throttled,
+			Deferred:  st.deferred,
+		}
+		if !st.lastStart.IsZero() {
+			s.LastRequestAt = st.lastStart.Unix()
+		}
+		if st.blocked.After(now) {
+			s.BlockedUntil = st.blocked.Unix()
+		}
+		hosts = append(hosts, s)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		if a, b := hosts[i].InFlight+hosts[i].Waiting, hosts[j].InFlight+hosts[j].Waiting; a != b {
+			return a > b
+		}
+		return hosts[i].Host < hosts[j].Host
+	})
+	return hosts
+}
@@ -0,0 +1,163 @@
+package pull
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+func TestHostLimiterCapsInFlightPerHost(t *testing.T) {
+	l := newHostLimiter(2, 0)
+	ctx := context.Background()
+
+	var running, peak int32
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.acquire(ctx, "example.com")
+			if err != nil {
+				t.Errorf("acquire: %v", err)
+				return
+			}
+			defer release()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+
+	// Another host is not held up by the busy one.
+	release, err := l.acquire(ctx, "other.example")
+	if err != nil {
+		t.Fatalf("acquire other host: %v", err)
+	}
+	release()
+	wg.Wait()
+
+	if peak > 2 {
+		t.Fatalf("peak in-flight = %d, want at most 2", peak)
+	}
+	status := l.status()
+	if len(status) != 2 || status[0].Host != "example.com" || status[0].Requests != 6 || status[0].InFlight != 0 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestHostLimiterSpacesRequestStarts(t *testing.T) {
+	l := newHostLimiter(0, 50*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		release, err := l.acquire(ctx, "example.com")
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("3 requests started within %v, want at least 100ms", elapsed)
+	}
+
+	// A cancelled wait gives up without taking the slot.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := l.acquire(cancelled, "example.com"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("acquire with cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestHostLimiterBlockFailsFast(t *testing.T) {
+	l := newHostLimiter(1, 0)
+	until := time.Now().Add(time.Hour)
+	l.block("example.com", until)
+
+	_, err := l.acquire(context.Background(), "example.com")
+	var blocked *HostBlockedError
+	if !errors.As(err, &blocked) || !blocked.Until.Equal(until) {
+		t.Fatalf("acquire on blocked host = %v, want HostBlockedError until %v", err, until)
+	}
+	status := l.status()
+	if len(status) != 1 || status[0].Throttled != 1 || status[0].Deferred != 1 || status[0].BlockedUntil != until.Unix() {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestRetryAfterBlocksWholeHost(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	var hitsB int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/b":
+			atomic.AddInt32(&hitsB, 1)
+			w.Header().Set("Content-Type", "application/rss+xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>T</title></channel></rss>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	feedA, err := st.CreateFeed(1, "A", server.URL+"/a", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	feedB, err := st.CreateFeed(1, "B", server.URL+"/b", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	p := New(st, &config.Config{PullInterval: 1800, PullTimeout: 5, PullConcurrency: 2, PullMaxBackoff: 604800, PullHostConcurrency: 2, AllowPrivateFeeds: true}, nil)
+	ctx := context.Background()
+	if err := p.RefreshFeed(ctx, feedA.ID); err != nil {
+		t.Fatalf("refresh feed A: %v", err)
+	}
+
+	var blocked *HostBlockedError
+	if err := p.RefreshFeed(ctx, feedB.ID); !errors.As(err, &blocked) {
+		t.Fatalf("refresh feed B = %v, want HostBlockedError", err)
+	}
+	if hits := atomic.LoadInt32(&hitsB); hits != 0 {
+		t.Fatalf("feed B fetched %d times while its host was rate limited", hits)
+	}
+
+	// The scheduler defers feed B to the end of the block instead of waiting.
+	p.pullScheduled(ctx, feedB.ID)
+	got, err := st.GetFeed(feedB.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if got.FetchState.NextCheckAt != blocked.Until.Unix() {
+		t.Fatalf("feed B next check at %d, want %d", got.FetchState.NextCheckAt, blocked.Until.Unix())
+	}
+
+	status := p.Status()
+	if len(status.Hosts) != 1 || status.Hosts[0].Throttled != 1 || status.Hosts[0].Deferred != 2 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
@@ -0,0 +1,113 @@
+package pull
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	jsonfeed "github.com/mmcdole/gofeed/json"
+)
+
+// jsonFeedTranslator applies JSON Feed 1.1 semantics on top of gofeed's
+// default mapping:
+//   - content_text is plain text and is escaped before use as HTML content;
+//   - summary stands in for missing content, also escaped;
+//   - external_url is the link when url is missing;
+//   - items without authors inherit the feed-level authors;
+//   - authors without a name (only url/avatar) are dropped;
+//   - the first attachment duration is exposed as itunes:duration.
+type jsonFeedTranslator struct {
+	gofeed.DefaultJSONTranslator
+}
+
+func (t *jsonFeedTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	jsonFeed, ok := feed.(*jsonfeed.Feed)
+	if !ok {
+		return nil, fmt.Errorf("unexpected json feed type %T", feed)
+	}
+
+	result, err := t.DefaultJSONTranslator.Translate(jsonFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Authors = namedPersons(result.Authors)
+	result.Author = firstPerson(result.Authors)
+
+	for i, item := range result.Items {
+		if i >= len(jsonFeed.Items) || jsonFeed.Items[i] == nil {
+			break
+		}
+		source := jsonFeed.Items[i]
+
+		switch {
+		case source.ContentHTML != "":
+			item.Content = source.ContentHTML
+		case source.ContentText != "":
+			item.Content = plainTextToHTML(source.ContentText)
+		case source.Summary != "":
+			item.Content = plainTextToHTML(source.Summary)
+		}
+
+		if item.Link == "" {
+			item.Link = source.ExternalURL
+		}
+
+		item.Authors = namedPersons(item.Authors)
+		if len(item.Authors) == 0 {
+			item.Authors = result.Authors
+		}
+		item.Author = firstPerson(item.Authors)
+
+		if item.ITunesExt == nil && source.Attachments != nil {
+			for _, attachment := range *source.Attachments {
+				if attachment.DurationInSeconds > 0 {
+					item.ITunesExt = &ext.ITunesItemExtension{Duration: strconv.FormatInt(attachment.DurationInSeconds, 10)}
+					break
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func namedPersons(persons []*gofeed.Person) []*gofeed.Person {
+	named := make([]*gofeed.Person, 0, len(persons))
+	for _, person := range persons {
+		if person != nil && strings.TrimSpace(person.Name) != "" {
+			named = append(named, person)
+		}
+	}
+	return named
+}
+
+func firstPerson(persons []*gofeed.Person) *gofeed.Person {
+	if len(persons) == 0 {
+		return nil
+	}
+	return persons[0]
+}
+
+// plainTextToHTML escapes text and keeps its paragraph and line breaks.
+func plainTextToHTML(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for paragraph := range strings.SplitSeq(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>"))
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
@@ -1,10 +1,12 @@
 package pull
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -18,16 +20,24 @@ import (
 
 // ParsedItem represents a feed item after parsing and field mapping.
 type ParsedItem struct {
-	GUID    string
-	Title   string
-	Link    string
-	Content string
-	PubDate int64
+	GUID       string
+	Title      string
+	Link       string
+	Content    string
+	Author     string
+	Summary    string
+	Categories []string
+	Enclosures []model.Enclosure
+	Duration   int64
+	Episode    int64
+	ImageURL   string
+	PubDate    int64
 }
 
 type FetchResult struct {
 	Items           []*ParsedItem
 	SiteURL         string
+	ImageURL        string
 	HTTPStatus      int
 	NotModified     bool
 	ETag            string
@@ -35,10 +45,19 @@ type FetchResult struct {
 	CacheControl    string
 	ExpiresAt       int64
 	RetryAfterUntil int64
+	// HubURL and SelfURL are WebSub links advertised by the response.
+	HubURL  string
+	SelfURL string
+	// PermanentURL is the final URL when the request reached it through 301/308
+	// redirects only; empty when it was not redirected or any hop was temporary.
+	PermanentURL string
+	// Bytes is the size of the response body read; 0 when it was not read.
+	Bytes int64
 }
 
-// FetchAndParse fetches RSS/Atom feed with conditional request headers.
-// It returns fetch metadata plus parsed items when response status is 200.
+// FetchAndParse fetches an RSS/Atom/JSON feed, or the page of a scraper feed,
+// with conditional request headers. It returns fetch metadata plus parsed
+// items when response status is 200.
 func FetchAndParse(ctx context.Context, feed *model.Feed, timeout time.Duration, allowPrivateFeeds bool) (*FetchResult, error) {
 	result := &FetchResult{}
 
@@ -56,9 +75,11 @@ func FetchAndParse(ctx context.Context, feed *model.Feed, timeout time.Duration,
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	httpc.SetDefaultHeaders(req)
+	httpc.SetFeedUserAgent(req, feed.UserAgent)
+	httpc.SetFeedAuth(req, feed.Auth)
 	setConditionalHeaders(req, feed)
 
-	resp, err := client.Do(req)
+	resp, err := httpc.AuthClient(client, feed.Auth).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("fetch feed: %w", err)
 	}
@@ -71,6 +92,7 @@ func FetchAndParse(ctx context.Context, feed *model.Feed, timeout time.Duration,
 	result.CacheControl = strings.TrimSpace(resp.Header.Get("Cache-Control"))
 	result.ExpiresAt = parseHTTPTime(resp.Header.Get("Expires"))
 	result.RetryAfterUntil = parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	result.PermanentURL = permanentRedirectURL(resp)
 
 	if resp.StatusCode == http.StatusNotModified {
 		result.NotModified = true
@@ -81,10 +103,42 @@ func FetchAndParse(ctx context.Context, feed *model.Feed, timeout time.Duration,
 		return result, fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
-	fp := gofeed.NewParser()
-	parsedFeed, err := fp.Parse(resp.Body)
+	body, err := io.ReadAll(resp.Body)
+	result.Bytes = int64(len(body))
+	if err != nil {
+		return result, fmt.Errorf("read feed: %w", err)
+	}
+
+	if feed.Kind == model.FeedKindScrape {
+		parsed, err := Scrape(bytes.NewReader(body), resp.Request.URL.String(), feed.Scrape)
+		if err != nil {
+			return result, err
+		}
+		result.Items = parsed.Items
+		result.SiteURL = parsed.SiteURL
+		return result, nil
+	}
+
+	parsed, err := ParseFeed(bytes.NewReader(body), feed)
+	if err != nil {
+		return result, err
+	}
+
+	result.Items = parsed.Items
+	result.SiteURL = parsed.SiteURL
+	result.ImageURL = parsed.ImageURL
+	result.HubURL, result.SelfURL = discoverWebSubLinks(resp.Header, body, feed.Link)
+	return result, nil
+}
+
+// ParseFeed parses a feed document and maps its items. Only Items, SiteURL and
+// ImageURL are set on the result. It is shared by pulls and WebSub pushes.
+func ParseFeed(r io.Reader, feed *model.Feed) (*FetchResult, error) {
+	result := &FetchResult{}
+
+	parsedFeed, err := newFeedParser().Parse(r)
 	if err != nil {
-		return result, fmt.Errorf("parse feed: %w", err)
+		return nil, fmt.Errorf("parse feed: %w", err)
 	}
 
 	siteURL := normalizeSiteURL(parsedFeed.Link)
@@ -104,9 +158,35 @@ func FetchAndParse(ctx context.Context, feed *model.Feed, timeout time.Duration,
 
 	result.Items = items
 	result.SiteURL = siteURL
+	if parsedFeed.Image != nil {
+		result.ImageURL = resolveFeedImageURL(parsedFeed.Image.URL, feed.Link)
+	}
 	return result, nil
 }
 
+func newFeedParser() *gofeed.Parser {
+	fp := gofeed.NewParser()
+	fp.JSONTranslator = &jsonFeedTranslator{}
+	return fp
+}
+
+// permanentRedirectURL walks the redirect chain of resp and returns the final
+// URL if every hop was a permanent redirect.
+func permanentRedirectURL(resp *http.Response) string {
+	req := resp.Request
+	if req == nil || req.Response == nil {
+		return ""
+	}
+	for r := req; r.Response != nil; r = r.Response.Request {
+		switch r.Response.StatusCode {
+		case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		default:
+			return ""
+		}
+	}
+	return req.URL.String()
+}
+
 func setConditionalHeaders(req *http.Request, feed *model.Feed) {
 	if req == nil || feed == nil {
 		return
@@ -182,13 +262,39 @@ func normalizeSiteURL(raw string) string {
 	return parsed.String()
 }
 
+// resolveFeedImageURL resolves the feed-level <image> URL against the feed link.
+func resolveFeedImageURL(raw, feedLink string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	base, err := url.Parse(feedLink)
+	if err != nil {
+		return ""
+	}
+	resolved, err := base.Parse(raw)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
+	}
+
+	return resolved.String()
+}
+
 // mapItem converts gofeed.Item to ParsedItem following mapping rules:
 // - guid: prefer GUID, fallback to Link
 // - content: prefer Content, fallback to Description
+// - summary: Description when Content is present (otherwise it is the content)
+// - author: first named author, fallback to itunes:author
+// - duration/episode/image: iTunes extension, image fallback to the item image
 // - pub_date: prefer PublishedParsed, fallback to UpdatedParsed
-// - link: convert to absolute URL
+// - link, enclosure URLs: convert to absolute URL
 func mapItem(item *gofeed.Item, baseURL *url.URL) *ParsedItem {
 	content := item.Content
+	summary := strings.TrimSpace(item.Description)
+	if content == "" || summary == strings.TrimSpace(content) {
+		summary = ""
+	}
 	if content == "" {
 		content = item.Description
 	}
@@ -224,13 +330,137 @@ func mapItem(item *gofeed.Item, baseURL *url.URL) *ParsedItem {
 		guid = fallbackGUID(item.Title, content, sourcePubDate, hasSourcePubDate)
 	}
 
-	return &ParsedItem{
-		GUID:    guid,
-		Title:   item.Title,
-		Link:    link,
-		Content: content,
-		PubDate: pubDate,
+	parsed := &ParsedItem{
+		GUID:       guid,
+		Title:      item.Title,
+		Link:       link,
+		Content:    content,
+		Author:     itemAuthor(item),
+		Summary:    summary,
+		Categories: itemCategories(item.Categories),
+		Enclosures: itemEnclosures(item.Enclosures, baseURL),
+		PubDate:    pubDate,
+	}
+
+	imageURL := ""
+	if item.ITunesExt != nil {
+		parsed.Duration = parseITunesDuration(item.ITunesExt.Duration)
+		parsed.Episode, _ = strconv.ParseInt(strings.TrimSpace(item.ITunesExt.Episode), 10, 64)
+		parsed.Episode = max(parsed.Episode, 0)
+		imageURL = item.ITunesExt.Image
+		if parsed.Author == "" {
+			parsed.Author = strings.TrimSpace(item.ITunesExt.Author)
+		}
+	}
+	if imageURL == "" && item.Image != nil {
+		imageURL = item.Image.URL
+	}
+	parsed.ImageURL = resolveHTTPURL(imageURL, baseURL)
+
+	return parsed
+}
+
+// parseITunesDuration converts itunes:duration ("SS", "MM:SS" or "HH:MM:SS",
+// optionally with fractional seconds) to seconds. Invalid values yield 0.
+func parseITunesDuration(raw string) int64 {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+
+	parts := strings.Split(raw, ":")
+	if len(parts) > 3 {
+		return 0
+	}
+	parts[len(parts)-1], _, _ = strings.Cut(parts[len(parts)-1], ".")
+
+	var seconds int64
+	for _, part := range parts {
+		value, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || value < 0 {
+			return 0
+		}
+		seconds = seconds*60 + value
+	}
+	return seconds
+}
+
+// resolveHTTPURL resolves raw against baseURL and keeps only http(s) results.
+func resolveHTTPURL(raw string, baseURL *url.URL) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	resolved, err := url.Parse(raw)
+	if baseURL != nil {
+		resolved, err = baseURL.Parse(raw)
+	}
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
+	}
+	return resolved.String()
+}
+
+func itemAuthor(item *gofeed.Item) string {
+	if item.Author != nil {
+		if name := strings.TrimSpace(item.Author.Name); name != "" {
+			return name
+		}
+	}
+	for _, author := range item.Authors {
+		if author != nil {
+			if name := strings.TrimSpace(author.Name); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// itemCategories trims categories and drops empty and duplicate values.
+func itemCategories(raw []string) []string {
+	categories := make([]string, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, category := range raw {
+		category = strings.TrimSpace(category)
+		if category == "" {
+			continue
+		}
+		if _, ok := seen[category]; ok {
+			continue
+		}
+		seen[category] = struct{}{}
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// itemEnclosures keeps http(s) enclosures with absolute URLs, deduplicated by URL.
+func itemEnclosures(raw []*gofeed.Enclosure, baseURL *url.URL) []model.Enclosure {
+	enclosures := make([]model.Enclosure, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, enclosure := range raw {
+		if enclosure == nil {
+			continue
+		}
+		link := resolveHTTPURL(enclosure.URL, baseURL)
+		if link == "" {
+			continue
+		}
+		if _, ok := seen[link]; ok {
+			continue
+		}
+		seen[link] = struct{}{}
+
+		length, _ := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64)
+		enclosures = append(enclosures, model.Enclosure{
+			URL:    link,
+			Type:   strings.TrimSpace(enclosure.Type),
+			Length: max(length, 0),
+		})
 	}
+	return enclosures
 }
 
 func fallbackGUID(title, content string, sourcePubDate int64, hasSourcePubDate bool) string {
@@ -90,6 +90,63 @@ func TestFetchAndParseParsesCacheMetadata(t *testing.T) {
 	}
 }
 
+func TestFetchAndParseReportsPermanentRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved-again", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved-again", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/feed", http.StatusPermanentRedirect)
+	})
+	mux.HandleFunc("/temporary", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusFound)
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	for path, want := range map[string]string{
+		"/moved":     server.URL + "/feed",
+		"/temporary": "",
+		"/feed":      "",
+	} {
+		result, err := FetchAndParse(context.Background(), &model.Feed{Link: server.URL + path}, 5*time.Second, true)
+		if err != nil {
+			t.Fatalf("%s: FetchAndParse() failed: %v", path, err)
+		}
+		if result.PermanentURL != want {
+			t.Errorf("%s: expected PermanentURL %q, got %q", path, want, result.PermanentURL)
+		}
+	}
+}
+
+func TestFetchAndParseSendsFeedAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "secret" || r.Header.Get("X-Api-Key") != "k1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	feed := &model.Feed{Link: server.URL}
+	if _, err := FetchAndParse(context.Background(), feed, 5*time.Second, true); err == nil {
+		t.Fatal("expected HTTP 401 without credentials")
+	}
+
+	feed.Auth = &model.FeedAuth{Username: "alice", Password: "secret", Headers: map[string]string{"X-Api-Key": "k1"}}
+	result, err := FetchAndParse(context.Background(), feed, 5*time.Second, true)
+	if err != nil {
+		t.Fatalf("FetchAndParse() failed: %v", err)
+	}
+	if !result.NotModified {
+		t.Fatalf("expected 304 with credentials, got %d", result.HTTPStatus)
+	}
+}
+
 func TestMapItemFallbackGUIDWhenMissingGUIDAndLink(t *testing.T) {
 	now := time.Now().UTC().Truncate(time.Second)
 	item := &gofeed.Item{
@@ -148,6 +205,116 @@ func TestMapItemDoesNotUseBaseURLWhenLinkIsMissing(t *testing.T) {
 	}
 }
 
+func TestMapItemMapsMetadata(t *testing.T) {
+	baseURL, err := url.Parse("https://example.com/blog/")
+	if err != nil {
+		t.Fatalf("parse base URL: %v", err)
+	}
+
+	item := &gofeed.Item{
+		GUID:        "g",
+		Content:     "<p>Full</p>",
+		Description: "Short",
+		Authors:     []*gofeed.Person{{Name: " "}, {Name: "Second Author"}},
+		Categories:  []string{" go ", "", "go", "feeds"},
+		Enclosures: []*gofeed.Enclosure{
+			{URL: "media/ep.mp3", Type: "audio/mpeg", Length: "2048"},
+			{URL: "https://example.com/blog/media/ep.mp3", Type: "audio/mpeg"},
+			{URL: "ftp://example.com/file.zip"},
+			{URL: "https://cdn.example.com/v.mp4", Type: "video/mp4", Length: "unknown"},
+		},
+	}
+
+	parsed := mapItem(item, baseURL)
+
+	if parsed.Author != "Second Author" {
+		t.Errorf("expected first named author, got %q", parsed.Author)
+	}
+	if parsed.Summary != "Short" {
+		t.Errorf("expected description as summary, got %q", parsed.Summary)
+	}
+	if len(parsed.Categories) != 2 || parsed.Categories[0] != "go" || parsed.Categories[1] != "feeds" {
+		t.Errorf("unexpected categories: %v", parsed.Categories)
+	}
+	want := []model.Enclosure{
+		{URL: "https://example.com/blog/media/ep.mp3", Type: "audio/mpeg", Length: 2048},
+		{URL: "https://cdn.example.com/v.mp4", Type: "video/mp4"},
+	}
+	if len(parsed.Enclosures) != len(want) {
+		t.Fatalf("expected %d enclosures, got %+v", len(want), parsed.Enclosures)
+	}
+	for i := range want {
+		if parsed.Enclosures[i] != want[i] {
+			t.Errorf("enclosure %d = %+v, want %+v", i, parsed.Enclosures[i], want[i])
+		}
+	}
+
+	// Description used as content is not repeated as summary.
+	if bare := mapItem(&gofeed.Item{GUID: "b", Description: "Only"}, baseURL); bare.Content != "Only" || bare.Summary != "" {
+		t.Errorf("unexpected content/summary: %q / %q", bare.Content, bare.Summary)
+	}
+}
+
+func TestParseFeedMapsPodcastMetadata(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+<title>Podcast</title><link>https://example.com/</link>
+<item>
+  <guid>ep-12</guid><title>Episode 12</title>
+  <enclosure url="/media/ep12.mp3" length="5000" type="audio/mpeg"/>
+  <itunes:author>Host Name</itunes:author>
+  <itunes:duration>1:02:03</itunes:duration>
+  <itunes:episode>12</itunes:episode>
+  <itunes:image href="/art/ep12.jpg"/>
+</item>
+</channel></rss>`
+
+	result, err := ParseFeed(strings.NewReader(doc), &model.Feed{Link: "https://example.com/feed.xml"})
+	if err != nil {
+		t.Fatalf("ParseFeed() failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(result.Items))
+	}
+	item := result.Items[0]
+	if item.Duration != 3723 || item.Episode != 12 || item.Author != "Host Name" {
+		t.Errorf("unexpected podcast fields: duration=%d episode=%d author=%q", item.Duration, item.Episode, item.Author)
+	}
+	if item.ImageURL != "https://example.com/art/ep12.jpg" {
+		t.Errorf("unexpected image URL %q", item.ImageURL)
+	}
+	if len(item.Enclosures) != 1 || item.Enclosures[0].URL != "https://example.com/media/ep12.mp3" {
+		t.Errorf("unexpected enclosures: %+v", item.Enclosures)
+	}
+
+	// JSON Feed attachments carry the duration directly.
+	jsonResult, err := ParseFeed(strings.NewReader(jsonFeed11), &model.Feed{Link: "https://example.com/feed.json"})
+	if err != nil {
+		t.Fatalf("ParseFeed(json) failed: %v", err)
+	}
+	if jsonResult.Items[0].Duration != 60 {
+		t.Errorf("expected attachment duration 60, got %d", jsonResult.Items[0].Duration)
+	}
+}
+
+func TestParseITunesDuration(t *testing.T) {
+	tests := map[string]int64{
+		"":          0,
+		"90":        90,
+		"05:30":     330,
+		"1:02:03":   3723,
+		"00:10.500": 10,
+		"1:2:3:4":   0,
+		"abc":       0,
+		"-5":        0,
+	}
+	for raw, want := range tests {
+		if got := parseITunesDuration(raw); got != want {
+			t.Errorf("parseITunesDuration(%q) = %d, want %d", raw, got, want)
+		}
+	}
+}
+
 func TestFallbackGUIDIgnoresSyntheticPubDate(t *testing.T) {
 	g1 := fallbackGUID("same title", "same content", 1700000000, false)
 	g2 := fallbackGUID("same title", "same content", 1800000000, false)
@@ -165,3 +332,166 @@ func TestFallbackGUIDUsesSourcePubDateWhenProvided(t *testing.T) {
 		t.Fatalf("expected different GUID when source pub date differs, got %q", g1)
 	}
 }
+
+const jsonFeed11 = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "JSON Blog",
+  "home_page_url": "https://example.com/",
+  "icon": "/icon.png",
+  "authors": [{"name": "Feed Author"}, {"url": "https://example.com/nameless"}],
+  "items": [
+    {
+      "id": "1",
+      "url": "/posts/1",
+      "title": "HTML post",
+      "content_html": "<p>Hello <b>world</b></p>",
+      "content_text": "Hello world",
+      "summary": "Greeting",
+      "date_published": "2026-01-02T03:04:05Z",
+      "date_modified": "2026-01-03T03:04:05Z",
+      "authors": [{"name": "Item Author"}],
+      "tags": ["go", "feeds"],
+      "attachments": [
+        {"url": "https://example.com/ep1.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 1234, "duration_in_seconds": 60}
+      ]
+    },
+    {
+      "id": 2,
+      "external_url": "https://other.example.com/article",
+      "content_text": "a < b & c\nnext line\n\nsecond paragraph",
+      "date_modified": "2026-01-04T05:06:07+02:00"
+    },
+    {
+      "id": "3",
+      "summary": "Summary only"
+    }
+  ]
+}`
+
+func TestParseFeedJSONFeed11(t *testing.T) {
+	result, err := ParseFeed(strings.NewReader(jsonFeed11), &model.Feed{Link: "https://example.com/feed.json"})
+	if err != nil {
+		t.Fatalf("ParseFeed() failed: %v", err)
+	}
+
+	if result.SiteURL != "https://example.com/" || result.ImageURL != "https://example.com/icon.png" {
+		t.Fatalf("unexpected feed metadata: site=%q image=%q", result.SiteURL, result.ImageURL)
+	}
+	if len(result.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(result.Items))
+	}
+
+	first := result.Items[0]
+	if first.GUID != "1" || first.Link != "https://example.com/posts/1" {
+		t.Errorf("unexpected first item identity: guid=%q link=%q", first.GUID, first.Link)
+	}
+	if first.Content != "<p>Hello <b>world</b></p>" {
+		t.Errorf("expected content_html to win, got %q", first.Content)
+	}
+	if first.Author != "Item Author" || first.Summary != "Greeting" || len(first.Categories) != 2 {
+		t.Errorf("unexpected first item metadata: author=%q summary=%q categories=%v", first.Author, first.Summary, first.Categories)
+	}
+	if len(first.Enclosures) != 1 || first.Enclosures[0].Length != 1234 || first.Enclosures[0].Type != "audio/mpeg" {
+		t.Errorf("unexpected enclosures: %+v", first.Enclosures)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Unix(); first.PubDate != want {
+		t.Errorf("expected date_published, got %d want %d", first.PubDate, want)
+	}
+
+	second := result.Items[1]
+	if second.Author != "Feed Author" {
+		t.Errorf("expected inherited feed author, got %q", second.Author)
+	}
+	if second.GUID != "2" {
+		t.Errorf("expected numeric id coerced to string, got %q", second.GUID)
+	}
+	if second.Link != "https://other.example.com/article" {
+		t.Errorf("expected external_url fallback, got %q", second.Link)
+	}
+	if want := "<p>a &lt; b &amp; c<br>next line</p><p>second paragraph</p>"; second.Content != want {
+		t.Errorf("expected escaped content_text, got %q want %q", second.Content, want)
+	}
+	if want := time.Date(2026, 1, 4, 3, 6, 7, 0, time.UTC).Unix(); second.PubDate != want {
+		t.Errorf("expected date_modified fallback, got %d want %d", second.PubDate, want)
+	}
+
+	if third := result.Items[2]; third.Content != "<p>Summary only</p>" {
+		t.Errorf("expected summary fallback, got %q", third.Content)
+	}
+}
+
+func TestJSONFeedTranslatorMapsAuthorsAndAttachments(t *testing.T) {
+	parsed, err := newFeedParser().Parse(strings.NewReader(jsonFeed11))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	if parsed.Author == nil || parsed.Author.Name != "Feed Author" || len(parsed.Authors) != 1 {
+		t.Fatalf("expected nameless feed authors to be dropped, got %+v", parsed.Authors)
+	}
+
+	first := parsed.Items[0]
+	if first.Author == nil || first.Author.Name != "Item Author" {
+		t.Errorf("expected item author, got %+v", first.Author)
+	}
+	if len(first.Categories) != 2 || first.Categories[0] != "go" {
+		t.Errorf("expected tags as categories, got %v", first.Categories)
+	}
+	if first.Description != "Greeting" {
+		t.Errorf("expected summary as description, got %q", first.Description)
+	}
+	if len(first.Enclosures) != 1 {
+		t.Fatalf("expected 1 enclosure, got %d", len(first.Enclosures))
+	}
+	if enc := first.Enclosures[0]; enc.URL != "https://example.com/ep1.mp3" || enc.Type != "audio/mpeg" || enc.Length != "1234" {
+		t.Errorf("unexpected enclosure: %+v", enc)
+	}
+
+	if second := parsed.Items[1]; second.Author == nil || second.Author.Name != "Feed Author" {
+		t.Errorf("expected item to inherit feed author, got %+v", second.Author)
+	}
+}
+
+func TestParseFeedHandlesRDFAndRSS09x(t *testing.T) {
+	feed := &model.Feed{Link: "https://example.com/feed"}
+
+	rdf := `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel rdf:about="https://example.com/rss"><title>RDF</title><link>https://example.com/</link></channel>
+<item rdf:about="https://example.com/a"><title>A</title><link>/a</link><description>Body</description><dc:date>2026-01-02T03:04:05Z</dc:date></item>
+</rdf:RDF>`
+	result, err := ParseFeed(strings.NewReader(rdf), feed)
+	if err != nil {
+		t.Fatalf("ParseFeed(rdf) failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("expected 1 RDF item, got %d", len(result.Items))
+	}
+	item := result.Items[0]
+	if item.Link != "https://example.com/a" || item.Content != "Body" {
+		t.Errorf("unexpected RDF item: %+v", item)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Unix(); item.PubDate != want {
+		t.Errorf("expected dc:date as pub date, got %d want %d", item.PubDate, want)
+	}
+
+	// RSS 0.91/0.92 items have no guid or pubDate, and 0.92 makes title optional.
+	rss092 := `<rss version="0.92"><channel><title>Old</title><link>https://example.com/</link>
+<item><title>Linked</title><link>https://example.com/t</link><description>D</description></item>
+<item><description>Description only</description></item>
+</channel></rss>`
+	before := time.Now().Unix()
+	result, err = ParseFeed(strings.NewReader(rss092), feed)
+	if err != nil {
+		t.Fatalf("ParseFeed(rss 0.92) failed: %v", err)
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("expected 2 RSS 0.92 items, got %d", len(result.Items))
+	}
+	if linked := result.Items[0]; linked.GUID != "https://example.com/t" || linked.PubDate < before {
+		t.Errorf("expected link GUID and fetch-time pub date, got %+v", linked)
+	}
+	if bare := result.Items[1]; !strings.HasPrefix(bare.GUID, "generated:") || bare.Content != "Description only" {
+		t.Errorf("expected generated GUID for bare item, got %+v", bare)
+	}
+}
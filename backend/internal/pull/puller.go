@@ -2,19 +2,29 @@ package pull
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/events"
 	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/sanitize"
 	"github.com/0x2E/fusion/internal/pullpolicy"
 	"github.com/0x2E/fusion/internal/store"
+	"github.com/0x2E/fusion/internal/websub"
 	"golang.org/x/sync/semaphore"
 )
 
+// publishHistorySize is the number of newest items used to estimate how often
+// a feed publishes.
+const publishHistorySize = 20
+
 type Puller struct {
 	store       *store.Store
 	config      *config.Config
@@ -23,10 +33,20 @@ type Puller struct {
 	timeout     time.Duration
 	maxBackoff  time.Duration
 	concurrency *semaphore.Weighted
+	hosts       *hostLimiter
+	events      *events.Bus
+	websub      *websub.Subscriber // nil when FUSION_PUBLIC_URL is unset
+
+	// Scheduler wakeups: feed ID -> whether a scheduled pull of it finished.
+	wakeMu  sync.Mutex
+	wakeups map[int64]bool
+	wake    chan struct{}
 }
 
-func New(st *store.Store, cfg *config.Config) *Puller {
-	return &Puller{
+// New creates a puller. sub subscribes feeds that advertise a WebSub hub; it
+// is nil when FUSION_PUBLIC_URL is unset.
+func New(st *store.Store, cfg *config.Config, sub *websub.Subscriber) *Puller {
+	p := &Puller{
 		store:       st,
 		config:      cfg,
 		logger:      slog.Default(),
@@ -34,59 +54,122 @@ func New(st *store.Store, cfg *config.Config) *Puller {
 		timeout:     time.Duration(cfg.PullTimeout) * time.Second,
 		maxBackoff:  time.Duration(cfg.PullMaxBackoff) * time.Second,
 		concurrency: semaphore.NewWeighted(int64(cfg.PullConcurrency)),
+		hosts:       newHostLimiter(cfg.PullHostConcurrency, time.Duration(cfg.PullHostDelay)*time.Second),
+		events:      events.NewBus(),
+		wakeups:     make(map[int64]bool),
+		wake:        make(chan struct{}, 1),
+		websub:      sub,
 	}
-}
 
-// Start begins periodic feed pulling. Blocks until context is cancelled.
-func (p *Puller) Start(ctx context.Context) error {
-	p.logger.Info("pull service started", "interval", p.interval, "timeout", p.timeout, "concurrency", p.config.PullConcurrency)
+	return p
+}
 
-	// Run immediately on startup
-	p.pullAll(ctx)
+// scheduleFor resolves the feed's schedule overrides against the global
+// defaults. Max backoff never drops below the interval, otherwise the cap in
+// ComputeNextCheckAt would shorten a long per-feed interval.
+func (p *Puller) scheduleFor(feed *model.Feed) (interval, maxBackoff time.Duration) {
+	interval = p.interval
+	if feed.PullInterval > 0 {
+		interval = time.Duration(feed.PullInterval) * time.Second
+	} else if p.config.PullAdaptive && feed.FetchState.AdaptiveInterval > 0 {
+		interval = time.Duration(feed.FetchState.AdaptiveInterval) * time.Second
+	}
 
-	ticker := time.NewTicker(p.interval)
-	defer ticker.Stop()
+	if feed.WebSubActive {
+		interval = max(interval, webSubPollInterval)
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			p.logger.Info("pull service stopping")
-			return ctx.Err()
-		case <-ticker.C:
-			p.pullAll(ctx)
-		}
+	maxBackoff = p.maxBackoff
+	if feed.MaxBackoff > 0 {
+		maxBackoff = time.Duration(feed.MaxBackoff) * time.Second
 	}
+	if maxBackoff < interval {
+		maxBackoff = interval
+	}
+
+	return interval, maxBackoff
 }
 
-// pullAll fetches all feeds concurrently with semaphore limiting.
-func (p *Puller) pullAll(ctx context.Context) {
-	feeds, err := p.store.ListFeeds()
+// cadence is the schedule applied after a successful check.
+type cadence struct {
+	interval         time.Duration
+	maxBackoff       time.Duration
+	adaptiveInterval int64
+	publishInterval  int64
+	notModifiedRatio float64
+}
+
+// successCadence resolves the schedule after a successful check. With adaptive
+// polling enabled and no per-feed interval override, the interval is derived
+// from the feed's publish history and unchanged-response ratio.
+func (p *Puller) successCadence(feed *model.Feed, now int64, unchanged bool) cadence {
+	interval, maxBackoff := p.scheduleFor(feed)
+	c := cadence{
+		interval:         interval,
+		maxBackoff:       maxBackoff,
+		notModifiedRatio: pullpolicy.UpdateNotModifiedRatio(feed.FetchState.NotModifiedRatio, unchanged),
+	}
+	if !p.config.PullAdaptive || feed.PullInterval > 0 {
+		return c
+	}
+
+	publishedAt, err := p.store.ListFeedPublishTimes(feed.ID, publishHistorySize)
 	if err != nil {
-		p.logger.Error("failed to list feeds", "error", err)
-		return
+		p.logger.Warn("failed to load publish history", "feed_id", feed.ID, "error", err)
+		return c
 	}
 
-	now := time.Now().Unix()
-	_, _ = p.dispatchFeeds(ctx, feeds, func(feed *model.Feed) bool {
-		state := pullpolicy.FeedRuntimeState{
-			Suspended:           feed.Suspended,
-			RetryAfterUntil:     feed.FetchState.RetryAfterUntil,
-			NextCheckAt:         feed.FetchState.NextCheckAt,
-			ConsecutiveFailures: feed.FetchState.ConsecutiveFailures,
-			LastErrorAt:         feed.FetchState.LastErrorAt,
-			LastCheckedAt:       feed.FetchState.LastCheckedAt,
-		}
-		return !pullpolicy.ShouldSkip(now, state, p.interval, p.maxBackoff)
-	})
+	lastPublishedAt := int64(0)
+	if len(publishedAt) > 0 {
+		lastPublishedAt = publishedAt[0]
+	}
+	c.publishInterval = pullpolicy.PublishIntervalSeconds(publishedAt)
+	c.adaptiveInterval = pullpolicy.AdaptiveIntervalSeconds(
+		now,
+		c.publishInterval,
+		lastPublishedAt,
+		c.notModifiedRatio,
+		int64(p.interval.Seconds()),
+		int64(p.config.PullMinInterval),
+		int64(p.config.PullMaxInterval),
+	)
+	c.interval = time.Duration(c.adaptiveInterval) * time.Second
+	if feed.WebSubActive {
+		c.interval = max(c.interval, webSubPollInterval)
+	}
+	if c.maxBackoff < c.interval {
+		c.maxBackoff = c.interval
+	}
+
+	return c
 }
 
-// pullFeed fetches single feed and saves new items.
-func (p *Puller) pullFeed(ctx context.Context, feed *model.Feed) {
+// pullFeed fetches single feed and saves new items, returning the error of a
+// failed attempt. Every attempt is recorded in the feed's fetch log and
+// announced on the event bus.
+func (p *Puller) pullFeed(ctx context.Context, feed *model.Feed) (pullErr error) {
 	p.logger.Debug("pulling feed", "feed_id", feed.ID, "feed_name", feed.Name)
+	p.events.Publish(events.Event{Type: events.PullStarted, Data: events.Pull{FeedID: feed.ID, FeedName: feed.Name}})
 
+	start := time.Now()
 	result, err := FetchAndParse(ctx, feed, p.timeout, p.config.AllowPrivateFeeds)
 	checkedAt := time.Now().Unix()
+
+	attempt := &model.FeedFetchLog{FeedID: feed.ID, FetchedAt: checkedAt, DurationMs: time.Since(start).Milliseconds()}
+	if result != nil {
+		attempt.HTTPStatus = result.HTTPStatus
+		attempt.Bytes = result.Bytes
+	}
+	notModified := false
+	defer func() {
+		p.finishPull(feed, attempt, notModified)
+		if attempt.ErrorClass != "" {
+			pullErr = errors.New(attempt.Error)
+		}
+	}()
+
 	if err != nil {
+		attempt.ErrorClass, attempt.Error = fetchErrorClass(err, result), err.Error()
 		httpStatus := 0
 		retryAfterUntil := int64(0)
 		if result != nil {
@@ -94,17 +177,27 @@ func (p *Puller) pullFeed(ctx context.Context, feed *model.Feed) {
 			retryAfterUntil = result.RetryAfterUntil
 		}
 
+		// Retry-After speaks for the whole host, not just this feed.
+		if (httpStatus == http.StatusTooManyRequests || httpStatus == http.StatusServiceUnavailable) && retryAfterUntil > checkedAt {
+			p.hosts.block(feedHost(feed.Link), time.Unix(retryAfterUntil, 0))
+		}
+
+		interval, maxBackoff := p.scheduleFor(feed)
 		if err := p.store.UpdateFeedFetchFailure(feed.ID, store.UpdateFeedFetchFailureParams{
 			CheckedAt:       checkedAt,
 			HTTPStatus:      httpStatus,
 			LastError:       err.Error(),
 			RetryAfterUntil: retryAfterUntil,
-			IntervalSeconds: int64(p.interval.Seconds()),
-			MaxBackoff:      int64(p.maxBackoff.Seconds()),
+			IntervalSeconds: int64(interval.Seconds()),
+			MaxBackoff:      int64(maxBackoff.Seconds()),
 		}); err != nil {
 			p.logger.Error("failed to record failure", "feed_id", feed.ID, "error", err)
 		}
 
+		if httpStatus == http.StatusGone {
+			p.suspendGone(feed)
+		}
+
 		p.logger.Warn("failed to fetch feed", "feed_id", feed.ID, "feed_name", feed.Name, "status", httpStatus, "error", err)
 		return
 	}
@@ -132,10 +225,11 @@ func (p *Puller) pullFeed(ctx context.Context, feed *model.Feed) {
 			expiresAt = feed.FetchState.ExpiresAt
 		}
 
+		schedule := p.successCadence(feed, checkedAt, true)
 		nextCheckAt := pullpolicy.ComputeNextCheckAt(
 			checkedAt,
-			p.interval,
-			p.maxBackoff,
+			schedule.interval,
+			schedule.maxBackoff,
 			0,
 			result.RetryAfterUntil,
 			cacheControl,
@@ -143,132 +237,228 @@ func (p *Puller) pullFeed(ctx context.Context, feed *model.Feed) {
 		)
 
 		if err := p.store.UpdateFeedFetchSuccess(feed.ID, store.UpdateFeedFetchSuccessParams{
-			CheckedAt:       checkedAt,
-			HTTPStatus:      result.HTTPStatus,
-			ETag:            etag,
-			LastModified:    lastModified,
-			CacheControl:    cacheControl,
-			ExpiresAt:       expiresAt,
-			RetryAfterUntil: result.RetryAfterUntil,
-			NextCheckAt:     nextCheckAt,
+			CheckedAt:        checkedAt,
+			HTTPStatus:       result.HTTPStatus,
+			ETag:             etag,
+			LastModified:     lastModified,
+			CacheControl:     cacheControl,
+			ExpiresAt:        expiresAt,
+			RetryAfterUntil:  result.RetryAfterUntil,
+			NextCheckAt:      nextCheckAt,
+			AdaptiveInterval: schedule.adaptiveInterval,
+			PublishInterval:  schedule.publishInterval,
+			NotModifiedRatio: schedule.notModifiedRatio,
 		}); err != nil {
 			p.logger.Error("failed to persist not-modified state", "feed_id", feed.ID, "error", err)
+			attempt.ErrorClass, attempt.Error = model.FetchErrorInternal, err.Error()
 			return
 		}
 
+		notModified = true
+		p.trackRedirect(feed, result.PermanentURL)
+
+		p.refreshFavicon(ctx, feed, feed.SiteURL, "")
+
 		p.logger.Debug("feed not modified", "feed_id", feed.ID, "feed_name", feed.Name)
 		return
 	}
 
+	inputs, err := p.ingestInputs(feed, result.Items)
+	if err != nil {
+		p.logger.Error("failed to apply ingest rules", "feed_id", feed.ID, "error", err)
+		attempt.ErrorClass, attempt.Error = model.FetchErrorInternal, err.Error()
+		return
+	}
+	upserted, err := p.store.BatchUpsertItems(feed.ID, feed.UpdateMode, inputs)
+	if err != nil {
+		p.logger.Error("failed to batch create items", "feed_id", feed.ID, "error", err)
+		attempt.ErrorClass, attempt.Error = model.FetchErrorInternal, err.Error()
+		return
+	}
+	newCount := upserted.Created
+	attempt.NewItems = int64(newCount)
+
+	// Computed after inserting so the new items count toward publish history.
+	schedule := p.successCadence(feed, checkedAt, newCount == 0)
 	nextCheckAt := pullpolicy.ComputeNextCheckAt(
 		checkedAt,
-		p.interval,
-		p.maxBackoff,
+		schedule.interval,
+		schedule.maxBackoff,
 		0,
 		result.RetryAfterUntil,
 		result.CacheControl,
 		result.ExpiresAt,
 	)
 
-	inputs := make([]store.BatchCreateItemInput, 0, len(result.Items))
-	for _, item := range result.Items {
-		inputs = append(inputs, store.BatchCreateItemInput{
-			GUID:    item.GUID,
-			Title:   item.Title,
-			Link:    item.Link,
-			Content: item.Content,
-			PubDate: item.PubDate,
-		})
-	}
-
-	newCount, err := p.store.BatchCreateItemsIgnore(feed.ID, inputs)
-	if err != nil {
-		p.logger.Error("failed to batch create items", "feed_id", feed.ID, "error", err)
-		return
-	}
-
 	if err := p.store.UpdateFeedFetchSuccess(feed.ID, store.UpdateFeedFetchSuccessParams{
-		CheckedAt:       checkedAt,
-		HTTPStatus:      result.HTTPStatus,
-		ETag:            result.ETag,
-		LastModified:    result.LastModified,
-		CacheControl:    result.CacheControl,
-		ExpiresAt:       result.ExpiresAt,
-		RetryAfterUntil: result.RetryAfterUntil,
-		NextCheckAt:     nextCheckAt,
+		CheckedAt:        checkedAt,
+		HTTPStatus:       result.HTTPStatus,
+		ETag:             result.ETag,
+		LastModified:     result.LastModified,
+		CacheControl:     result.CacheControl,
+		ExpiresAt:        result.ExpiresAt,
+		RetryAfterUntil:  result.RetryAfterUntil,
+		NextCheckAt:      nextCheckAt,
+		AdaptiveInterval: schedule.adaptiveInterval,
+		PublishInterval:  schedule.publishInterval,
+		NotModifiedRatio: schedule.notModifiedRatio,
 	}); err != nil {
 		p.logger.Error("failed to update fetch state", "feed_id", feed.ID, "error", err)
+		attempt.ErrorClass, attempt.Error = model.FetchErrorInternal, err.Error()
 		return
 	}
 
-	if strings.TrimSpace(feed.SiteURL) == "" && result.SiteURL != "" {
+	p.trackRedirect(feed, result.PermanentURL)
+
+	siteURL := feed.SiteURL
+	if strings.TrimSpace(siteURL) == "" && result.SiteURL != "" {
+		siteURL = result.SiteURL
 		if err := p.store.UpdateFeedSiteURLIfEmpty(feed.ID, result.SiteURL); err != nil {
 			p.logger.Warn("failed to auto-fill site_url", "feed_id", feed.ID, "site_url", result.SiteURL, "error", err)
 		}
 	}
 
-	p.logger.Info("feed pulled successfully", "feed_id", feed.ID, "feed_name", feed.Name, "new_items", newCount)
+	p.refreshFavicon(ctx, feed, siteURL, result.ImageURL)
+	p.ensureWebSub(ctx, feed, result, checkedAt)
+	p.extractReadable(ctx, feed)
+
+	p.logger.Info("feed pulled successfully", "feed_id", feed.ID, "feed_name", feed.Name, "new_items", newCount, "updated_items", upserted.Updated, "dropped_items", upserted.Dropped)
+	return nil
 }
 
-// RefreshAll triggers refresh for all non-suspended feeds and waits until all
-// started refresh jobs have completed. It bypasses backoff/interval skip logic.
-// Concurrency is controlled by the same semaphore as periodic pulls.
-func (p *Puller) RefreshAll(ctx context.Context) (int, error) {
-	feeds, err := p.store.ListFeeds()
-	if err != nil {
-		return 0, fmt.Errorf("list feeds: %w", err)
+// trackRedirect counts consecutive checks that ended at the same permanent
+// redirect target and moves the feed link there once the count reaches
+// FUSION_PULL_REDIRECT_THRESHOLD.
+func (p *Puller) trackRedirect(feed *model.Feed, target string) {
+	if target == feed.Link {
+		target = ""
+	}
+	if target == "" && feed.FetchState.RedirectURL == "" {
+		return
 	}
 
-	count, err := p.dispatchFeeds(ctx, feeds, func(feed *model.Feed) bool {
-		return !feed.Suspended
-	})
+	count, err := p.store.RecordFeedRedirect(feed.ID, target)
 	if err != nil {
-		return count, err
+		p.logger.Warn("failed to record redirect", "feed_id", feed.ID, "error", err)
+		return
+	}
+	if target == "" || count < int64(p.config.PullRedirectThreshold) {
+		return
+	}
+	// Credentials were entered for the original host; moving the feed would
+	// send them to the new one on every pull.
+	if !feed.Auth.IsZero() && !sameHost(feed.Link, target) {
+		p.logger.Warn("not following permanent redirect of authenticated feed to another host", "feed_id", feed.ID, "from", feed.Link, "to", target)
+		return
 	}
 
-	return count, nil
+	if err := p.store.MoveFeedLink(feed.ID, feed.Link, target); err != nil {
+		p.logger.Warn("failed to follow permanent redirect", "feed_id", feed.ID, "from", feed.Link, "to", target, "error", err)
+		return
+	}
+	p.logger.Info("feed link updated after permanent redirect", "feed_id", feed.ID, "from", feed.Link, "to", target)
+	feed.Link = target
+}
+
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Host, ub.Host)
 }
 
-func (p *Puller) dispatchFeeds(ctx context.Context, feeds []*model.Feed, shouldPull func(*model.Feed) bool) (int, error) {
-	count := 0
-	var wg sync.WaitGroup
-	var acquireErr error
+// suspendGone stops polling a feed whose server answered 410 Gone.
+func (p *Puller) suspendGone(feed *model.Feed) {
+	reason := "HTTP 410 Gone: the server reports the feed was removed permanently"
+	if err := p.store.SuspendFeed(feed.ID, reason); err != nil {
+		p.logger.Error("failed to suspend gone feed", "feed_id", feed.ID, "error", err)
+		return
+	}
+	p.logger.Warn("feed suspended", "feed_id", feed.ID, "feed_name", feed.Name, "reason", reason)
+}
 
-	for _, feed := range feeds {
-		if !shouldPull(feed) {
-			continue
+func batchCreateInputs(items []*ParsedItem, feedLink string) []store.BatchCreateItemInput {
+	inputs := make([]store.BatchCreateItemInput, 0, len(items))
+	for _, item := range items {
+		// Relative URLs in content are relative to the item page.
+		base := item.Link
+		if base == "" {
+			base = feedLink
 		}
 
-		if err := p.concurrency.Acquire(ctx, 1); err != nil {
-			acquireErr = err
-			break
-		}
+		inputs = append(inputs, store.BatchCreateItemInput{
+			GUID:       item.GUID,
+			Title:      item.Title,
+			Link:       item.Link,
+			Content:    sanitize.HTML(item.Content, base),
+			Author:     item.Author,
+			Summary:    sanitize.HTML(item.Summary, base),
+			Categories: item.Categories,
+			Enclosures: item.Enclosures,
+			Duration:   item.Duration,
+			Episode:    item.Episode,
+			ImageURL:   item.ImageURL,
+			PubDate:    item.PubDate,
+
+			SanitizeVersion: sanitize.PolicyVersion,
+		})
+	}
+	return inputs
+}
 
-		count++
-		wg.Add(1)
-		go func(f *model.Feed) {
-			defer wg.Done()
-			defer p.concurrency.Release(1)
-			p.pullFeed(ctx, f)
-		}(feed)
+// acquire reserves a request slot on the feed's host and then a global one,
+// returning a func releasing both.
+func (p *Puller) acquire(ctx context.Context, feed *model.Feed) (func(), error) {
+	releaseHost, err := p.hosts.acquire(ctx, feedHost(feed.Link))
+	if err != nil {
+		return nil, err
+	}
+	if err := p.concurrency.Acquire(ctx, 1); err != nil {
+		releaseHost()
+		return nil, err
 	}
+	return func() {
+		p.concurrency.Release(1)
+		releaseHost()
+	}, nil
+}
+
+// Events returns the bus on which pulls and new items are announced.
+func (p *Puller) Events() *events.Bus {
+	return p.events
+}
 
-	wg.Wait()
-	return count, acquireErr
+// Status reports the per-host limits and counters.
+func (p *Puller) Status() model.PullStatus {
+	return model.PullStatus{
+		HostConcurrency: p.config.PullHostConcurrency,
+		HostDelay:       p.config.PullHostDelay,
+		Hosts:           p.hosts.status(),
+	}
 }
 
 // RefreshFeed manually triggers refresh for specific feed (bypasses skip logic).
-// Used by HTTP handler for manual refresh requests.
+// Used by HTTP handler for manual refresh requests. A failed fetch is recorded
+// in the feed's fetch state and log rather than returned.
 func (p *Puller) RefreshFeed(ctx context.Context, feedID int64) error {
+	// Also picks up new feeds whose initial pull could not run.
+	defer p.Reschedule(feedID)
+
 	feed, err := p.store.GetFeed(feedID)
 	if err != nil {
 		return fmt.Errorf("get feed: %w", err)
 	}
 
-	if err := p.concurrency.Acquire(ctx, 1); err != nil {
+	release, err := p.acquire(ctx, feed)
+	if err != nil {
 		return err
 	}
-	defer p.concurrency.Release(1)
+	defer release()
 
 	p.pullFeed(ctx, feed)
 	return nil
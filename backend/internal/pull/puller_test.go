@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/model"
 	"github.com/0x2E/fusion/internal/store"
 )
 
@@ -53,7 +54,7 @@ func TestRefreshFeedPreservesValidatorsWhen304OmitHeaders(t *testing.T) {
 		PullConcurrency:   1,
 		PullMaxBackoff:    604800,
 		AllowPrivateFeeds: true,
-	})
+	}, nil)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -121,12 +122,12 @@ func TestRefreshAllWaitsForRunningJobs(t *testing.T) {
 		PullConcurrency:   1,
 		PullMaxBackoff:    604800,
 		AllowPrivateFeeds: true,
-	})
+	}, nil)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	count, err := p.RefreshAll(ctx)
+	count, err := p.RefreshAll(ctx, nil)
 	if err != nil {
 		t.Fatalf("refresh all: %v", err)
 	}
@@ -144,3 +145,327 @@ func TestRefreshAllWaitsForRunningJobs(t *testing.T) {
 		}
 	}
 }
+
+func TestRefreshFeedHonorsPerFeedSchedule(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	var fail atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Demo</title>
+<item><guid>g1</guid><title>Item</title></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	feed, err := st.CreateFeed(1, "Feed", server.URL, "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	// An interval longer than the global max backoff must not be capped by it.
+	interval := int64(10 * 86400)
+	if err := st.UpdateFeed(feed.ID, store.UpdateFeedParams{PullInterval: &interval}); err != nil {
+		t.Fatalf("update feed: %v", err)
+	}
+
+	p := New(st, &config.Config{
+		PullInterval:      1800,
+		PullTimeout:       5,
+		PullConcurrency:   1,
+		PullMaxBackoff:    604800,
+		AllowPrivateFeeds: true,
+	}, nil)
+	if err := p.RefreshFeed(context.Background(), feed.ID); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	got, err := st.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if delay := got.FetchState.NextCheckAt - got.FetchState.LastCheckedAt; delay != interval {
+		t.Fatalf("next check delay = %d, want %d", delay, interval)
+	}
+
+	// A per-feed max backoff caps failure scheduling.
+	interval, maxBackoff := int64(600), int64(900)
+	if err := st.UpdateFeed(feed.ID, store.UpdateFeedParams{PullInterval: &interval, MaxBackoff: &maxBackoff}); err != nil {
+		t.Fatalf("update feed: %v", err)
+	}
+	fail.Store(true)
+	for range 3 {
+		if err := p.RefreshFeed(context.Background(), feed.ID); err != nil {
+			t.Fatalf("refresh: %v", err)
+		}
+	}
+
+	got, err = st.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if got.FetchState.ConsecutiveFailures != 3 {
+		t.Fatalf("consecutive_failures = %d, want 3", got.FetchState.ConsecutiveFailures)
+	}
+	if delay := got.FetchState.NextCheckAt - got.FetchState.LastCheckedAt; delay != maxBackoff {
+		t.Fatalf("next check delay = %d, want %d", delay, maxBackoff)
+	}
+}
+
+func TestRefreshFeedAdaptsIntervalToPublishFrequency(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	now := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>Hourly</title>`)
+		for i := range 4 {
+			pubDate := now.Add(-time.Minute - time.Duration(i)*time.Hour).UTC().Format(time.RFC1123Z)
+			_, _ = fmt.Fprintf(w, `<item><guid>g%d</guid><title>Item</title><pubDate>%s</pubDate></item>`, i, pubDate)
+		}
+		_, _ = fmt.Fprint(w, `</channel></rss>`)
+	}))
+	defer server.Close()
+
+	feed, err := st.CreateFeed(1, "Feed", server.URL, "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	p := New(st, &config.Config{
+		PullInterval:      600,
+		PullTimeout:       5,
+		PullConcurrency:   1,
+		PullMaxBackoff:    604800,
+		PullAdaptive:      true,
+		PullMinInterval:   300,
+		PullMaxInterval:   86400,
+		AllowPrivateFeeds: true,
+	}, nil)
+	if err := p.RefreshFeed(context.Background(), feed.ID); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	got, err := st.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if got.FetchState.PublishInterval != 3600 {
+		t.Fatalf("publish_interval = %d, want 3600", got.FetchState.PublishInterval)
+	}
+	// Half the publish gap; the first check found new items, so no stretch.
+	if got.FetchState.AdaptiveInterval != 1800 {
+		t.Fatalf("adaptive_interval = %d, want 1800", got.FetchState.AdaptiveInterval)
+	}
+	if delay := got.FetchState.NextCheckAt - got.FetchState.LastCheckedAt; delay != 1800 {
+		t.Fatalf("next check delay = %d, want 1800", delay)
+	}
+
+	// A second check finds nothing new and stretches the interval.
+	if err := p.RefreshFeed(context.Background(), feed.ID); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	got, err = st.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if got.FetchState.NotModifiedRatio <= 0 {
+		t.Fatalf("not_modified_ratio = %f, want > 0", got.FetchState.NotModifiedRatio)
+	}
+	if got.FetchState.AdaptiveInterval <= 1800 {
+		t.Fatalf("adaptive_interval = %d, want > 1800", got.FetchState.AdaptiveInterval)
+	}
+}
+
+func TestRefreshFeedFollowsStablePermanentRedirect(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	moved, err := st.CreateFeed(1, "Moved", server.URL+"/old", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	gone, err := st.CreateFeed(1, "Gone", server.URL+"/gone", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	p := New(st, &config.Config{
+		PullInterval:          1800,
+		PullTimeout:           5,
+		PullConcurrency:       1,
+		PullMaxBackoff:        604800,
+		PullRedirectThreshold: 2,
+		AllowPrivateFeeds:     true,
+	}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.RefreshFeed(ctx, moved.ID); err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+	feed, err := st.GetFeed(moved.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if feed.Link != server.URL+"/old" || feed.FetchState.RedirectURL != server.URL+"/new" || feed.FetchState.RedirectCount != 1 {
+		t.Fatalf("after first refresh: link=%q redirect=%q count=%d", feed.Link, feed.FetchState.RedirectURL, feed.FetchState.RedirectCount)
+	}
+
+	if err := p.RefreshFeed(ctx, moved.ID); err != nil {
+		t.Fatalf("second refresh: %v", err)
+	}
+	feed, err = st.GetFeed(moved.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if feed.Link != server.URL+"/new" || feed.FetchState.RedirectURL != "" {
+		t.Fatalf("after second refresh: link=%q redirect=%q", feed.Link, feed.FetchState.RedirectURL)
+	}
+	history, err := st.ListFeedLinkHistory(moved.ID)
+	if err != nil || len(history) != 1 || history[0].OldLink != server.URL+"/old" {
+		t.Fatalf("link history = %+v, %v", history, err)
+	}
+
+	if err := p.RefreshFeed(ctx, gone.ID); err != nil {
+		t.Fatalf("refresh gone feed: %v", err)
+	}
+	feed, err = st.GetFeed(gone.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if !feed.Suspended || feed.FetchState.SuspendReason == "" || feed.FetchState.LastHTTPStatus != http.StatusGone {
+		t.Fatalf("gone feed: suspended=%v reason=%q status=%d", feed.Suspended, feed.FetchState.SuspendReason, feed.FetchState.LastHTTPStatus)
+	}
+}
+
+func TestAuthenticatedFeedKeepsLinkOnCrossHostRedirect(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	created, err := st.CreateFeed(1, "Private", "https://feeds.example.com/private.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	p := New(st, &config.Config{PullInterval: 1800, PullTimeout: 5, PullConcurrency: 1, PullMaxBackoff: 604800, PullRedirectThreshold: 1}, nil)
+
+	feed := &model.Feed{ID: created.ID, Link: created.Link, Auth: &model.FeedAuth{Token: "secret"}}
+	p.trackRedirect(feed, "https://elsewhere.example.net/private.xml")
+	if got, err := st.GetFeed(created.ID); err != nil || got.Link != created.Link {
+		t.Fatalf("authenticated feed moved to another host: %+v, %v", got, err)
+	}
+
+	p.trackRedirect(feed, "https://feeds.example.com/moved.xml")
+	if got, err := st.GetFeed(created.ID); err != nil || got.Link != "https://feeds.example.com/moved.xml" {
+		t.Fatalf("same-host redirect not followed: %+v, %v", got, err)
+	}
+}
+
+func TestRefreshFeedRecordsFetchAttempts(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	const body = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Demo</title>
+<item><guid>g1</guid><title>One</title><link>https://example.com/1</link></item>
+<item><guid>g2</guid><title>Two</title><link>https://example.com/2</link></item>
+</channel></rss>`
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed" {
+			http.NotFound(w, r)
+			return
+		}
+		switch atomic.AddInt32(&requestCount, 1) {
+		case 1:
+			w.Header().Set("Content-Type", "application/rss+xml")
+			_, _ = fmt.Fprint(w, body)
+		case 2:
+			w.WriteHeader(http.StatusNotModified)
+		case 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = fmt.Fprint(w, "not a feed")
+		}
+	}))
+	defer server.Close()
+
+	feed, err := st.CreateFeed(1, "Feed", server.URL+"/feed", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	p := New(st, &config.Config{PullInterval: 1800, PullTimeout: 5, PullConcurrency: 1, PullMaxBackoff: 604800, AllowPrivateFeeds: true}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for range 4 {
+		if err := p.RefreshFeed(ctx, feed.ID); err != nil {
+			t.Fatalf("refresh: %v", err)
+		}
+	}
+
+	entries, err := st.ListFeedFetchLog(feed.ID)
+	if err != nil {
+		t.Fatalf("list fetch log: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 attempts, got %d", len(entries))
+	}
+	// Newest first.
+	want := []struct {
+		status   int
+		class    string
+		newItems int64
+	}{
+		{http.StatusOK, model.FetchErrorParse, 0},
+		{http.StatusServiceUnavailable, model.FetchErrorHTTP, 0},
+		{http.StatusNotModified, "", 0},
+		{http.StatusOK, "", 2},
+	}
+	for i, w := range want {
+		got := entries[i]
+		if got.HTTPStatus != w.status || got.ErrorClass != w.class || got.NewItems != w.newItems {
+			t.Errorf("attempt %d: got %+v, want %+v", i, got, w)
+		}
+	}
+	if entries[3].Bytes != int64(len(body)) {
+		t.Errorf("bytes = %d, want %d", entries[3].Bytes, len(body))
+	}
+	if entries[0].Error == "" || entries[3].Error != "" {
+		t.Errorf("unexpected error messages: %q, %q", entries[0].Error, entries[3].Error)
+	}
+}
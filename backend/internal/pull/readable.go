@@ -0,0 +1,129 @@
+package pull
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"github.com/0x2E/fusion/internal/pkg/readability"
+	"github.com/0x2E/fusion/internal/pkg/sanitize"
+)
+
+const (
+	// readableBatchSize caps full-text extractions per feed refresh, so a
+	// newly opted-in feed backfills gradually instead of in one burst.
+	readableBatchSize = 10
+
+	maxReadablePageBytes = 5 << 20
+)
+
+var errNotHTML = errors.New("item link is not an HTML page")
+
+// FetchReadable downloads an article page and extracts its main content.
+// Requests use the feed's proxy and the SSRF-guarded client.
+func FetchReadable(ctx context.Context, link, proxy string, timeout time.Duration, allowPrivateFeeds bool) (string, error) {
+	if err := httpc.ValidateRequestURL(ctx, link, allowPrivateFeeds); err != nil {
+		return "", fmt.Errorf("validate item url: %w", err)
+	}
+
+	client, err := httpc.NewClient(timeout, proxy, allowPrivateFeeds)
+	if err != nil {
+		return "", fmt.Errorf("create client: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	httpc.SetDefaultHeaders(req)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch article: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return "", errNotHTML
+	}
+
+	// Relative links resolve against the final URL after redirects.
+	content, err := readability.Extract(io.LimitReader(resp.Body, maxReadablePageBytes), resp.Request.URL)
+	if err != nil {
+		return "", err
+	}
+
+	return sanitize.HTML(content, resp.Request.URL.String()), nil
+}
+
+// extractReadable fetches full text for the newest items of an opted-in feed
+// that were not attempted yet. Failures are recorded so they are not retried
+// in the background; on-demand requests still retry them.
+func (p *Puller) extractReadable(ctx context.Context, feed *model.Feed) {
+	if !feed.FullText {
+		return
+	}
+
+	items, err := p.store.ListItemsPendingReadable(feed.ID, readableBatchSize)
+	if err != nil {
+		p.logger.Warn("failed to list items pending full-text extraction", "feed_id", feed.ID, "error", err)
+		return
+	}
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return
+		}
+
+		content, err := FetchReadable(ctx, item.Link, feed.Proxy, p.timeout, p.config.AllowPrivateFeeds)
+		if err != nil {
+			p.logger.Debug("full-text extraction failed", "feed_id", feed.ID, "item_id", item.ID, "link", item.Link, "error", err)
+			content = ""
+		}
+		if err := p.store.UpdateItemReadable(item.ID, content, time.Now().Unix()); err != nil {
+			p.logger.Warn("failed to store full text", "feed_id", feed.ID, "item_id", item.ID, "error", err)
+		}
+	}
+}
+
+// ReadableContent returns the extracted article of an item, fetching and
+// caching it when it has not been extracted yet. Works for any feed.
+func (p *Puller) ReadableContent(ctx context.Context, itemID int64) (string, error) {
+	item, err := p.store.GetItem(itemID)
+	if err != nil {
+		return "", err
+	}
+	if item.ReadableContent != "" {
+		return item.ReadableContent, nil
+	}
+	if strings.TrimSpace(item.Link) == "" {
+		return "", readability.ErrNoContent
+	}
+
+	feed, err := p.store.GetFeed(item.FeedID)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := FetchReadable(ctx, item.Link, feed.Proxy, p.timeout, p.config.AllowPrivateFeeds)
+	if err != nil {
+		return "", err
+	}
+	if err := p.store.UpdateItemReadable(item.ID, content, time.Now().Unix()); err != nil {
+		return "", err
+	}
+
+	return content, nil
+}
@@ -0,0 +1,115 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+const articlePage = `<!doctype html><html><head><title>Post</title></head><body>
+<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+<article>
+<p>The first paragraph of the article explains, at some length, what this post is about and why it matters to readers.</p>
+<p>The second paragraph continues with more detail, commas, and enough words to look like real prose rather than navigation.</p>
+<p><img src="/img/figure.png" alt="figure"> A third paragraph closes the article with a summary of the points above.</p>
+</article>
+<footer>Copyright footer text</footer>
+</body></html>`
+
+func TestRefreshFeedExtractsFullText(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	var pageRequests atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Demo</title>
+<item><guid>g1</guid><title>Item</title><link>%s/posts/1</link><description>teaser</description></item>
+</channel></rss>`, server.URL)
+		case "/posts/1":
+			pageRequests.Add(1)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = fmt.Fprint(w, articlePage)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	feed, err := st.CreateFeed(1, "Feed", server.URL+"/feed.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	fullText := true
+	if err := st.UpdateFeed(feed.ID, store.UpdateFeedParams{FullText: &fullText}); err != nil {
+		t.Fatalf("enable full text: %v", err)
+	}
+
+	p := New(st, &config.Config{
+		PullInterval:      1800,
+		PullTimeout:       5,
+		PullConcurrency:   1,
+		PullMaxBackoff:    604800,
+		AllowPrivateFeeds: true,
+	}, nil)
+	for range 2 {
+		if err := p.RefreshFeed(context.Background(), feed.ID); err != nil {
+			t.Fatalf("refresh: %v", err)
+		}
+	}
+
+	if pageRequests.Load() != 1 {
+		t.Fatalf("expected the article to be fetched once, got %d", pageRequests.Load())
+	}
+
+	items, err := st.ListItems(store.ListItemsParams{FeedID: &feed.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListItems() failed: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	readable := items[0].ReadableContent
+	if !strings.Contains(readable, "second paragraph") || strings.Contains(readable, "Copyright") {
+		t.Fatalf("unexpected readable content: %q", readable)
+	}
+	if !strings.Contains(readable, server.URL+"/img/figure.png") {
+		t.Fatalf("expected image src to be absolute, got %q", readable)
+	}
+	if items[0].Content != "teaser" {
+		t.Fatalf("feed content should be kept, got %q", items[0].Content)
+	}
+
+	// The stored result is served without another fetch.
+	content, err := p.ReadableContent(context.Background(), items[0].ID)
+	if err != nil || content != readable || pageRequests.Load() != 1 {
+		t.Fatalf("ReadableContent() = %q, %v (requests=%d)", content, err, pageRequests.Load())
+	}
+}
+
+func TestReadableContentRejectsNonHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.7"))
+	}))
+	defer server.Close()
+
+	if _, err := FetchReadable(context.Background(), server.URL+"/paper.pdf", "", 5*time.Second, true); err == nil {
+		t.Fatal("expected non-HTML page to be rejected")
+	}
+}
@@ -0,0 +1,159 @@
+package pull
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/0x2E/fusion/internal/events"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+// Progress receives the progress of a multi-feed refresh: the number of feeds
+// once, then one step per feed with the error of a failed or skipped pull.
+// Steps may be reported concurrently.
+type Progress interface {
+	SetTotal(total int)
+	Step(err error)
+}
+
+type noProgress struct{}
+
+func (noProgress) SetTotal(int) {}
+func (noProgress) Step(error)   {}
+
+// refreshAllProgress publishes refresh_all.progress events and forwards to
+// the caller's Progress.
+type refreshAllProgress struct {
+	bus  *events.Bus
+	next Progress
+
+	mu    sync.Mutex
+	state events.RefreshProgress
+}
+
+func (r *refreshAllProgress) SetTotal(total int) {
+	r.mu.Lock()
+	r.state.Total = total
+	r.bus.Publish(events.Event{Type: events.RefreshAllProgress, Data: r.state})
+	r.mu.Unlock()
+	r.next.SetTotal(total)
+}
+
+func (r *refreshAllProgress) Step(err error) {
+	r.mu.Lock()
+	r.state.Done++
+	var blocked *HostBlockedError
+	switch {
+	case errors.As(err, &blocked):
+		r.state.Skipped++
+	case err != nil:
+		r.state.Failed++
+	}
+	r.bus.Publish(events.Event{Type: events.RefreshAllProgress, Data: r.state})
+	r.mu.Unlock()
+	r.next.Step(err)
+}
+
+// RefreshAll triggers refresh for all non-suspended feeds and waits until all
+// started refresh jobs have completed. It bypasses backoff/interval skip logic.
+// Concurrency is controlled by the same host limits and semaphore as periodic
+// pulls; feeds on a host blocked by Retry-After are skipped. Progress is
+// reported to progress (which may be nil) and as refresh_all.progress events.
+func (p *Puller) RefreshAll(ctx context.Context, progress Progress) (int, error) {
+	feeds, err := p.store.ListFeeds()
+	if err != nil {
+		return 0, fmt.Errorf("list feeds: %w", err)
+	}
+
+	var selected []*model.Feed
+	for _, feed := range feeds {
+		if !feed.Suspended {
+			selected = append(selected, feed)
+		}
+	}
+
+	if progress == nil {
+		progress = noProgress{}
+	}
+	return p.dispatchFeeds(ctx, selected, &refreshAllProgress{bus: p.events, next: progress})
+}
+
+// RefreshFeeds pulls the given feeds like RefreshAll, e.g. right after they
+// were imported, without publishing refresh_all.progress. Feeds deleted
+// meanwhile are left out.
+func (p *Puller) RefreshFeeds(ctx context.Context, feedIDs []int64, progress Progress) (int, error) {
+	if progress == nil {
+		progress = noProgress{}
+	}
+
+	feeds := make([]*model.Feed, 0, len(feedIDs))
+	for _, id := range feedIDs {
+		feed, err := p.store.GetFeed(id)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				continue
+			}
+			return 0, fmt.Errorf("get feed %d: %w", id, err)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	return p.dispatchFeeds(ctx, feeds, progress)
+}
+
+// dispatchFeeds pulls feeds concurrently and returns how many were pulled.
+// Each feed waits for its host first, so a busy host does not hold global
+// slots that feeds on other hosts could use. Failed steps carry the feed's
+// ID and name.
+func (p *Puller) dispatchFeeds(ctx context.Context, feeds []*model.Feed, progress Progress) (int, error) {
+	var (
+		mu         sync.Mutex
+		count      int
+		acquireErr error
+		wg         sync.WaitGroup
+	)
+	progress.SetTotal(len(feeds))
+
+	for _, feed := range feeds {
+		wg.Add(1)
+		go func(f *model.Feed) {
+			defer wg.Done()
+			defer p.Reschedule(f.ID)
+
+			release, err := p.acquire(ctx, f)
+			if err != nil {
+				var blocked *HostBlockedError
+				if errors.As(err, &blocked) {
+					p.logger.Debug("skipping feed on rate limited host", "feed_id", f.ID, "host", blocked.Host, "until", blocked.Until)
+				} else {
+					mu.Lock()
+					acquireErr = cmp.Or(acquireErr, err)
+					mu.Unlock()
+				}
+				progress.Step(feedError(f, err))
+				return
+			}
+			defer release()
+
+			err = p.pullFeed(ctx, f)
+			mu.Lock()
+			count++
+			mu.Unlock()
+			progress.Step(feedError(f, err))
+		}(feed)
+	}
+
+	wg.Wait()
+	return count, acquireErr
+}
+
+func feedError(feed *model.Feed, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("feed %d (%s): %w", feed.ID, feed.Name, err)
+}
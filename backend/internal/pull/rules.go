@@ -0,0 +1,44 @@
+package pull
+
+import (
+	"fmt"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/rules"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+// ingestInputs converts parsed items to store inputs carrying the outcome of
+// the ingest rules that apply to feed. The store only acts on the outcome for
+// items it has not seen yet.
+func (p *Puller) ingestInputs(feed *model.Feed, items []*ParsedItem) ([]store.BatchCreateItemInput, error) {
+	inputs := batchCreateInputs(items, feed.Link)
+
+	list, err := p.store.ListRulesForFeed(feed.ID, feed.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("list rules: %w", err)
+	}
+	set, err := rules.Compile(list)
+	if err != nil {
+		return nil, fmt.Errorf("compile rules: %w", err)
+	}
+	if set.Len() == 0 {
+		return inputs, nil
+	}
+
+	for i := range inputs {
+		input := &inputs[i]
+		outcome := set.Evaluate(rules.Fields{
+			Title:   input.Title,
+			Content: input.Content,
+			Link:    input.Link,
+			Author:  input.Author,
+		})
+		input.Drop = outcome.Drop
+		input.Read = outcome.Read
+		input.Bookmark = outcome.Bookmark
+		input.GroupID = outcome.GroupID
+		input.RuleIDs = outcome.RuleIDs
+	}
+	return inputs, nil
+}
@@ -0,0 +1,72 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+func TestRefreshFeedAppliesIngestRules(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Demo</title>
+<item><guid>g1</guid><title>Sponsored: gadgets</title><link>https://example.com/1</link></item>
+<item><guid>g2</guid><title>Weekly digest</title><link>https://example.com/2</link></item>
+<item><guid>g3</guid><title>Release</title><link>https://example.com/3</link></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	feed, err := st.CreateFeed(1, "Feed", server.URL, "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	drop, err := st.CreateRule(&model.Rule{Name: "ads", FeedID: feed.ID, Field: model.RuleFieldTitle, MatchType: model.RuleMatchRegex, Pattern: `^Sponsored`, Action: model.RuleActionDrop, Enabled: true})
+	if err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+	if _, err := st.CreateRule(&model.Rule{Name: "digests", Field: model.RuleFieldTitle, MatchType: model.RuleMatchContains, Pattern: "digest", Action: model.RuleActionRead, Enabled: true}); err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	p := New(st, &config.Config{PullInterval: 1800, PullTimeout: 5, PullConcurrency: 1, PullMaxBackoff: 604800, AllowPrivateFeeds: true}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for range 2 {
+		if err := p.RefreshFeed(ctx, feed.ID); err != nil {
+			t.Fatalf("refresh: %v", err)
+		}
+	}
+
+	items, err := st.ListItems(store.ListItemsParams{FeedID: &feed.ID, SortAsc: true})
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected dropped item to be skipped, got %d items", len(items))
+	}
+	for _, item := range items {
+		if wantUnread := item.GUID != "g2"; item.Unread != wantUnread {
+			t.Errorf("%s: unread = %v, want %v", item.GUID, item.Unread, wantUnread)
+		}
+	}
+
+	if got, err := st.GetRule(drop.ID); err != nil || got.HitCount != 1 {
+		t.Fatalf("expected one hit for the drop rule, got %+v, %v", got, err)
+	}
+}
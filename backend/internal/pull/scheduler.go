@@ -0,0 +1,308 @@
+package pull
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pullpolicy"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+// scheduleResyncInterval bounds how long schedule changes made without a
+// wakeup (e.g. WebSub leases expiring) take to reach the queue.
+const scheduleResyncInterval = 10 * time.Minute
+
+// minRepullDelay keeps a feed whose pull left it due (e.g. a storage error
+// before the fetch state was saved) from being pulled again in a tight loop.
+const minRepullDelay = time.Minute
+
+type scheduledFeed struct {
+	feedID int64
+	dueAt  int64
+	index  int
+}
+
+// feedQueue is a min-heap of feeds ordered by due time, indexed by feed ID so
+// a feed can be moved or removed in O(log n).
+type feedQueue struct {
+	items []*scheduledFeed
+	byID  map[int64]*scheduledFeed
+}
+
+func newFeedQueue() *feedQueue {
+	return &feedQueue{byID: make(map[int64]*scheduledFeed)}
+}
+
+func (q *feedQueue) Len() int { return len(q.items) }
+
+func (q *feedQueue) Less(i, j int) bool {
+	if q.items[i].dueAt != q.items[j].dueAt {
+		return q.items[i].dueAt < q.items[j].dueAt
+	}
+	return q.items[i].feedID < q.items[j].feedID
+}
+
+func (q *feedQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.items[i].index = i
+	q.items[j].index = j
+}
+
+func (q *feedQueue) Push(x any) {
+	e := x.(*scheduledFeed)
+	e.index = len(q.items)
+	q.items = append(q.items, e)
+	q.byID[e.feedID] = e
+}
+
+func (q *feedQueue) Pop() any {
+	last := len(q.items) - 1
+	e := q.items[last]
+	q.items[last] = nil
+	q.items = q.items[:last]
+	delete(q.byID, e.feedID)
+	return e
+}
+
+// set inserts a feed or moves it to a new due time.
+func (q *feedQueue) set(feedID, dueAt int64) {
+	if e, ok := q.byID[feedID]; ok {
+		e.dueAt = dueAt
+		heap.Fix(q, e.index)
+		return
+	}
+	heap.Push(q, &scheduledFeed{feedID: feedID, dueAt: dueAt})
+}
+
+func (q *feedQueue) remove(feedID int64) {
+	if e, ok := q.byID[feedID]; ok {
+		heap.Remove(q, e.index)
+	}
+}
+
+// next returns the earliest due time, or false when the queue is empty.
+func (q *feedQueue) next() (int64, bool) {
+	if len(q.items) == 0 {
+		return 0, false
+	}
+	return q.items[0].dueAt, true
+}
+
+// popDue removes and returns the feeds due at or before now.
+func (q *feedQueue) popDue(now int64) []int64 {
+	var due []int64
+	for len(q.items) > 0 && q.items[0].dueAt <= now {
+		due = append(due, heap.Pop(q).(*scheduledFeed).feedID)
+	}
+	return due
+}
+
+// Start runs the pull scheduler until the context is cancelled, then waits
+// for in-flight pulls. Feeds sit in a queue keyed on their due time; a timer
+// fires for the earliest one. Reschedule and finished pulls reload single
+// feeds, and the whole queue is rebuilt from the store periodically.
+func (p *Puller) Start(ctx context.Context) error {
+	p.logger.Info("pull service started", "interval", p.interval, "timeout", p.timeout, "concurrency", p.config.PullConcurrency)
+
+	queue := newFeedQueue()
+	inflight := make(map[int64]bool)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	p.resync(queue, inflight, time.Duration(p.config.PullStartupJitter)*time.Second)
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	resync := time.NewTicker(scheduleResyncInterval)
+	defer resync.Stop()
+
+	for {
+		var due <-chan time.Time
+		if dueAt, ok := queue.next(); ok {
+			timer.Reset(time.Until(time.Unix(dueAt, 0)))
+			due = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			p.logger.Info("pull service stopping")
+			return ctx.Err()
+		case <-due:
+			for _, feedID := range queue.popDue(time.Now().Unix()) {
+				inflight[feedID] = true
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					p.pullScheduled(ctx, feedID)
+					p.notify(feedID, true)
+				}()
+			}
+		case <-p.wake:
+			now := time.Now().Unix()
+			for feedID, finished := range p.takeWakeups() {
+				notBefore := int64(0)
+				if finished {
+					delete(inflight, feedID)
+					notBefore = now + int64(minRepullDelay.Seconds())
+				} else if inflight[feedID] {
+					// Reloaded when the running pull finishes.
+					continue
+				}
+				p.reload(queue, feedID, notBefore)
+			}
+		case <-resync.C:
+			p.resync(queue, inflight, 0)
+		}
+	}
+}
+
+// Reschedule makes the scheduler reload a feed's due time, e.g. after it was
+// created, edited or refreshed. It never blocks.
+func (p *Puller) Reschedule(feedID int64) {
+	p.notify(feedID, false)
+}
+
+// ApplySchedule pulls a feed's next check forward to what its current
+// schedule allows since the last check, so a shorter interval or backoff
+// takes effect without waiting out a check planned under the old one. Call it
+// after editing the schedule, then Reschedule.
+func (p *Puller) ApplySchedule(feedID int64) error {
+	feed, err := p.store.GetFeedSchedule(feedID)
+	if err != nil {
+		return err
+	}
+	state := feed.FetchState
+	if feed.Suspended || state.NextCheckAt <= 0 || state.LastCheckedAt <= 0 {
+		return nil
+	}
+
+	interval, maxBackoff := p.scheduleFor(feed)
+	bound := pullpolicy.ComputeNextCheckAt(state.LastCheckedAt, interval, maxBackoff, state.ConsecutiveFailures, 0, "", 0)
+	if bound >= state.NextCheckAt {
+		return nil
+	}
+	return p.store.LowerFeedNextCheck(feed.ID, bound)
+}
+
+// notify queues a wakeup for feedID; finished marks the end of a scheduled pull.
+func (p *Puller) notify(feedID int64, finished bool) {
+	p.wakeMu.Lock()
+	p.wakeups[feedID] = p.wakeups[feedID] || finished
+	p.wakeMu.Unlock()
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Puller) takeWakeups() map[int64]bool {
+	p.wakeMu.Lock()
+	defer p.wakeMu.Unlock()
+
+	wakeups := p.wakeups
+	p.wakeups = make(map[int64]bool)
+	return wakeups
+}
+
+// resync rebuilds the queue from the store, leaving in-flight feeds out.
+// With jitter, feeds already due are spread over [now, now+jitter].
+func (p *Puller) resync(queue *feedQueue, inflight map[int64]bool, jitter time.Duration) {
+	feeds, err := p.store.ListFeedSchedules()
+	if err != nil {
+		p.logger.Error("failed to list feed schedules", "error", err)
+		return
+	}
+
+	now := time.Now().Unix()
+	active := make(map[int64]bool, len(feeds))
+	for _, feed := range feeds {
+		active[feed.ID] = true
+		if inflight[feed.ID] {
+			continue
+		}
+		dueAt := p.dueAt(feed)
+		if jitter > 0 && dueAt <= now {
+			dueAt = now + rand.Int64N(int64(jitter.Seconds())+1)
+		}
+		queue.set(feed.ID, dueAt)
+	}
+	for feedID := range queue.byID {
+		if !active[feedID] {
+			queue.remove(feedID)
+		}
+	}
+}
+
+// reload refreshes one feed's position, not earlier than notBefore, dropping
+// deleted and suspended feeds.
+func (p *Puller) reload(queue *feedQueue, feedID, notBefore int64) {
+	feed, err := p.store.GetFeedSchedule(feedID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			p.logger.Error("failed to load feed schedule", "feed_id", feedID, "error", err)
+		}
+		queue.remove(feedID)
+		return
+	}
+	if feed.Suspended {
+		queue.remove(feedID)
+		return
+	}
+	queue.set(feedID, max(p.dueAt(feed), notBefore))
+}
+
+// pullScheduled pulls a feed taken off the queue once its host and a global
+// concurrency slot are free. The feed is re-read first since it may have
+// changed meanwhile. While the host is blocked by Retry-After the feed is
+// deferred to the end of the block.
+func (p *Puller) pullScheduled(ctx context.Context, feedID int64) {
+	feed, err := p.store.GetFeed(feedID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			p.logger.Error("failed to get scheduled feed", "feed_id", feedID, "error", err)
+		}
+		return
+	}
+
+	interval, maxBackoff := p.scheduleFor(feed)
+	if pullpolicy.ShouldSkip(time.Now().Unix(), runtimeState(feed), interval, maxBackoff) {
+		return
+	}
+
+	release, err := p.acquire(ctx, feed)
+	if err != nil {
+		var blocked *HostBlockedError
+		if errors.As(err, &blocked) {
+			if err := p.store.DeferFeedCheck(feed.ID, blocked.Until.Unix()); err != nil {
+				p.logger.Error("failed to defer feed on rate limited host", "feed_id", feed.ID, "error", err)
+			}
+		}
+		return
+	}
+	defer release()
+
+	p.pullFeed(ctx, feed)
+}
+
+// dueAt is the earliest time the scheduler pulls feed.
+func (p *Puller) dueAt(feed *model.Feed) int64 {
+	interval, maxBackoff := p.scheduleFor(feed)
+	return pullpolicy.DueAt(runtimeState(feed), interval, maxBackoff)
+}
+
+func runtimeState(feed *model.Feed) pullpolicy.FeedRuntimeState {
+	return pullpolicy.FeedRuntimeState{
+		Suspended:           feed.Suspended,
+		RetryAfterUntil:     feed.FetchState.RetryAfterUntil,
+		NextCheckAt:         feed.FetchState.NextCheckAt,
+		ConsecutiveFailures: feed.FetchState.ConsecutiveFailures,
+		LastErrorAt:         feed.FetchState.LastErrorAt,
+		LastCheckedAt:       feed.FetchState.LastCheckedAt,
+	}
+}
@@ -0,0 +1,223 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+func TestFeedQueueOrdersByDueTime(t *testing.T) {
+	q := newFeedQueue()
+	q.set(1, 300)
+	q.set(2, 100)
+	q.set(3, 200)
+	q.set(4, 50)
+
+	// Moving and removing keep the heap consistent.
+	q.set(1, 10)
+	q.remove(4)
+	q.remove(99)
+
+	if next, ok := q.next(); !ok || next != 10 {
+		t.Fatalf("next() = %d, %v; want 10, true", next, ok)
+	}
+	if got := q.popDue(150); !reflect.DeepEqual(got, []int64{1, 2}) {
+		t.Fatalf("popDue(150) = %v, want [1 2]", got)
+	}
+	if got := q.popDue(1000); !reflect.DeepEqual(got, []int64{3}) {
+		t.Fatalf("popDue(1000) = %v, want [3]", got)
+	}
+	if _, ok := q.next(); ok || len(q.byID) != 0 {
+		t.Fatalf("expected empty queue, got %d entries", len(q.byID))
+	}
+}
+
+func TestResyncSpreadsOverdueFeeds(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	overdue, err := st.CreateFeed(1, "Overdue", "https://example.com/a.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	later, err := st.CreateFeed(1, "Later", "https://example.com/b.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	suspended, err := st.CreateFeed(1, "Suspended", "https://example.com/c.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	now := time.Now().Unix()
+	if err := st.UpdateFeedFetchSuccess(later.ID, store.UpdateFeedFetchSuccessParams{CheckedAt: now, HTTPStatus: 200, NextCheckAt: now + 7200}); err != nil {
+		t.Fatalf("update fetch state: %v", err)
+	}
+	suspend := true
+	if err := st.UpdateFeed(suspended.ID, store.UpdateFeedParams{Suspended: &suspend}); err != nil {
+		t.Fatalf("suspend feed: %v", err)
+	}
+
+	p := New(st, &config.Config{PullInterval: 1800, PullTimeout: 5, PullConcurrency: 1, PullMaxBackoff: 604800}, nil)
+	queue := newFeedQueue()
+	queue.set(999, now) // deleted meanwhile
+	p.resync(queue, map[int64]bool{}, 10*time.Minute)
+
+	if len(queue.byID) != 2 {
+		t.Fatalf("expected 2 queued feeds, got %d", len(queue.byID))
+	}
+	if due := queue.byID[overdue.ID].dueAt; due < now || due > time.Now().Unix()+600 {
+		t.Fatalf("overdue feed due at %d, want within jitter of %d", due, now)
+	}
+	if due := queue.byID[later.ID].dueAt; due != now+7200 {
+		t.Fatalf("scheduled feed due at %d, want %d", due, now+7200)
+	}
+}
+
+func TestStartPullsDueFeedsAndWakesOnReschedule(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	var hitsA, hitsB int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			atomic.AddInt32(&hitsA, 1)
+		case "/b":
+			atomic.AddInt32(&hitsB, 1)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = fmt.Fprint(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>T</title></channel></rss>`)
+	}))
+	defer server.Close()
+
+	due, err := st.CreateFeed(1, "Due", server.URL+"/a", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	idle, err := st.CreateFeed(1, "Idle", server.URL+"/b", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	now := time.Now().Unix()
+	if err := st.UpdateFeedFetchSuccess(idle.ID, store.UpdateFeedFetchSuccessParams{CheckedAt: now, HTTPStatus: 200, NextCheckAt: now + 3600}); err != nil {
+		t.Fatalf("update fetch state: %v", err)
+	}
+
+	p := New(st, &config.Config{PullInterval: 1800, PullTimeout: 5, PullConcurrency: 2, PullMaxBackoff: 604800, AllowPrivateFeeds: true}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Start(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitFor("due feed pull", func() bool {
+		got, err := st.GetFeed(due.ID)
+		return err == nil && got.FetchState.NextCheckAt > now
+	})
+	if atomic.LoadInt32(&hitsB) != 0 {
+		t.Fatal("feed scheduled in an hour was pulled")
+	}
+
+	// Changing the link makes the feed due now; the wakeup must not wait for
+	// the hour-long timer or the periodic resync.
+	link := server.URL + "/b?v=2"
+	if err := st.UpdateFeed(idle.ID, store.UpdateFeedParams{Link: &link}); err != nil {
+		t.Fatalf("update feed: %v", err)
+	}
+	p.Reschedule(idle.ID)
+	waitFor("rescheduled feed pull", func() bool { return atomic.LoadInt32(&hitsB) == 1 })
+
+	if hits := atomic.LoadInt32(&hitsA); hits != 1 {
+		t.Fatalf("due feed pulled %d times, want once", hits)
+	}
+}
+
+func TestApplyScheduleKeepsWebSubFloor(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	polled, err := st.CreateFeed(1, "Polled", "https://example.com/a.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	pushed, err := st.CreateFeed(1, "Pushed", "https://example.com/b.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	checkedAt := time.Now().Unix() - 100
+	webSubDue := checkedAt + int64(webSubPollInterval.Seconds())
+	for _, id := range []int64{polled.ID, pushed.ID} {
+		if err := st.UpdateFeedFetchSuccess(id, store.UpdateFeedFetchSuccessParams{CheckedAt: checkedAt, HTTPStatus: 200, NextCheckAt: webSubDue}); err != nil {
+			t.Fatalf("update fetch state: %v", err)
+		}
+	}
+	if err := st.UpsertWebSubSubscription(pushed.ID, store.UpsertWebSubSubscriptionParams{
+		HubURL: "https://hub.example.com/", TopicURL: pushed.Link, Secret: "s", State: model.WebSubStatePending,
+	}); err != nil {
+		t.Fatalf("UpsertWebSubSubscription() failed: %v", err)
+	}
+	if err := st.ActivateWebSubSubscription(pushed.ID, 86400, time.Now().Unix()+86400); err != nil {
+		t.Fatalf("ActivateWebSubSubscription() failed: %v", err)
+	}
+
+	p := New(st, &config.Config{PullInterval: 1800, PullTimeout: 5, PullConcurrency: 1, PullMaxBackoff: 604800}, nil)
+	shorter := int64(300)
+	for _, id := range []int64{polled.ID, pushed.ID} {
+		if err := st.UpdateFeed(id, store.UpdateFeedParams{PullInterval: &shorter}); err != nil {
+			t.Fatalf("UpdateFeed() failed: %v", err)
+		}
+		if err := p.ApplySchedule(id); err != nil {
+			t.Fatalf("ApplySchedule() failed: %v", err)
+		}
+	}
+
+	got, err := st.GetFeedSchedule(polled.ID)
+	if err != nil {
+		t.Fatalf("GetFeedSchedule() failed: %v", err)
+	}
+	if got.FetchState.NextCheckAt != checkedAt+shorter {
+		t.Fatalf("polled feed next check = %d, want %d", got.FetchState.NextCheckAt, checkedAt+shorter)
+	}
+	got, err = st.GetFeedSchedule(pushed.ID)
+	if err != nil {
+		t.Fatalf("GetFeedSchedule() failed: %v", err)
+	}
+	if got.FetchState.NextCheckAt != webSubDue {
+		t.Fatalf("WebSub feed next check = %d, want %d", got.FetchState.NextCheckAt, webSubDue)
+	}
+}
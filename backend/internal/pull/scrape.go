@@ -0,0 +1,202 @@
+package pull
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/sanitize"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// scrapeDateLayouts are tried in order when a recipe has no DateLayout.
+var scrapeDateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"02 Jan 2006",
+}
+
+// ValidateScrapeRecipe checks that a recipe has item and title selectors and
+// that every selector compiles.
+func ValidateScrapeRecipe(recipe *model.ScrapeRecipe) error {
+	if recipe == nil {
+		return errors.New("scrape recipe is required")
+	}
+	if strings.TrimSpace(recipe.Item) == "" {
+		return errors.New("item selector is required")
+	}
+	if _, err := cascadia.Compile(recipe.Item); err != nil {
+		return fmt.Errorf("item selector: %w", err)
+	}
+	if strings.TrimSpace(recipe.Title) == "" {
+		return errors.New("title selector is required")
+	}
+
+	fields := []struct{ name, spec string }{
+		{"title", recipe.Title},
+		{"link", recipe.Link},
+		{"date", recipe.Date},
+		{"content", recipe.Content},
+	}
+	for _, field := range fields {
+		css, _ := splitSelector(field.spec)
+		if css == "" {
+			continue
+		}
+		if _, err := cascadia.Compile(css); err != nil {
+			return fmt.Errorf("%s selector: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+// Scrape extracts items from an HTML page with recipe. pageURL resolves
+// relative links and becomes the site URL. Only Items and SiteURL are set.
+func Scrape(r io.Reader, pageURL string, recipe *model.ScrapeRecipe) (*FetchResult, error) {
+	if recipe == nil {
+		return nil, errors.New("scrape recipe is missing")
+	}
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse page: %w", err)
+	}
+	baseURL, _ := url.Parse(pageURL)
+
+	now := time.Now().Unix()
+	items := []*ParsedItem{}
+	doc.Find(recipe.Item).Each(func(_ int, sel *goquery.Selection) {
+		title := collapseSpace(extractText(sel, recipe.Title))
+
+		linkSpec := recipe.Link
+		if linkSpec == "" {
+			linkSpec = "a@href"
+			if goquery.NodeName(sel) == "a" {
+				linkSpec = "@href"
+			}
+		} else if _, attr := splitSelector(linkSpec); attr == "" {
+			linkSpec += "@href"
+		}
+		link := resolveHTTPURL(extractText(sel, linkSpec), baseURL)
+		if title == "" && link == "" {
+			return
+		}
+
+		content := ""
+		if recipe.Content != "" {
+			// Relative URLs in scraped markup are relative to the page, not
+			// to the item link used when the item is stored.
+			content = sanitize.HTML(extractHTML(sel, recipe.Content), pageURL)
+		}
+
+		var sourcePubDate int64
+		hasSourcePubDate := false
+		if recipe.Date != "" {
+			sourcePubDate, hasSourcePubDate = parseScrapeDate(extractText(sel, recipe.Date), recipe.DateLayout)
+		}
+		pubDate := sourcePubDate
+		if !hasSourcePubDate {
+			pubDate = now
+		}
+
+		guid := link
+		if guid == "" {
+			guid = fallbackGUID(title, content, sourcePubDate, hasSourcePubDate)
+		}
+		items = append(items, &ParsedItem{
+			GUID:    guid,
+			Title:   title,
+			Link:    link,
+			Content: content,
+			PubDate: pubDate,
+		})
+	})
+
+	return &FetchResult{Items: items, SiteURL: normalizeSiteURL(pageURL)}, nil
+}
+
+// splitSelector splits "css@attr" into its parts. An "@" inside an attribute
+// selector such as a[href*="@"] is not a separator.
+func splitSelector(spec string) (string, string) {
+	spec = strings.TrimSpace(spec)
+	i := strings.LastIndex(spec, "@")
+	if i < 0 {
+		return spec, ""
+	}
+	attr := spec[i+1:]
+	if attr == "" || strings.ContainsAny(attr, "]\"' ") {
+		return spec, ""
+	}
+	return strings.TrimSpace(spec[:i]), attr
+}
+
+// selectTarget returns the first element matching css inside sel, or sel
+// itself when css is empty.
+func selectTarget(sel *goquery.Selection, css string) *goquery.Selection {
+	if css == "" {
+		return sel
+	}
+	return sel.Find(css).First()
+}
+
+// extractText returns the attribute or text content selected by spec.
+func extractText(sel *goquery.Selection, spec string) string {
+	css, attr := splitSelector(spec)
+	target := selectTarget(sel, css)
+	if target.Length() == 0 {
+		return ""
+	}
+	if attr != "" {
+		return strings.TrimSpace(target.AttrOr(attr, ""))
+	}
+	return strings.TrimSpace(target.Text())
+}
+
+// extractHTML returns the attribute or inner HTML selected by spec.
+func extractHTML(sel *goquery.Selection, spec string) string {
+	css, attr := splitSelector(spec)
+	if attr != "" {
+		return extractText(sel, spec)
+	}
+	target := selectTarget(sel, css)
+	if target.Length() == 0 {
+		return ""
+	}
+	content, err := target.Html()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(content)
+}
+
+func parseScrapeDate(value, layout string) (int64, bool) {
+	value = collapseSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	layouts := scrapeDateLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l, value); err == nil {
+			return t.Unix(), true
+		}
+	}
+	return 0, false
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
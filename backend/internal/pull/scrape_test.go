@@ -0,0 +1,133 @@
+package pull
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+const scrapePage = `<html><body>
+<div class="post">
+  <h2><a href="/posts/1">First   post</a></h2>
+  <time datetime="2024-03-01T10:00:00Z">March 1</time>
+  <div class="body"><p>Hello <img src="/img/a.png"></p><script>alert(1)</script></div>
+</div>
+<div class="post">
+  <h2>Second post</h2>
+  <span class="date">Mar 2, 2024</span>
+</div>
+<div class="post"><p>no title or link</p></div>
+</body></html>`
+
+func TestScrapeExtractsItems(t *testing.T) {
+	recipe := &model.ScrapeRecipe{
+		Item:    "div.post",
+		Title:   "h2",
+		Date:    "time@datetime",
+		Content: ".body",
+	}
+
+	result, err := Scrape(strings.NewReader(scrapePage), "https://example.com/blog", recipe)
+	if err != nil {
+		t.Fatalf("Scrape() failed: %v", err)
+	}
+	if result.SiteURL != "https://example.com/blog" {
+		t.Fatalf("SiteURL = %q", result.SiteURL)
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(result.Items))
+	}
+
+	first := result.Items[0]
+	if first.Title != "First post" || first.Link != "https://example.com/posts/1" || first.GUID != first.Link {
+		t.Fatalf("unexpected first item: %+v", first)
+	}
+	if first.PubDate != time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC).Unix() {
+		t.Fatalf("first PubDate = %d", first.PubDate)
+	}
+	if !strings.Contains(first.Content, `src="https://example.com/img/a.png"`) || strings.Contains(first.Content, "script") {
+		t.Fatalf("content not resolved and sanitized: %q", first.Content)
+	}
+
+	second := result.Items[1]
+	if second.Link != "" || !strings.HasPrefix(second.GUID, "generated:") {
+		t.Fatalf("expected generated guid without link, got %+v", second)
+	}
+}
+
+func TestScrapeDateLayout(t *testing.T) {
+	recipe := &model.ScrapeRecipe{Item: "div.post", Title: "h2", Date: ".date", DateLayout: "Jan 2, 2006"}
+
+	result, err := Scrape(strings.NewReader(scrapePage), "https://example.com/blog", recipe)
+	if err != nil {
+		t.Fatalf("Scrape() failed: %v", err)
+	}
+	if got, want := result.Items[1].PubDate, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC).Unix(); got != want {
+		t.Fatalf("PubDate = %d, want %d", got, want)
+	}
+}
+
+func TestSplitSelector(t *testing.T) {
+	tests := []struct {
+		spec, css, attr string
+	}{
+		{"h2 a", "h2 a", ""},
+		{"a@href", "a", "href"},
+		{"@data-id", "", "data-id"},
+		{`a[href*="@"]`, `a[href*="@"]`, ""},
+	}
+	for _, tt := range tests {
+		css, attr := splitSelector(tt.spec)
+		if css != tt.css || attr != tt.attr {
+			t.Errorf("splitSelector(%q) = %q, %q; want %q, %q", tt.spec, css, attr, tt.css, tt.attr)
+		}
+	}
+}
+
+func TestValidateScrapeRecipe(t *testing.T) {
+	if err := ValidateScrapeRecipe(&model.ScrapeRecipe{Item: "div.post", Title: "h2", Link: "a@href"}); err != nil {
+		t.Fatalf("valid recipe rejected: %v", err)
+	}
+	invalid := []*model.ScrapeRecipe{
+		nil,
+		{Title: "h2"},
+		{Item: "div.post"},
+		{Item: "div[", Title: "h2"},
+		{Item: "div.post", Title: "h2", Date: "time[@datetime"},
+	}
+	for _, recipe := range invalid {
+		if err := ValidateScrapeRecipe(recipe); err == nil {
+			t.Errorf("expected error for %+v", recipe)
+		}
+	}
+}
+
+func TestFetchAndParseScrapesPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("ETag", `"page"`)
+		_, _ = w.Write([]byte(scrapePage))
+	}))
+	defer server.Close()
+
+	feed := &model.Feed{
+		Link:   server.URL + "/blog",
+		Kind:   model.FeedKindScrape,
+		Scrape: &model.ScrapeRecipe{Item: "div.post", Title: "h2"},
+	}
+	result, err := FetchAndParse(context.Background(), feed, 5*time.Second, true)
+	if err != nil {
+		t.Fatalf("FetchAndParse() failed: %v", err)
+	}
+	if len(result.Items) != 2 || result.Items[0].Link != server.URL+"/posts/1" {
+		t.Fatalf("unexpected items: %+v", result.Items)
+	}
+	if result.ETag != `"page"` {
+		t.Fatalf("ETag = %q", result.ETag)
+	}
+}
@@ -0,0 +1,184 @@
+package pull
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// webSubPollInterval is the safety-net polling interval for feeds with an
+// active WebSub subscription.
+const webSubPollInterval = 24 * time.Hour
+
+// discoverWebSubLinks returns the hub and self URLs advertised by a feed.
+// HTTP Link headers take precedence over <link rel="hub|self"> elements in
+// the document, as required by the WebSub spec. Relative URLs are resolved
+// against feedLink.
+func discoverWebSubLinks(header http.Header, body []byte, feedLink string) (hubURL, selfURL string) {
+	hubURL, selfURL = parseLinkHeader(header.Values("Link"))
+	if hubURL == "" || selfURL == "" {
+		docHub, docSelf := parseDocumentLinks(body)
+		if hubURL == "" {
+			hubURL = docHub
+		}
+		if selfURL == "" {
+			selfURL = docSelf
+		}
+	}
+
+	return resolveWebSubURL(hubURL, feedLink), resolveWebSubURL(selfURL, feedLink)
+}
+
+// parseLinkHeader extracts rel="hub" and rel="self" targets from RFC 8288
+// Link header values.
+func parseLinkHeader(values []string) (hubURL, selfURL string) {
+	for _, value := range values {
+		for link := range strings.SplitSeq(value, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok {
+				continue
+			}
+			target = strings.TrimSpace(target)
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			target = target[1 : len(target)-1]
+
+			for param := range strings.SplitSeq(params, ";") {
+				key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for rel := range strings.FieldsSeq(strings.Trim(strings.TrimSpace(val), `"`)) {
+					switch strings.ToLower(rel) {
+					case "hub":
+						if hubURL == "" {
+							hubURL = target
+						}
+					case "self":
+						if selfURL == "" {
+							selfURL = target
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return hubURL, selfURL
+}
+
+// parseDocumentLinks scans feed-level <link> elements (atom:link in RSS, link
+// in Atom) until the first item or entry.
+func parseDocumentLinks(body []byte) (hubURL, selfURL string) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return hubURL, selfURL
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(start.Name.Local) {
+		case "item", "entry":
+			return hubURL, selfURL
+		case "link":
+			var rel, href string
+			for _, attr := range start.Attr {
+				switch strings.ToLower(attr.Name.Local) {
+				case "rel":
+					rel = strings.ToLower(strings.TrimSpace(attr.Value))
+				case "href":
+					href = strings.TrimSpace(attr.Value)
+				}
+			}
+			if rel == "hub" && hubURL == "" {
+				hubURL = href
+			}
+			if rel == "self" && selfURL == "" {
+				selfURL = href
+			}
+		}
+	}
+}
+
+func resolveWebSubURL(raw, feedLink string) string {
+	if raw == "" {
+		return ""
+	}
+
+	base, err := url.Parse(feedLink)
+	if err != nil {
+		return ""
+	}
+	resolved, err := base.Parse(raw)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
+	}
+
+	return resolved.String()
+}
+
+// ensureWebSub subscribes the feed to its advertised hub. The topic is the
+// feed's self URL, falling back to the polled link.
+func (p *Puller) ensureWebSub(ctx context.Context, feed *model.Feed, result *FetchResult, now int64) {
+	if p.websub == nil || result.HubURL == "" {
+		return
+	}
+
+	topicURL := result.SelfURL
+	if topicURL == "" {
+		topicURL = feed.Link
+	}
+
+	if err := p.websub.Ensure(ctx, feed.ID, result.HubURL, topicURL, now); err != nil {
+		p.logger.Warn("websub subscription failed, polling continues", "feed_id", feed.ID, "hub", result.HubURL, "error", err)
+	}
+}
+
+// IngestPayload stores items from a feed document pushed by a WebSub hub,
+// using the same parsing and insert path as regular pulls.
+func (p *Puller) IngestPayload(ctx context.Context, feedID int64, body []byte) (int, error) {
+	feed, err := p.store.GetFeed(feedID)
+	if err != nil {
+		return 0, err
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	result, err := ParseFeed(bytes.NewReader(body), feed)
+	if err != nil {
+		return 0, err
+	}
+
+	inputs, err := p.ingestInputs(feed, result.Items)
+	if err != nil {
+		return 0, err
+	}
+	upserted, err := p.store.BatchUpsertItems(feed.ID, feed.UpdateMode, inputs)
+	if err != nil {
+		return 0, err
+	}
+
+	if upserted.Created > 0 {
+		p.publishNewItems(feed.ID, upserted.Created, "websub")
+	}
+
+	p.logger.Info("websub payload ingested", "feed_id", feed.ID, "feed_name", feed.Name, "new_items", upserted.Created, "updated_items", upserted.Updated, "dropped_items", upserted.Dropped)
+	return upserted.Created, nil
+}
@@ -0,0 +1,144 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+	"github.com/0x2E/fusion/internal/websub"
+)
+
+func TestDiscoverWebSubLinks(t *testing.T) {
+	body := []byte(`<?xml version="1.0"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>
+<atom:link rel="hub" href="https://doc-hub.example.com/"/>
+<atom:link rel="self" href="/feed.xml"/>
+<item><atom:link rel="hub" href="https://ignored.example.com/"/></item>
+</channel></rss>`)
+
+	hub, self := discoverWebSubLinks(http.Header{}, body, "https://example.com/rss")
+	if hub != "https://doc-hub.example.com/" || self != "https://example.com/feed.xml" {
+		t.Fatalf("document links: hub=%q self=%q", hub, self)
+	}
+
+	header := http.Header{}
+	header.Add("Link", `<https://header-hub.example.com/>; rel="hub", <https://example.com/canonical>; rel="self"`)
+	hub, self = discoverWebSubLinks(header, body, "https://example.com/rss")
+	if hub != "https://header-hub.example.com/" || self != "https://example.com/canonical" {
+		t.Fatalf("header links should take precedence: hub=%q self=%q", hub, self)
+	}
+
+	hub, self = discoverWebSubLinks(http.Header{}, []byte(`<feed xmlns="http://www.w3.org/2005/Atom"><title>No hub</title></feed>`), "https://example.com/atom")
+	if hub != "" || self != "" {
+		t.Fatalf("expected no links, got hub=%q self=%q", hub, self)
+	}
+}
+
+func TestRefreshFeedSubscribesToAdvertisedHub(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	var hubRequests atomic.Int32
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hubRequests.Add(1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer hub.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="hub"`, hub.URL))
+		_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Demo</title>
+<item><guid>g1</guid><title>Item</title></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	feed, err := st.CreateFeed(1, "Feed", server.URL, "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	cfg := &config.Config{
+		PullInterval:      1800,
+		PullTimeout:       5,
+		PullConcurrency:   1,
+		PullMaxBackoff:    604800,
+		AllowPrivateFeeds: true,
+		PublicURL:         "https://reader.example.com",
+	}
+	p := New(st, cfg, websub.New(st, cfg))
+	if err := p.RefreshFeed(context.Background(), feed.ID); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	if hubRequests.Load() != 1 {
+		t.Fatalf("expected 1 hub request, got %d", hubRequests.Load())
+	}
+	sub, err := st.GetWebSubSubscription(feed.ID)
+	if err != nil {
+		t.Fatalf("GetWebSubSubscription() failed: %v", err)
+	}
+	if sub.State != model.WebSubStatePending || sub.TopicURL != server.URL {
+		t.Fatalf("unexpected subscription: state=%s topic=%q", sub.State, sub.TopicURL)
+	}
+
+	// Once the hub verified the subscription, polling only runs as a safety net.
+	if err := st.ActivateWebSubSubscription(feed.ID, 86400, time.Now().Unix()+86400); err != nil {
+		t.Fatalf("ActivateWebSubSubscription() failed: %v", err)
+	}
+	if err := p.RefreshFeed(context.Background(), feed.ID); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	got, err := st.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if delay := got.FetchState.NextCheckAt - got.FetchState.LastCheckedAt; delay != int64(webSubPollInterval.Seconds()) {
+		t.Fatalf("next check delay = %d, want %d", delay, int64(webSubPollInterval.Seconds()))
+	}
+}
+
+func TestIngestPayloadStoresPushedItems(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/feed.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	p := New(st, &config.Config{PullInterval: 1800, PullTimeout: 5, PullConcurrency: 1, PullMaxBackoff: 604800}, nil)
+	payload := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Demo</title>
+<entry><id>urn:1</id><title>Pushed</title><link href="/posts/1"/><updated>2026-01-02T03:04:05Z</updated></entry>
+</feed>`)
+
+	for range 2 {
+		if _, err := p.IngestPayload(context.Background(), feed.ID, payload); err != nil {
+			t.Fatalf("IngestPayload() failed: %v", err)
+		}
+	}
+
+	items, err := st.ListItems(store.ListItemsParams{FeedID: &feed.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListItems() failed: %v", err)
+	}
+	if len(items) != 1 || items[0].GUID != "urn:1" || items[0].Link != "https://example.com/posts/1" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+}
@@ -0,0 +1,79 @@
+package pullpolicy
+
+import (
+	"slices"
+)
+
+const (
+	// minPublishSamples is the number of item timestamps needed before a
+	// publish interval is trusted.
+	minPublishSamples = 3
+	// notModifiedWeight is the smoothing factor of the unchanged-response ratio.
+	notModifiedWeight = 0.2
+	// idleDivisor slows down feeds that went quiet: a feed idle for N seconds
+	// is checked at most every N/idleDivisor seconds.
+	idleDivisor = 4
+)
+
+// PublishIntervalSeconds returns the median gap between consecutive item
+// timestamps, or 0 when there are too few samples. Order does not matter.
+func PublishIntervalSeconds(publishedAt []int64) int64 {
+	if len(publishedAt) < minPublishSamples {
+		return 0
+	}
+
+	sorted := slices.Clone(publishedAt)
+	slices.Sort(sorted)
+
+	gaps := make([]int64, 0, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		gaps = append(gaps, sorted[i]-sorted[i-1])
+	}
+	slices.Sort(gaps)
+
+	return gaps[len(gaps)/2]
+}
+
+// UpdateNotModifiedRatio folds one check into the exponentially smoothed
+// share of checks that returned no new content (304 or no new items).
+func UpdateNotModifiedRatio(prev float64, unchanged bool) float64 {
+	sample := 0.0
+	if unchanged {
+		sample = 1
+	}
+
+	return prev*(1-notModifiedWeight) + sample*notModifiedWeight
+}
+
+// AdaptiveIntervalSeconds derives a polling interval from observed publish
+// behavior, bounded by [minSeconds, maxSeconds]:
+//   - half the median publish gap, so a new item waits at most half a period
+//     (fallbackSeconds when the gap is unknown);
+//   - at least a quarter of the time since the newest item, so dormant feeds
+//     back off;
+//   - stretched by up to 2x as the unchanged-response ratio approaches 1.
+func AdaptiveIntervalSeconds(
+	now int64,
+	publishIntervalSeconds int64,
+	lastPublishedAt int64,
+	notModifiedRatio float64,
+	fallbackSeconds int64,
+	minSeconds int64,
+	maxSeconds int64,
+) int64 {
+	interval := fallbackSeconds
+	if publishIntervalSeconds > 0 {
+		interval = publishIntervalSeconds / 2
+	}
+
+	if lastPublishedAt > 0 && lastPublishedAt < now {
+		if idle := (now - lastPublishedAt) / idleDivisor; idle > interval {
+			interval = idle
+		}
+	}
+
+	ratio := min(max(notModifiedRatio, 0), 1)
+	interval = int64(float64(interval) * (1 + ratio))
+
+	return min(max(interval, minSeconds), maxSeconds)
+}
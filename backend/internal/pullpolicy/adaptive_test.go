@@ -0,0 +1,56 @@
+package pullpolicy
+
+import "testing"
+
+func TestPublishIntervalSecondsUsesMedianGap(t *testing.T) {
+	if got := PublishIntervalSeconds([]int64{100, 200}); got != 0 {
+		t.Fatalf("PublishIntervalSeconds() with too few samples = %d, want 0", got)
+	}
+
+	// Gaps: 3600, 3600, 7200, 100000 -> median 7200 (upper middle).
+	got := PublishIntervalSeconds([]int64{114400, 0, 3600, 14400, 7200})
+	if got != 7200 {
+		t.Fatalf("PublishIntervalSeconds() = %d, want 7200", got)
+	}
+}
+
+func TestAdaptiveIntervalSeconds(t *testing.T) {
+	now := int64(1_000_000)
+	tests := []struct {
+		name            string
+		publishInterval int64
+		lastPublishedAt int64
+		ratio           float64
+		want            int64
+	}{
+		{name: "half publish gap", publishInterval: 7200, lastPublishedAt: now - 60, want: 3600},
+		{name: "clamped to min", publishInterval: 60, lastPublishedAt: now, want: 300},
+		{name: "unknown history falls back", want: 1800},
+		{name: "dormant feed backs off", publishInterval: 7200, lastPublishedAt: now - 40000, want: 10000},
+		{name: "unchanged responses stretch interval", publishInterval: 7200, lastPublishedAt: now, ratio: 0.5, want: 5400},
+		{name: "clamped to max", publishInterval: 7200, lastPublishedAt: now - 900000, ratio: 1, want: 86400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AdaptiveIntervalSeconds(now, tt.publishInterval, tt.lastPublishedAt, tt.ratio, 1800, 300, 86400)
+			if got != tt.want {
+				t.Fatalf("AdaptiveIntervalSeconds() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateNotModifiedRatio(t *testing.T) {
+	ratio := 0.0
+	for range 20 {
+		ratio = UpdateNotModifiedRatio(ratio, true)
+	}
+	if ratio < 0.95 || ratio > 1 {
+		t.Fatalf("ratio after unchanged checks = %f, want close to 1", ratio)
+	}
+
+	if got := UpdateNotModifiedRatio(1, false); got != 0.8 {
+		t.Fatalf("UpdateNotModifiedRatio(1, false) = %f, want 0.8", got)
+	}
+}
@@ -18,21 +18,19 @@ type FeedRuntimeState struct {
 	LastCheckedAt       int64
 }
 
+// ShouldSkip reports whether a feed must not be pulled at now.
 func ShouldSkip(now int64, state FeedRuntimeState, interval, maxBackoff time.Duration) bool {
-	if state.Suspended {
-		return true
-	}
-
-	if state.RetryAfterUntil > now {
-		return true
-	}
-
-	if state.NextCheckAt > now {
-		return true
-	}
+	return state.Suspended || now < DueAt(state, interval, maxBackoff)
+}
 
+// DueAt returns the earliest Unix time a feed may be pulled again, ignoring
+// suspension. Feeds with next_check_at use it (delayed by Retry-After); older
+// rows without it fall back to the failure backoff and the interval since the
+// last check.
+func DueAt(state FeedRuntimeState, interval, maxBackoff time.Duration) int64 {
+	due := max(state.RetryAfterUntil, state.NextCheckAt)
 	if state.NextCheckAt > 0 {
-		return false
+		return due
 	}
 
 	if state.ConsecutiveFailures > 0 {
@@ -41,17 +39,10 @@ func ShouldSkip(now int64, state FeedRuntimeState, interval, maxBackoff time.Dur
 		if base <= 0 {
 			base = state.LastCheckedAt
 		}
-		nextPull := base + int64(backoff.Seconds())
-		if now < nextPull {
-			return true
-		}
-	}
-
-	if now-state.LastCheckedAt < int64(interval.Seconds()) {
-		return true
+		due = max(due, base+int64(backoff.Seconds()))
 	}
 
-	return false
+	return max(due, state.LastCheckedAt+int64(interval.Seconds()))
 }
 
 func CalculateBackoff(interval time.Duration, failures int64, maxBackoff time.Duration) time.Duration {
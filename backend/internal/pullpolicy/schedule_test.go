@@ -208,3 +208,32 @@ func TestShouldSkip(t *testing.T) {
 		})
 	}
 }
+
+func TestDueAt(t *testing.T) {
+	interval := 30 * time.Minute
+	maxBackoff := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name  string
+		state FeedRuntimeState
+		want  int64
+	}{
+		{name: "never pulled", state: FeedRuntimeState{}, want: 1800},
+		{name: "next_check_at", state: FeedRuntimeState{NextCheckAt: 5000, LastCheckedAt: 4000}, want: 5000},
+		{name: "retry-after beyond next_check_at", state: FeedRuntimeState{NextCheckAt: 5000, RetryAfterUntil: 9000}, want: 9000},
+		{name: "legacy interval", state: FeedRuntimeState{LastCheckedAt: 1000}, want: 2800},
+		{
+			name:  "legacy backoff",
+			state: FeedRuntimeState{LastCheckedAt: 1000, LastErrorAt: 1000, ConsecutiveFailures: 1},
+			want:  1000 + int64(CalculateBackoff(interval, 1, maxBackoff).Seconds()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DueAt(tt.state, interval, maxBackoff); got != tt.want {
+				t.Errorf("DueAt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
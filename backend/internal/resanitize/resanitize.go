@@ -0,0 +1,115 @@
+// Package resanitize brings stored item and bookmark HTML up to the current
+// sanitize policy. Rows record the policy version they were cleaned with, so
+// a policy change (or an upgrade from a release without server-side
+// sanitization) is applied once in the background.
+package resanitize
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/0x2E/fusion/internal/pkg/sanitize"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+// batchSize bounds each read so the job does not hold large result sets.
+const batchSize = 200
+
+type Job struct {
+	store  *store.Store
+	logger *slog.Logger
+}
+
+func New(st *store.Store) *Job {
+	return &Job{
+		store:  st,
+		logger: slog.Default(),
+	}
+}
+
+// Start re-sanitizes outdated rows once and returns. New content is sanitized
+// on ingest, so nothing is left to do until the policy version changes.
+func (j *Job) Start(ctx context.Context) error {
+	items, bookmarks, err := j.Run(ctx)
+	if err != nil {
+		return err
+	}
+	if items > 0 || bookmarks > 0 {
+		j.logger.Info("re-sanitized stored content", "policy_version", sanitize.PolicyVersion, "items", items, "bookmarks", bookmarks)
+	}
+
+	return nil
+}
+
+// Run re-sanitizes every item and bookmark below sanitize.PolicyVersion.
+// Returns the number of updated items and bookmarks.
+func (j *Job) Run(ctx context.Context) (int, int, error) {
+	items, err := j.runItems(ctx)
+	if err != nil {
+		return items, 0, err
+	}
+
+	bookmarks, err := j.runBookmarks(ctx)
+	return items, bookmarks, err
+}
+
+func (j *Job) runItems(ctx context.Context) (int, error) {
+	updated := 0
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return updated, err
+		}
+
+		items, err := j.store.ListItemsBelowSanitizeVersion(sanitize.PolicyVersion, afterID, batchSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(items) == 0 {
+			return updated, nil
+		}
+
+		for _, item := range items {
+			afterID = item.ID
+			if err := j.store.UpdateItemSanitized(
+				item.ID,
+				sanitize.HTML(item.Content, item.Link),
+				sanitize.HTML(item.Summary, item.Link),
+				sanitize.HTML(item.ReadableContent, item.Link),
+				sanitize.PolicyVersion,
+			); err != nil {
+				// The item may have been pruned meanwhile; keep going.
+				j.logger.Warn("failed to re-sanitize item", "item_id", item.ID, "error", err)
+				continue
+			}
+			updated++
+		}
+	}
+}
+
+func (j *Job) runBookmarks(ctx context.Context) (int, error) {
+	updated := 0
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return updated, err
+		}
+
+		bookmarks, err := j.store.ListBookmarksBelowSanitizeVersion(sanitize.PolicyVersion, afterID, batchSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(bookmarks) == 0 {
+			return updated, nil
+		}
+
+		for _, bookmark := range bookmarks {
+			afterID = bookmark.ID
+			if err := j.store.UpdateBookmarkSanitized(bookmark.ID, sanitize.HTML(bookmark.Content, bookmark.Link), sanitize.PolicyVersion); err != nil {
+				j.logger.Warn("failed to re-sanitize bookmark", "bookmark_id", bookmark.ID, "error", err)
+				continue
+			}
+			updated++
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package resanitize
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/pkg/sanitize"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+func TestRunUpgradesOutdatedContent(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/feed.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	legacy, err := st.CreateItem(feed.ID, "a", "A", "https://example.com/posts/a", `<p onclick="x()">a</p><script>x()</script><img src="/a.png">`, 100)
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	bookmark, err := st.CreateBookmark(nil, nil, "https://example.com/posts/b", "B", `<p>b<script>x()</script></p>`, 100, feed.Name, 0)
+	if err != nil {
+		t.Fatalf("create bookmark: %v", err)
+	}
+	current, err := st.CreateBookmark(nil, nil, "https://example.com/posts/c", "C", `<p>kept as is</p><!-- current -->`, 100, feed.Name, sanitize.PolicyVersion)
+	if err != nil {
+		t.Fatalf("create bookmark: %v", err)
+	}
+
+	job := New(st)
+	items, bookmarks, err := job.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if items != 1 || bookmarks != 1 {
+		t.Fatalf("Run() = %d items, %d bookmarks; want 1, 1", items, bookmarks)
+	}
+
+	gotItem, err := st.GetItem(legacy.ID)
+	if err != nil {
+		t.Fatalf("get item: %v", err)
+	}
+	if want := `<p>a</p><img src="https://example.com/a.png"/>`; gotItem.Content != want {
+		t.Fatalf("item content = %q, want %q", gotItem.Content, want)
+	}
+	gotBookmark, err := st.GetBookmark(bookmark.ID)
+	if err != nil {
+		t.Fatalf("get bookmark: %v", err)
+	}
+	if gotBookmark.Content != "<p>b</p>" {
+		t.Fatalf("bookmark content = %q", gotBookmark.Content)
+	}
+	gotCurrent, err := st.GetBookmark(current.ID)
+	if err != nil {
+		t.Fatalf("get bookmark: %v", err)
+	}
+	if gotCurrent.Content != current.Content {
+		t.Fatalf("up-to-date bookmark was rewritten: %q", gotCurrent.Content)
+	}
+
+	// A second run finds nothing left to do.
+	items, bookmarks, err = job.Run(context.Background())
+	if err != nil || items != 0 || bookmarks != 0 {
+		t.Fatalf("second Run() = %d, %d, %v", items, bookmarks, err)
+	}
+}
@@ -0,0 +1,146 @@
+// Package retention periodically prunes old read items according to the
+// global retention policy and per-feed overrides.
+package retention
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+// Storage is compacted once optimizeMinPruned items were pruned since the last
+// compaction, or after optimizeInterval when anything was pruned at all.
+const (
+	optimizeMinPruned = 1000
+	optimizeInterval  = 7 * 24 * time.Hour
+)
+
+type Pruner struct {
+	store    *store.Store
+	config   *config.Config
+	logger   *slog.Logger
+	interval time.Duration
+
+	// Items pruned since the last compaction, and when that was.
+	pendingPruned int
+	lastOptimize  time.Time
+}
+
+func New(st *store.Store, cfg *config.Config) *Pruner {
+	return &Pruner{
+		store:        st,
+		config:       cfg,
+		logger:       slog.Default(),
+		interval:     time.Duration(cfg.RetentionInterval) * time.Second,
+		lastOptimize: time.Now(),
+	}
+}
+
+// Start runs retention periodically. Blocks until context is cancelled.
+func (p *Pruner) Start(ctx context.Context) error {
+	p.logger.Info("retention service started", "interval", p.interval, "days", p.config.RetentionDays, "max_items", p.config.RetentionMaxItems)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("retention service stopping")
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := p.PruneAll(ctx, time.Now()); err != nil {
+				p.logger.Error("retention run failed", "error", err)
+			}
+		}
+	}
+}
+
+// PruneAll applies the effective policy to every feed and compacts the
+// database when enough was deleted (see shouldOptimize). Returns the number of
+// pruned items.
+func (p *Pruner) PruneAll(ctx context.Context, now time.Time) (int, error) {
+	feeds, err := p.store.ListFeeds()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, feed := range feeds {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		params := p.policyFor(feed, now)
+		pruned, err := p.store.PruneFeedItems(feed.ID, params)
+		if err != nil {
+			p.logger.Error("failed to prune feed items", "feed_id", feed.ID, "error", err)
+			continue
+		}
+		if pruned > 0 {
+			p.logger.Debug("pruned feed items", "feed_id", feed.ID, "feed_name", feed.Name, "pruned", pruned)
+		}
+		total += pruned
+	}
+
+	p.pendingPruned += total
+	if !p.shouldOptimize(now) {
+		if total > 0 {
+			p.logger.Info("retention run completed", "pruned", total)
+		}
+		return total, nil
+	}
+
+	start := time.Now()
+	if err := p.store.OptimizeStorage(); err != nil {
+		return total, err
+	}
+	p.pendingPruned = 0
+	p.lastOptimize = now
+	p.logger.Info("retention run completed", "pruned", total, "optimize_duration", time.Since(start))
+
+	return total, nil
+}
+
+// shouldOptimize reports whether the FTS merge and VACUUM are worth their
+// exclusive lock: after a large prune, or periodically after small ones.
+func (p *Pruner) shouldOptimize(now time.Time) bool {
+	if p.pendingPruned == 0 {
+		return false
+	}
+	return p.pendingPruned >= optimizeMinPruned || now.Sub(p.lastOptimize) >= optimizeInterval
+}
+
+// policyFor resolves per-feed overrides against the global defaults.
+func (p *Pruner) policyFor(feed *model.Feed, now time.Time) store.PruneFeedItemsParams {
+	days := effectiveLimit(feed.RetentionDays, int64(p.config.RetentionDays))
+	maxItems := effectiveLimit(feed.RetentionMaxItems, int64(p.config.RetentionMaxItems))
+
+	params := store.PruneFeedItemsParams{
+		KeepNewest: maxItems,
+		PrunedAt:   now.Unix(),
+	}
+	if days > 0 {
+		params.Before = now.Add(-time.Duration(days) * 24 * time.Hour).Unix()
+	}
+	// Pulls mark tombstones of GUIDs still listed upstream as seen, so only
+	// items gone from the feed for this long can expire.
+	if tombstoneDays := p.config.RetentionTombstoneDays; tombstoneDays > 0 {
+		params.TombstonesBefore = now.Add(-time.Duration(tombstoneDays) * 24 * time.Hour).Unix()
+	}
+
+	return params
+}
+
+// effectiveLimit returns the feed override unless it is negative (inherit).
+func effectiveLimit(override, global int64) int64 {
+	if override < 0 {
+		return global
+	}
+
+	return override
+}
@@ -0,0 +1,115 @@
+package retention
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+func TestPruneAllHonorsPerFeedOverrides(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	now := time.Unix(100*86400, 0)
+	old := now.Add(-30 * 24 * time.Hour).Unix()
+
+	inherit, err := st.CreateFeed(1, "Inherit", "https://example.com/a.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	keepForever, err := st.CreateFeed(1, "Keep forever", "https://example.com/b.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	zero := int64(0)
+	if err := st.UpdateFeed(keepForever.ID, store.UpdateFeedParams{RetentionDays: &zero}); err != nil {
+		t.Fatalf("update feed: %v", err)
+	}
+
+	inheritItem, err := st.CreateItem(inherit.ID, "a", "A", "https://example.com/a", "", old)
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	keptItem, err := st.CreateItem(keepForever.ID, "b", "B", "https://example.com/b", "", old)
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	if err := st.BatchUpdateItemsUnread([]int64{inheritItem.ID, keptItem.ID}, false); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+
+	p := New(st, &config.Config{RetentionDays: 7, RetentionInterval: 86400})
+	pruned, err := p.PruneAll(context.Background(), now)
+	if err != nil {
+		t.Fatalf("PruneAll() failed: %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("expected 1 pruned item, got %d", pruned)
+	}
+	if _, err := st.GetItem(inheritItem.ID); err == nil {
+		t.Error("expected item of inheriting feed to be pruned")
+	}
+	if _, err := st.GetItem(keptItem.ID); err != nil {
+		t.Errorf("expected item of keep-forever feed to survive: %v", err)
+	}
+}
+
+func TestPolicyForTombstoneHorizon(t *testing.T) {
+	now := time.Unix(1000*86400, 0)
+	p := New(nil, &config.Config{RetentionDays: 30, RetentionTombstoneDays: 45, RetentionInterval: 86400})
+
+	// The horizon counts from the last time a pull saw the GUID, so it does
+	// not depend on the feed's retention age.
+	want := now.Add(-45 * 24 * time.Hour).Unix()
+	for _, feed := range []*model.Feed{
+		{RetentionDays: -1, RetentionMaxItems: -1},
+		{RetentionDays: 0, RetentionMaxItems: 10},
+	} {
+		if got := p.policyFor(feed, now).TombstonesBefore; got != want {
+			t.Errorf("retention_days=%d: TombstonesBefore = %d, want %d", feed.RetentionDays, got, want)
+		}
+	}
+}
+
+func TestShouldOptimize(t *testing.T) {
+	now := time.Now()
+	p := &Pruner{lastOptimize: now}
+
+	if p.shouldOptimize(now) {
+		t.Error("optimized without pruned items")
+	}
+	p.pendingPruned = 10
+	if p.shouldOptimize(now.Add(time.Hour)) {
+		t.Error("optimized after a small prune")
+	}
+	if !p.shouldOptimize(now.Add(optimizeInterval)) {
+		t.Error("small prunes were never optimized")
+	}
+	p.pendingPruned = optimizeMinPruned
+	if !p.shouldOptimize(now) {
+		t.Error("large prune was not optimized")
+	}
+}
+
+func TestEffectiveLimit(t *testing.T) {
+	tests := []struct {
+		override, global, want int64
+	}{
+		{override: -1, global: 30, want: 30},
+		{override: 0, global: 30, want: 0},
+		{override: 7, global: 30, want: 7},
+	}
+	for _, tt := range tests {
+		if got := effectiveLimit(tt.override, tt.global); got != tt.want {
+			t.Errorf("effectiveLimit(%d, %d) = %d, want %d", tt.override, tt.global, got, tt.want)
+		}
+	}
+}
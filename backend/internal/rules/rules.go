@@ -0,0 +1,202 @@
+// Package rules evaluates ingest rules against items. It is pure: loading
+// rules and applying outcomes is left to the store and puller.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/0x2E/fusion/internal/model"
+	"golang.org/x/net/html"
+)
+
+// maxPatternLength bounds patterns, which are matched against every new item.
+const maxPatternLength = 1024
+
+// Fields are the item values rules match on. Content is HTML; rules see its
+// text only, so markup never matches.
+type Fields struct {
+	Title   string
+	Content string
+	Link    string
+	Author  string
+}
+
+// Outcome is the combined effect of the rules matching an item. RuleIDs lists
+// every matching rule in evaluation order.
+type Outcome struct {
+	Drop     bool
+	Read     bool
+	Bookmark bool
+	// GroupID is the target of the first matching move rule.
+	GroupID int64
+	RuleIDs []int64
+}
+
+// Matched reports whether any rule matched.
+func (o Outcome) Matched() bool {
+	return len(o.RuleIDs) > 0
+}
+
+// Validate checks a rule definition before it is stored.
+func Validate(rule *model.Rule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return errors.New("name is required")
+	}
+	if rule.FeedID != 0 && rule.GroupID != 0 {
+		return errors.New("feed_id and group_id are mutually exclusive")
+	}
+	switch rule.Field {
+	case model.RuleFieldTitle, model.RuleFieldContent, model.RuleFieldLink, model.RuleFieldAuthor, model.RuleFieldAny:
+	default:
+		return fmt.Errorf("unknown field %q", rule.Field)
+	}
+	if rule.Pattern == "" {
+		return errors.New("pattern is required")
+	}
+	if len(rule.Pattern) > maxPatternLength {
+		return fmt.Errorf("pattern exceeds %d bytes", maxPatternLength)
+	}
+	switch rule.MatchType {
+	case model.RuleMatchContains:
+	case model.RuleMatchRegex:
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown match_type %q", rule.MatchType)
+	}
+	switch rule.Action {
+	case model.RuleActionDrop, model.RuleActionRead, model.RuleActionBookmark:
+		if rule.TargetGroupID != 0 {
+			return errors.New("target_group_id is only valid for move")
+		}
+	case model.RuleActionMove:
+		if rule.TargetGroupID == 0 {
+			return errors.New("target_group_id is required for move")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", rule.Action)
+	}
+	return nil
+}
+
+type compiledRule struct {
+	rule   *model.Rule
+	needle string
+	re     *regexp.Regexp
+}
+
+// Set is a compiled list of rules, evaluated in order.
+type Set struct {
+	rules []compiledRule
+}
+
+// Compile prepares rules for evaluation. Disabled rules are skipped.
+func Compile(rules []*model.Rule) (*Set, error) {
+	set := &Set{rules: make([]compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		c := compiledRule{rule: rule}
+		if rule.MatchType == model.RuleMatchRegex {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", rule.ID, err)
+			}
+			c.re = re
+		} else {
+			c.needle = strings.ToLower(rule.Pattern)
+		}
+		set.rules = append(set.rules, c)
+	}
+	return set, nil
+}
+
+// Len returns the number of enabled rules in the set.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
+
+// Evaluate applies every rule to fields. A matching drop rule makes the other
+// actions irrelevant but later matches are still reported.
+func (s *Set) Evaluate(fields Fields) Outcome {
+	var outcome Outcome
+	if s.Len() == 0 {
+		return outcome
+	}
+
+	var content string
+	contentLoaded := false
+	value := func(field string) string {
+		switch field {
+		case model.RuleFieldTitle:
+			return fields.Title
+		case model.RuleFieldLink:
+			return fields.Link
+		case model.RuleFieldAuthor:
+			return fields.Author
+		}
+		if !contentLoaded {
+			content = htmlText(fields.Content)
+			contentLoaded = true
+		}
+		if field == model.RuleFieldContent {
+			return content
+		}
+		return strings.Join([]string{fields.Title, content, fields.Link, fields.Author}, "\n")
+	}
+
+	for _, c := range s.rules {
+		if !c.match(value(c.rule.Field)) {
+			continue
+		}
+		outcome.RuleIDs = append(outcome.RuleIDs, c.rule.ID)
+		switch c.rule.Action {
+		case model.RuleActionDrop:
+			outcome.Drop = true
+		case model.RuleActionRead:
+			outcome.Read = true
+		case model.RuleActionBookmark:
+			outcome.Bookmark = true
+		case model.RuleActionMove:
+			if outcome.GroupID == 0 {
+				outcome.GroupID = c.rule.TargetGroupID
+			}
+		}
+	}
+	return outcome
+}
+
+func (c compiledRule) match(value string) bool {
+	if c.re != nil {
+		return c.re.MatchString(value)
+	}
+	return strings.Contains(strings.ToLower(value), c.needle)
+}
+
+// htmlText returns the text of an HTML fragment with tags removed and
+// entities decoded.
+func htmlText(fragment string) string {
+	if !strings.ContainsAny(fragment, "<&") {
+		return fragment
+	}
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return b.String()
+		case html.TextToken:
+			b.Write(z.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			b.WriteByte(' ')
+		}
+	}
+}
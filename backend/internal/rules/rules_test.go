@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+func TestEvaluateCombinesActions(t *testing.T) {
+	set, err := Compile([]*model.Rule{
+		{ID: 1, Field: model.RuleFieldTitle, MatchType: model.RuleMatchContains, Pattern: "SPONSORED", Action: model.RuleActionRead, Enabled: true},
+		{ID: 2, Field: model.RuleFieldContent, MatchType: model.RuleMatchRegex, Pattern: `\bkubernetes\b`, Action: model.RuleActionMove, TargetGroupID: 7, Enabled: true},
+		{ID: 3, Field: model.RuleFieldAny, MatchType: model.RuleMatchContains, Pattern: "example.com/ads", Action: model.RuleActionDrop, Enabled: true},
+		{ID: 4, Field: model.RuleFieldTitle, MatchType: model.RuleMatchContains, Pattern: "sponsored", Action: model.RuleActionBookmark},
+		{ID: 5, Field: model.RuleFieldAuthor, MatchType: model.RuleMatchContains, Pattern: "bot", Action: model.RuleActionMove, TargetGroupID: 9, Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("Compile() failed: %v", err)
+	}
+	if set.Len() != 4 {
+		t.Fatalf("expected disabled rule to be skipped, Len() = %d", set.Len())
+	}
+
+	got := set.Evaluate(Fields{
+		Title:   "Sponsored: cloud news",
+		Content: "<p>All about <b>kubernetes</b> &amp; more</p>",
+		Link:    "https://example.com/posts/1",
+		Author:  "newsbot",
+	})
+	want := Outcome{Read: true, GroupID: 7, RuleIDs: []int64{1, 2, 5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Evaluate() = %+v, want %+v", got, want)
+	}
+
+	got = set.Evaluate(Fields{Title: "Hello", Link: "https://example.com/ads/1"})
+	if !got.Drop || !reflect.DeepEqual(got.RuleIDs, []int64{3}) {
+		t.Fatalf("expected drop by rule 3, got %+v", got)
+	}
+
+	if got := set.Evaluate(Fields{Title: "Plain", Content: "<a href=\"kubernetes\">link</a>"}); got.Matched() {
+		t.Fatalf("markup must not match, got %+v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := &model.Rule{Name: "ads", Field: model.RuleFieldLink, MatchType: model.RuleMatchRegex, Pattern: `/ads?/`, Action: model.RuleActionDrop}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("Validate() rejected valid rule: %v", err)
+	}
+
+	tests := map[string]func(r *model.Rule){
+		"missing name":       func(r *model.Rule) { r.Name = " " },
+		"feed and group":     func(r *model.Rule) { r.FeedID, r.GroupID = 1, 1 },
+		"unknown field":      func(r *model.Rule) { r.Field = "body" },
+		"empty pattern":      func(r *model.Rule) { r.Pattern = "" },
+		"bad regex":          func(r *model.Rule) { r.Pattern = "(" },
+		"unknown match":      func(r *model.Rule) { r.MatchType = "glob" },
+		"unknown action":     func(r *model.Rule) { r.Action = "star" },
+		"move without group": func(r *model.Rule) { r.Action = model.RuleActionMove },
+		"target on drop":     func(r *model.Rule) { r.TargetGroupID = 2 },
+	}
+	for name, mutate := range tests {
+		rule := *valid
+		mutate(&rule)
+		if err := Validate(&rule); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -103,12 +103,15 @@ func (s *Store) GetBookmark(id int64) (*model.Bookmark, error) {
 
 // CreateBookmark saves a snapshot of content. itemID/feedID may be nil if the
 // original item/feed is gone, in which case the bookmark preserves the content.
-func (s *Store) CreateBookmark(itemID *int64, feedID *int64, link, title, content string, pubDate int64, feedName string) (*model.Bookmark, error) {
+// CreateBookmark stores a snapshot. sanitizeVersion is the sanitize policy
+// already applied to content (0 when it was not sanitized).
+func (s *Store) CreateBookmark(itemID *int64, feedID *int64, link, title, content string, pubDate int64, feedName string, sanitizeVersion int64) (*model.Bookmark, error) {
 	result, err := s.db.Exec(`
-		INSERT INTO bookmarks (item_id, feed_id, link, title, content, pub_date, feed_name)
-		VALUES (:item_id, :feed_id, :link, :title, :content, :pub_date, :feed_name)
+		INSERT INTO bookmarks (item_id, feed_id, link, title, content, pub_date, feed_name, sanitize_version)
+		VALUES (:item_id, :feed_id, :link, :title, :content, :pub_date, :feed_name, :sanitize_version)
 	`, sql.Named("item_id", itemID), sql.Named("feed_id", feedID), sql.Named("link", link), sql.Named("title", title),
-		sql.Named("content", content), sql.Named("pub_date", pubDate), sql.Named("feed_name", feedName))
+		sql.Named("content", content), sql.Named("pub_date", pubDate), sql.Named("feed_name", feedName),
+		sql.Named("sanitize_version", sanitizeVersion))
 	if err != nil {
 		return nil, err
 	}
@@ -203,3 +206,49 @@ func (s *Store) ListSavedItemIDs() ([]int64, error) {
 
 	return ids, rows.Err()
 }
+
+// ListBookmarksBelowSanitizeVersion returns bookmarks sanitized with an older
+// policy than version, in id order after afterID. Only ID, Link and Content
+// are set.
+func (s *Store) ListBookmarksBelowSanitizeVersion(version, afterID int64, limit int) ([]*model.Bookmark, error) {
+	rows, err := s.db.Query(`
+		SELECT id, link, content
+		FROM bookmarks
+		WHERE sanitize_version < :version AND id > :after_id
+		ORDER BY id
+		LIMIT :limit
+	`, sql.Named("version", version), sql.Named("after_id", afterID), sql.Named("limit", limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookmarks := []*model.Bookmark{}
+	for rows.Next() {
+		b := &model.Bookmark{}
+		if err := rows.Scan(&b.ID, &b.Link, &b.Content); err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, rows.Err()
+}
+
+// UpdateBookmarkSanitized replaces the snapshot content with its re-sanitized
+// version and records the policy version.
+func (s *Store) UpdateBookmarkSanitized(id int64, content string, version int64) error {
+	result, err := s.db.Exec(`
+		UPDATE bookmarks SET content = :content, sanitize_version = :version WHERE id = :id
+	`, sql.Named("content", content), sql.Named("version", version), sql.Named("id", id))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: bookmark", ErrNotFound)
+	}
+	return nil
+}
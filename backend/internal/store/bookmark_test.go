@@ -375,7 +375,7 @@ func TestCreateBookmark(t *testing.T) {
 		mustCreateBookmark(t, store, nil, nil, link, "Bookmark 1", "Content", 123, "Feed")
 
 		// Try to create duplicate
-		_, err := store.CreateBookmark(nil, nil, link, "Bookmark 2", "Content", 123, "Feed")
+		_, err := store.CreateBookmark(nil, nil, link, "Bookmark 2", "Content", 123, "Feed", 0)
 		if err == nil {
 			t.Error("expected error when creating duplicate bookmark link, got nil")
 		}
@@ -1,5 +1,12 @@
 package store
 
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
 // boolToInt converts Go bool to SQLite INTEGER (0/1).
 func boolToInt(b bool) int {
 	if b {
@@ -12,3 +19,39 @@ func boolToInt(b bool) int {
 func intToBool(i int) bool {
 	return i != 0
 }
+
+// encodeJSONList converts a slice to a JSON array for TEXT columns; nil becomes "[]".
+func encodeJSONList[T any](values []T) (string, error) {
+	if values == nil {
+		values = []T{}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeJSONList converts a JSON array TEXT column to a non-nil slice.
+func decodeJSONList[T any](data string) ([]T, error) {
+	values := []T{}
+	if data == "" {
+		return values, nil
+	}
+	if err := json.Unmarshal([]byte(data), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// decodeItemMetadata fills the JSON-encoded list columns of an item.
+func decodeItemMetadata(i *model.Item, categories, enclosures string) error {
+	var err error
+	if i.Categories, err = decodeJSONList[string](categories); err != nil {
+		return fmt.Errorf("decode item categories: %w", err)
+	}
+	if i.Enclosures, err = decodeJSONList[model.Enclosure](enclosures); err != nil {
+		return fmt.Errorf("decode item enclosures: %w", err)
+	}
+	return nil
+}
@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrNotFound = errors.New("not found")
 	ErrInvalid  = errors.New("invalid")
+	ErrConflict = errors.New("conflict")
 )
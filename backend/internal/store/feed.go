@@ -14,20 +14,28 @@ func (s *Store) ListFeeds() ([]*model.Feed, error) {
 	rows, err := s.db.Query(`
 		SELECT f.id, f.group_id, f.name, f.link, f.site_url,
 		       f.suspended, f.proxy, f.created_at, f.updated_at,
+		       f.retention_days, f.retention_max_items, f.pull_interval, f.max_backoff, f.full_text, f.update_mode, f.auth, f.user_agent, f.scrape,
 		       COALESCE(fs.etag, ''), COALESCE(fs.last_modified, ''), COALESCE(fs.cache_control, ''),
 		       COALESCE(fs.expires_at, 0), COALESCE(fs.last_checked_at, 0), COALESCE(fs.next_check_at, 0),
 		       COALESCE(fs.last_http_status, 0), COALESCE(fs.retry_after_until, 0), COALESCE(fs.last_success_at, 0),
 		       COALESCE(fs.last_error_at, 0), COALESCE(fs.last_error, ''), COALESCE(fs.consecutive_failures, 0),
+		       COALESCE(fs.adaptive_interval, 0), COALESCE(fs.publish_interval, 0), COALESCE(fs.not_modified_ratio, 0),
+		       COALESCE(fs.redirect_url, ''), COALESCE(fs.redirect_count, 0), COALESCE(fs.suspend_reason, ''),
 		       COALESCE(SUM(CASE WHEN i.unread = 1 THEN 1 ELSE 0 END), 0) AS unread_count,
-		       COALESCE(COUNT(i.id), 0) AS item_count
+		       COALESCE(COUNT(i.id), 0) AS item_count,
+		       EXISTS (SELECT 1 FROM feed_icons fi WHERE fi.feed_id = f.id AND length(fi.data) > 0) AS has_icon,
+		       EXISTS (SELECT 1 FROM websub_subscriptions ws WHERE ws.feed_id = f.id AND ws.state = 'active' AND ws.lease_expires_at > unixepoch()) AS websub_active
 		FROM feeds f
 		LEFT JOIN feed_fetch_state fs ON fs.feed_id = f.id
 		LEFT JOIN items i ON i.feed_id = f.id
 		GROUP BY f.id, f.group_id, f.name, f.link, f.site_url,
 		         f.suspended, f.proxy, f.created_at, f.updated_at,
+		         f.retention_days, f.retention_max_items, f.pull_interval, f.max_backoff, f.full_text, f.update_mode, f.auth, f.user_agent, f.scrape,
 		         fs.etag, fs.last_modified, fs.cache_control, fs.expires_at, fs.last_checked_at,
 		         fs.next_check_at, fs.last_http_status, fs.retry_after_until, fs.last_success_at,
-		         fs.last_error_at, fs.last_error, fs.consecutive_failures
+		         fs.last_error_at, fs.last_error, fs.consecutive_failures,
+		         fs.adaptive_interval, fs.publish_interval, fs.not_modified_ratio,
+		         fs.redirect_url, fs.redirect_count, fs.suspend_reason
 		ORDER BY f.id
 	`)
 	if err != nil {
@@ -38,7 +46,8 @@ func (s *Store) ListFeeds() ([]*model.Feed, error) {
 	feeds := []*model.Feed{}
 	for rows.Next() {
 		f := &model.Feed{}
-		var suspended int
+		var suspended, fullText, hasIcon, webSubActive int
+		var sealedAuth, scrape string
 		if err := rows.Scan(
 			&f.ID,
 			&f.GroupID,
@@ -49,6 +58,15 @@ func (s *Store) ListFeeds() ([]*model.Feed, error) {
 			&f.Proxy,
 			&f.CreatedAt,
 			&f.UpdatedAt,
+			&f.RetentionDays,
+			&f.RetentionMaxItems,
+			&f.PullInterval,
+			&f.MaxBackoff,
+			&fullText,
+			&f.UpdateMode,
+			&sealedAuth,
+			&f.UserAgent,
+			&scrape,
 			&f.FetchState.ETag,
 			&f.FetchState.LastModified,
 			&f.FetchState.CacheControl,
@@ -61,27 +79,79 @@ func (s *Store) ListFeeds() ([]*model.Feed, error) {
 			&f.FetchState.LastErrorAt,
 			&f.FetchState.LastError,
 			&f.FetchState.ConsecutiveFailures,
+			&f.FetchState.AdaptiveInterval,
+			&f.FetchState.PublishInterval,
+			&f.FetchState.NotModifiedRatio,
+			&f.FetchState.RedirectURL,
+			&f.FetchState.RedirectCount,
+			&f.FetchState.SuspendReason,
 			&f.UnreadCount,
 			&f.ItemCount,
+			&hasIcon,
+			&webSubActive,
 		); err != nil {
 			return nil, err
 		}
 		f.Suspended = intToBool(suspended)
+		f.FullText = intToBool(fullText)
+		f.HasIcon = intToBool(hasIcon)
+		f.WebSubActive = intToBool(webSubActive)
+		s.openFeedAuth(f, sealedAuth)
+		decodeScrapeRecipe(f, scrape)
 		feeds = append(feeds, f)
 	}
 	return feeds, rows.Err()
 }
 
+// ListFeedRefs returns all feeds with only ID, GroupID, Name, Link and SiteURL
+// set, without the item aggregates of ListFeeds.
+func (s *Store) ListFeedRefs() ([]*model.Feed, error) {
+	rows, err := s.db.Query(`SELECT id, group_id, name, link, site_url FROM feeds ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []*model.Feed{}
+	for rows.Next() {
+		f := &model.Feed{}
+		if err := rows.Scan(&f.ID, &f.GroupID, &f.Name, &f.Link, &f.SiteURL); err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, f)
+	}
+	return feeds, rows.Err()
+}
+
+// GetFeedByLink returns the feed subscribed to link, as GetFeed does.
+func (s *Store) GetFeedByLink(link string) (*model.Feed, error) {
+	var id int64
+	err := s.db.QueryRow(`SELECT id FROM feeds WHERE link = :link`, sql.Named("link", link)).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: feed", ErrNotFound)
+		}
+		return nil, fmt.Errorf("get feed by link: %w", err)
+	}
+	return s.GetFeed(id)
+}
+
 func (s *Store) GetFeed(id int64) (*model.Feed, error) {
 	f := &model.Feed{}
-	var suspended int
+	var suspended, fullText, hasIcon, webSubActive int
+	var sealedAuth, scrape string
 	err := s.db.QueryRow(`
 		SELECT f.id, f.group_id, f.name, f.link, f.site_url,
 		       f.suspended, f.proxy, f.created_at, f.updated_at,
+		       f.retention_days, f.retention_max_items, f.pull_interval, f.max_backoff, f.full_text, f.update_mode, f.auth, f.user_agent, f.scrape,
 		       COALESCE(fs.etag, ''), COALESCE(fs.last_modified, ''), COALESCE(fs.cache_control, ''),
 		       COALESCE(fs.expires_at, 0), COALESCE(fs.last_checked_at, 0), COALESCE(fs.next_check_at, 0),
 		       COALESCE(fs.last_http_status, 0), COALESCE(fs.retry_after_until, 0), COALESCE(fs.last_success_at, 0),
-		       COALESCE(fs.last_error_at, 0), COALESCE(fs.last_error, ''), COALESCE(fs.consecutive_failures, 0)
+		       COALESCE(fs.last_error_at, 0), COALESCE(fs.last_error, ''), COALESCE(fs.consecutive_failures, 0),
+		       COALESCE(fs.adaptive_interval, 0), COALESCE(fs.publish_interval, 0), COALESCE(fs.not_modified_ratio, 0),
+		       COALESCE(fs.redirect_url, ''), COALESCE(fs.redirect_count, 0), COALESCE(fs.suspend_reason, ''),
+		       EXISTS (SELECT 1 FROM feed_icons fi WHERE fi.feed_id = f.id AND length(fi.data) > 0),
+		       EXISTS (SELECT 1 FROM websub_subscriptions ws WHERE ws.feed_id = f.id AND ws.state = 'active' AND ws.lease_expires_at > unixepoch())
 		FROM feeds f
 		LEFT JOIN feed_fetch_state fs ON fs.feed_id = f.id
 		WHERE f.id = :id
@@ -95,6 +165,15 @@ func (s *Store) GetFeed(id int64) (*model.Feed, error) {
 		&f.Proxy,
 		&f.CreatedAt,
 		&f.UpdatedAt,
+		&f.RetentionDays,
+		&f.RetentionMaxItems,
+		&f.PullInterval,
+		&f.MaxBackoff,
+		&fullText,
+		&f.UpdateMode,
+		&sealedAuth,
+		&f.UserAgent,
+		&scrape,
 		&f.FetchState.ETag,
 		&f.FetchState.LastModified,
 		&f.FetchState.CacheControl,
@@ -107,6 +186,14 @@ func (s *Store) GetFeed(id int64) (*model.Feed, error) {
 		&f.FetchState.LastErrorAt,
 		&f.FetchState.LastError,
 		&f.FetchState.ConsecutiveFailures,
+		&f.FetchState.AdaptiveInterval,
+		&f.FetchState.PublishInterval,
+		&f.FetchState.NotModifiedRatio,
+		&f.FetchState.RedirectURL,
+		&f.FetchState.RedirectCount,
+		&f.FetchState.SuspendReason,
+		&hasIcon,
+		&webSubActive,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -116,10 +203,53 @@ func (s *Store) GetFeed(id int64) (*model.Feed, error) {
 	}
 
 	f.Suspended = intToBool(suspended)
+	f.FullText = intToBool(fullText)
+	f.HasIcon = intToBool(hasIcon)
+	f.WebSubActive = intToBool(webSubActive)
+	s.openFeedAuth(f, sealedAuth)
+	decodeScrapeRecipe(f, scrape)
 	return f, nil
 }
 
 func (s *Store) CreateFeed(groupID int64, name, link, siteURL, proxy string) (*model.Feed, error) {
+	return s.CreateFeedWithParams(CreateFeedParams{
+		GroupID: groupID,
+		Name:    name,
+		Link:    link,
+		SiteURL: siteURL,
+		Proxy:   proxy,
+	})
+}
+
+// CreateFeedParams holds input for CreateFeedWithParams.
+type CreateFeedParams struct {
+	GroupID int64
+	Name    string
+	Link    string
+	SiteURL string
+	Proxy   string
+	// Auth is sealed before it is stored; nil or a zero value stores none.
+	Auth      *model.FeedAuth
+	UserAgent string
+	// Scrape creates a scraper feed when set.
+	Scrape *model.ScrapeRecipe
+}
+
+// CreateFeedWithParams creates a feed with its request settings in a single
+// transaction, so the first pull never sees the feed without them.
+func (s *Store) CreateFeedWithParams(params CreateFeedParams) (*model.Feed, error) {
+	var sealedAuth string
+	if params.Auth != nil {
+		var err error
+		if sealedAuth, err = s.sealFeedAuth(params.Auth); err != nil {
+			return nil, fmt.Errorf("seal feed auth: %w", err)
+		}
+	}
+	recipe, err := encodeScrapeRecipe(params.Scrape)
+	if err != nil {
+		return nil, fmt.Errorf("encode scrape recipe: %w", err)
+	}
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, err
@@ -127,10 +257,11 @@ func (s *Store) CreateFeed(groupID int64, name, link, siteURL, proxy string) (*m
 	defer tx.Rollback()
 
 	result, err := tx.Exec(`
-		INSERT INTO feeds (group_id, name, link, site_url, proxy)
-		VALUES (:group_id, :name, :link, :site_url, :proxy)
-	`, sql.Named("group_id", groupID), sql.Named("name", name), sql.Named("link", link),
-		sql.Named("site_url", siteURL), sql.Named("proxy", proxy))
+		INSERT INTO feeds (group_id, name, link, site_url, proxy, auth, user_agent, scrape)
+		VALUES (:group_id, :name, :link, :site_url, :proxy, :auth, :user_agent, :scrape)
+	`, sql.Named("group_id", params.GroupID), sql.Named("name", params.Name), sql.Named("link", params.Link),
+		sql.Named("site_url", params.SiteURL), sql.Named("proxy", params.Proxy), sql.Named("auth", sealedAuth),
+		sql.Named("user_agent", params.UserAgent), sql.Named("scrape", recipe))
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +324,20 @@ type UpdateFeedParams struct {
 	SiteURL   *string
 	Suspended *bool
 	Proxy     *string
+	// RetentionDays / RetentionMaxItems: -1 inherits the global default.
+	RetentionDays     *int64
+	RetentionMaxItems *int64
+	// PullInterval / MaxBackoff are in seconds; 0 inherits the global default.
+	PullInterval *int64
+	MaxBackoff   *int64
+	FullText     *bool
+	UpdateMode   *string
+	// Auth replaces the stored credentials; a zero value removes them.
+	Auth      *model.FeedAuth
+	UserAgent *string
+	// Scrape sets the scraping recipe, which turns the feed into a scraper
+	// feed.
+	Scrape *model.ScrapeRecipe
 }
 
 // UpdateFeed performs partial update of feed fields using a single dynamic UPDATE query.
@@ -224,6 +369,50 @@ func (s *Store) UpdateFeed(id int64, params UpdateFeedParams) error {
 		setClauses = append(setClauses, "proxy = :proxy")
 		args = append(args, sql.Named("proxy", *params.Proxy))
 	}
+	if params.RetentionDays != nil {
+		setClauses = append(setClauses, "retention_days = :retention_days")
+		args = append(args, sql.Named("retention_days", *params.RetentionDays))
+	}
+	if params.RetentionMaxItems != nil {
+		setClauses = append(setClauses, "retention_max_items = :retention_max_items")
+		args = append(args, sql.Named("retention_max_items", *params.RetentionMaxItems))
+	}
+	if params.PullInterval != nil {
+		setClauses = append(setClauses, "pull_interval = :pull_interval")
+		args = append(args, sql.Named("pull_interval", *params.PullInterval))
+	}
+	if params.MaxBackoff != nil {
+		setClauses = append(setClauses, "max_backoff = :max_backoff")
+		args = append(args, sql.Named("max_backoff", *params.MaxBackoff))
+	}
+	if params.FullText != nil {
+		setClauses = append(setClauses, "full_text = :full_text")
+		args = append(args, sql.Named("full_text", boolToInt(*params.FullText)))
+	}
+	if params.UpdateMode != nil {
+		setClauses = append(setClauses, "update_mode = :update_mode")
+		args = append(args, sql.Named("update_mode", *params.UpdateMode))
+	}
+	if params.UserAgent != nil {
+		setClauses = append(setClauses, "user_agent = :user_agent")
+		args = append(args, sql.Named("user_agent", *params.UserAgent))
+	}
+	if params.Scrape != nil {
+		recipe, err := encodeScrapeRecipe(params.Scrape)
+		if err != nil {
+			return fmt.Errorf("encode scrape recipe: %w", err)
+		}
+		setClauses = append(setClauses, "scrape = :scrape")
+		args = append(args, sql.Named("scrape", recipe))
+	}
+	if params.Auth != nil {
+		sealed, err := s.sealFeedAuth(params.Auth)
+		if err != nil {
+			return fmt.Errorf("seal feed auth: %w", err)
+		}
+		setClauses = append(setClauses, "auth = :auth")
+		args = append(args, sql.Named("auth", sealed))
+	}
 
 	if len(setClauses) == 0 {
 		return nil
@@ -296,12 +485,27 @@ func (s *Store) UpdateFeed(id int64, params UpdateFeedParams) error {
 				last_error_at = 0,
 				last_error = '',
 				consecutive_failures = 0,
+				adaptive_interval = 0,
+				publish_interval = 0,
+				not_modified_ratio = 0,
+				redirect_url = '',
+				redirect_count = 0,
 				updated_at = unixepoch()
 		`, sql.Named("feed_id", id)); err != nil {
 			return err
 		}
 	}
 
+	// Any explicit suspend/resume replaces an automatic suspension reason.
+	if params.Suspended != nil {
+		if _, err := tx.Exec(`
+			UPDATE feed_fetch_state SET suspend_reason = '', updated_at = unixepoch()
+			WHERE feed_id = :feed_id
+		`, sql.Named("feed_id", id)); err != nil {
+			return err
+		}
+	}
+
 	return tx.Commit()
 }
 
@@ -350,6 +554,10 @@ type UpdateFeedFetchSuccessParams struct {
 	ExpiresAt       int64
 	RetryAfterUntil int64
 	NextCheckAt     int64
+	// Adaptive polling cadence; see model.FeedFetchState.
+	AdaptiveInterval int64
+	PublishInterval  int64
+	NotModifiedRatio float64
 }
 
 func (s *Store) UpdateFeedFetchSuccess(id int64, params UpdateFeedFetchSuccessParams) error {
@@ -368,6 +576,9 @@ func (s *Store) UpdateFeedFetchSuccess(id int64, params UpdateFeedFetchSuccessPa
 			last_error_at,
 			last_error,
 			consecutive_failures,
+			adaptive_interval,
+			publish_interval,
+			not_modified_ratio,
 			updated_at
 		)
 		VALUES (
@@ -384,6 +595,9 @@ func (s *Store) UpdateFeedFetchSuccess(id int64, params UpdateFeedFetchSuccessPa
 			0,
 			'',
 			0,
+			:adaptive_interval,
+			:publish_interval,
+			:not_modified_ratio,
 			unixepoch()
 		)
 		ON CONFLICT(feed_id) DO UPDATE SET
@@ -399,6 +613,9 @@ func (s *Store) UpdateFeedFetchSuccess(id int64, params UpdateFeedFetchSuccessPa
 			last_error_at = 0,
 			last_error = '',
 			consecutive_failures = 0,
+			adaptive_interval = excluded.adaptive_interval,
+			publish_interval = excluded.publish_interval,
+			not_modified_ratio = excluded.not_modified_ratio,
 			updated_at = unixepoch()
 	`,
 		sql.Named("feed_id", id),
@@ -411,6 +628,9 @@ func (s *Store) UpdateFeedFetchSuccess(id int64, params UpdateFeedFetchSuccessPa
 		sql.Named("last_http_status", params.HTTPStatus),
 		sql.Named("retry_after_until", params.RetryAfterUntil),
 		sql.Named("last_success_at", params.CheckedAt),
+		sql.Named("adaptive_interval", params.AdaptiveInterval),
+		sql.Named("publish_interval", params.PublishInterval),
+		sql.Named("not_modified_ratio", params.NotModifiedRatio),
 	)
 	return err
 }
@@ -521,23 +741,31 @@ func (s *Store) UpdateFeedSiteURLIfEmpty(id int64, siteURL string) error {
 
 // BatchCreateFeedsInput holds input for batch feed creation.
 type BatchCreateFeedsInput struct {
-	GroupID int64
-	Name    string
-	Link    string
-	SiteURL string
+	GroupID   int64
+	Name      string
+	Link      string
+	SiteURL   string
+	Proxy     string
+	Suspended bool
+	// Scrape creates a scraper feed when set.
+	Scrape *model.ScrapeRecipe
 }
 
 // BatchCreateFeedsResult holds the result of batch feed creation.
+//
+// Outcomes is parallel to the inputs: nil when the feed was created, otherwise
+// the reason it was skipped (duplicates wrap ErrConflict).
 type BatchCreateFeedsResult struct {
 	Created    int
 	CreatedIDs []int64
 	Errors     []string
+	Outcomes   []error
 }
 
 // BatchCreateFeeds creates multiple feeds in a single transaction.
 // It skips feeds with duplicate links and returns statistics.
 func (s *Store) BatchCreateFeeds(inputs []BatchCreateFeedsInput) (*BatchCreateFeedsResult, error) {
-	result := &BatchCreateFeedsResult{}
+	result := &BatchCreateFeedsResult{Outcomes: make([]error, len(inputs))}
 
 	if len(inputs) == 0 {
 		return result, nil
@@ -550,8 +778,8 @@ func (s *Store) BatchCreateFeeds(inputs []BatchCreateFeedsInput) (*BatchCreateFe
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
-		INSERT INTO feeds (group_id, name, link, site_url, proxy)
-		VALUES (:group_id, :name, :link, :site_url, '')
+		INSERT INTO feeds (group_id, name, link, site_url, proxy, suspended, scrape)
+		VALUES (:group_id, :name, :link, :site_url, :proxy, :suspended, :scrape)
 		ON CONFLICT(link) DO NOTHING
 	`)
 	if err != nil {
@@ -571,37 +799,52 @@ func (s *Store) BatchCreateFeeds(inputs []BatchCreateFeedsInput) (*BatchCreateFe
 
 	seenLinks := make(map[string]bool, len(inputs))
 
-	for _, input := range inputs {
+	fail := func(i int, outcome error, message string) {
+		result.Outcomes[i] = outcome
+		result.Errors = append(result.Errors, message)
+	}
+	errDuplicate := fmt.Errorf("%w: duplicate feed", ErrConflict)
+
+	for i, input := range inputs {
 		if seenLinks[input.Link] {
-			result.Errors = append(result.Errors, fmt.Sprintf("duplicate feed: %s", input.Link))
+			fail(i, errDuplicate, fmt.Sprintf("duplicate feed: %s", input.Link))
 			continue
 		}
 		seenLinks[input.Link] = true
 
+		recipe, err := encodeScrapeRecipe(input.Scrape)
+		if err != nil {
+			fail(i, err, fmt.Sprintf("failed to encode scrape recipe for %s: %v", input.Link, err))
+			continue
+		}
+
 		res, err := stmt.Exec(
 			sql.Named("group_id", input.GroupID),
 			sql.Named("name", input.Name),
 			sql.Named("link", input.Link),
 			sql.Named("site_url", input.SiteURL),
+			sql.Named("proxy", input.Proxy),
+			sql.Named("suspended", boolToInt(input.Suspended)),
+			sql.Named("scrape", recipe),
 		)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("failed to create %s: %v", input.Link, err))
+			fail(i, err, fmt.Sprintf("failed to create %s: %v", input.Link, err))
 			continue
 		}
 
 		affected, err := res.RowsAffected()
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("failed to inspect result for %s: %v", input.Link, err))
+			fail(i, err, fmt.Sprintf("failed to inspect result for %s: %v", input.Link, err))
 			continue
 		}
 		if affected == 0 {
-			result.Errors = append(result.Errors, fmt.Sprintf("duplicate feed: %s", input.Link))
+			fail(i, errDuplicate, fmt.Sprintf("duplicate feed: %s", input.Link))
 			continue
 		}
 
 		id, err := res.LastInsertId()
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("failed to get id for %s: %v", input.Link, err))
+			fail(i, err, fmt.Sprintf("failed to get id for %s: %v", input.Link, err))
 			continue
 		}
 
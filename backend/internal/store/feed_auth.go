@@ -0,0 +1,52 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/secretbox"
+)
+
+var errNoSecretBox = errors.New("feed credentials require a secret key")
+
+// SetSecretBox configures the key sealing feed credentials. It must be called
+// before the store is shared between goroutines.
+func (s *Store) SetSecretBox(box *secretbox.Box) {
+	s.secrets = box
+}
+
+// sealFeedAuth encrypts auth for the feeds.auth column; zero auth is stored
+// as an empty string.
+func (s *Store) sealFeedAuth(auth *model.FeedAuth) (string, error) {
+	if auth.IsZero() {
+		return "", nil
+	}
+	if s.secrets == nil {
+		return "", errNoSecretBox
+	}
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return "", err
+	}
+	return s.secrets.Seal(data)
+}
+
+// openFeedAuth fills f.Auth from a sealed column value. Values that cannot be
+// opened (e.g. after the key changed) leave Auth nil, so requests go out
+// without credentials and fail visibly instead of hiding the feed.
+func (s *Store) openFeedAuth(f *model.Feed, sealed string) {
+	f.HasAuth = sealed != ""
+	if sealed == "" || s.secrets == nil {
+		return
+	}
+	data, err := s.secrets.Open(sealed)
+	if err != nil {
+		return
+	}
+	auth := &model.FeedAuth{}
+	if err := json.Unmarshal(data, auth); err != nil {
+		return
+	}
+	f.Auth = auth
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// maxFeedFetchLog bounds the stored attempts per feed; older entries are
+// dropped when a new one is recorded.
+const maxFeedFetchLog = 100
+
+// feedHealthWindow is the span over which items per day are averaged.
+const feedHealthWindow = 30 * 24 * time.Hour
+
+// maxFetchLogError caps the stored error message.
+const maxFetchLogError = 512
+
+// RecordFeedFetch appends a pull attempt to the feed's fetch log.
+func (s *Store) RecordFeedFetch(entry *model.FeedFetchLog) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	message := entry.Error
+	if len(message) > maxFetchLogError {
+		message = message[:maxFetchLogError]
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO feed_fetch_log (feed_id, fetched_at, duration_ms, http_status, bytes, new_items, error_class, error)
+		VALUES (:feed_id, :fetched_at, :duration_ms, :http_status, :bytes, :new_items, :error_class, :error)
+	`, sql.Named("feed_id", entry.FeedID), sql.Named("fetched_at", entry.FetchedAt),
+		sql.Named("duration_ms", entry.DurationMs), sql.Named("http_status", entry.HTTPStatus),
+		sql.Named("bytes", entry.Bytes), sql.Named("new_items", entry.NewItems),
+		sql.Named("error_class", entry.ErrorClass), sql.Named("error", message)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM feed_fetch_log
+		WHERE feed_id = :feed_id AND id NOT IN (
+			SELECT id FROM feed_fetch_log WHERE feed_id = :feed_id ORDER BY id DESC LIMIT :keep
+		)
+	`, sql.Named("feed_id", entry.FeedID), sql.Named("keep", maxFeedFetchLog)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListFeedFetchLog returns the recorded pull attempts of a feed, newest first.
+func (s *Store) ListFeedFetchLog(feedID int64) ([]*model.FeedFetchLog, error) {
+	rows, err := s.db.Query(`
+		SELECT id, feed_id, fetched_at, duration_ms, http_status, bytes, new_items, error_class, error
+		FROM feed_fetch_log
+		WHERE feed_id = :feed_id
+		ORDER BY id DESC
+	`, sql.Named("feed_id", feedID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*model.FeedFetchLog{}
+	for rows.Next() {
+		e := &model.FeedFetchLog{}
+		if err := rows.Scan(&e.ID, &e.FeedID, &e.FetchedAt, &e.DurationMs, &e.HTTPStatus, &e.Bytes,
+			&e.NewItems, &e.ErrorClass, &e.Error); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ListFeedHealth aggregates the fetch log and recent items of every feed as
+// of now (Unix seconds).
+func (s *Store) ListFeedHealth(now int64) ([]*model.FeedHealth, error) {
+	window := int64(feedHealthWindow.Seconds())
+	rows, err := s.db.Query(`
+		SELECT f.id, f.name, f.created_at,
+		       COALESCE(l.attempts, 0), COALESCE(l.successes, 0), COALESCE(l.avg_duration, 0),
+		       COALESCE(l.bytes, 0), COALESCE(l.last_fetch_at, 0),
+		       COALESCE((SELECT error_class FROM feed_fetch_log WHERE feed_id = f.id ORDER BY id DESC LIMIT 1), ''),
+		       COALESCE(i.recent, 0), COALESCE(i.last_created, 0)
+		FROM feeds f
+		LEFT JOIN (
+			SELECT feed_id, COUNT(*) AS attempts, SUM(error_class = '') AS successes,
+			       CAST(AVG(duration_ms) AS INTEGER) AS avg_duration, SUM(bytes) AS bytes,
+			       MAX(fetched_at) AS last_fetch_at
+			FROM feed_fetch_log
+			GROUP BY feed_id
+		) l ON l.feed_id = f.id
+		LEFT JOIN (
+			SELECT feed_id, SUM(created_at >= :since) AS recent, MAX(created_at) AS last_created
+			FROM items
+			GROUP BY feed_id
+		) i ON i.feed_id = f.id
+		ORDER BY f.id
+	`, sql.Named("since", now-window))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*model.FeedHealth{}
+	for rows.Next() {
+		h := &model.FeedHealth{}
+		var createdAt, recent int64
+		if err := rows.Scan(&h.FeedID, &h.Name, &createdAt, &h.Attempts, &h.Successes, &h.AvgDurationMs,
+			&h.Bytes, &h.LastFetchAt, &h.LastErrorClass, &recent, &h.LastNewItemAt); err != nil {
+			return nil, err
+		}
+		if h.Attempts > 0 {
+			h.SuccessRate = float64(h.Successes) / float64(h.Attempts)
+		}
+		// Young feeds average over their age, but at least one day.
+		span := min(window, max(now-createdAt, 86400))
+		h.ItemsPerDay = float64(recent) * 86400 / float64(span)
+		list = append(list, h)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+func TestRecordFeedFetchKeepsNewestEntries(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	feed := mustCreateFeed(t, store, 1, "Feed", "https://example.com/feed.xml", "", "")
+
+	for i := range maxFeedFetchLog + 5 {
+		if err := store.RecordFeedFetch(&model.FeedFetchLog{FeedID: feed.ID, FetchedAt: int64(i + 1), HTTPStatus: 200}); err != nil {
+			t.Fatalf("RecordFeedFetch() failed: %v", err)
+		}
+	}
+
+	entries, err := store.ListFeedFetchLog(feed.ID)
+	if err != nil {
+		t.Fatalf("ListFeedFetchLog() failed: %v", err)
+	}
+	if len(entries) != maxFeedFetchLog {
+		t.Fatalf("expected %d entries, got %d", maxFeedFetchLog, len(entries))
+	}
+	if entries[0].FetchedAt != maxFeedFetchLog+5 || entries[len(entries)-1].FetchedAt != 6 {
+		t.Fatalf("expected newest entries first, got %d..%d", entries[0].FetchedAt, entries[len(entries)-1].FetchedAt)
+	}
+}
+
+func TestListFeedHealth(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	feed := mustCreateFeed(t, store, 1, "Feed", "https://example.com/feed.xml", "", "")
+	idle := mustCreateFeed(t, store, 1, "Idle", "https://example.com/idle.xml", "", "")
+	now := time.Now().Unix()
+	// An old feed averages over the full 30-day window.
+	if _, err := store.db.Exec(`UPDATE feeds SET created_at = ? WHERE id = ?`, now-60*86400, feed.ID); err != nil {
+		t.Fatalf("backdate feed: %v", err)
+	}
+	for i, guid := range []string{"a", "b", "c"} {
+		mustCreateItem(t, store, feed.ID, guid, guid, "https://example.com/"+guid, "", int64(i))
+	}
+	for _, entry := range []*model.FeedFetchLog{
+		{FeedID: feed.ID, FetchedAt: now - 20, DurationMs: 100, Bytes: 1000, NewItems: 3},
+		{FeedID: feed.ID, FetchedAt: now - 10, DurationMs: 300, ErrorClass: model.FetchErrorTimeout},
+		{FeedID: feed.ID, FetchedAt: now, DurationMs: 200, HTTPStatus: 304},
+	} {
+		if err := store.RecordFeedFetch(entry); err != nil {
+			t.Fatalf("RecordFeedFetch() failed: %v", err)
+		}
+	}
+
+	list, err := store.ListFeedHealth(now)
+	if err != nil {
+		t.Fatalf("ListFeedHealth() failed: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 feeds, got %d", len(list))
+	}
+
+	got := list[0]
+	if got.Attempts != 3 || got.Successes != 2 || got.AvgDurationMs != 200 || got.Bytes != 1000 || got.LastFetchAt != now || got.LastErrorClass != "" {
+		t.Fatalf("unexpected health: %+v", got)
+	}
+	if math.Abs(got.SuccessRate-2.0/3) > 1e-9 || math.Abs(got.ItemsPerDay-0.1) > 1e-9 || got.LastNewItemAt == 0 {
+		t.Fatalf("unexpected rates: %+v", got)
+	}
+
+	if list[1].FeedID != idle.ID || list[1].Attempts != 0 || list[1].SuccessRate != 0 || list[1].ItemsPerDay != 0 {
+		t.Fatalf("unexpected health for idle feed: %+v", list[1])
+	}
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// GetFeedIcon returns the cached favicon for a feed. Rows recorded for failed
+// discovery (no data) are reported as not found.
+func (s *Store) GetFeedIcon(feedID int64) (*model.FeedIcon, error) {
+	icon := &model.FeedIcon{}
+	err := s.db.QueryRow(`
+		SELECT feed_id, mime_type, data, source_url, fetched_at, refresh_after
+		FROM feed_icons
+		WHERE feed_id = :feed_id AND length(data) > 0
+	`, sql.Named("feed_id", feedID)).Scan(&icon.FeedID, &icon.MimeType, &icon.Data, &icon.SourceURL, &icon.FetchedAt, &icon.RefreshAfter)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: feed icon", ErrNotFound)
+		}
+		return nil, fmt.Errorf("get feed icon: %w", err)
+	}
+
+	return icon, nil
+}
+
+// ListFeedIcons returns every cached favicon that has data.
+func (s *Store) ListFeedIcons() ([]*model.FeedIcon, error) {
+	rows, err := s.db.Query(`
+		SELECT feed_id, mime_type, data, source_url, fetched_at, refresh_after
+		FROM feed_icons
+		WHERE length(data) > 0
+		ORDER BY feed_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	icons := []*model.FeedIcon{}
+	for rows.Next() {
+		icon := &model.FeedIcon{}
+		if err := rows.Scan(&icon.FeedID, &icon.MimeType, &icon.Data, &icon.SourceURL, &icon.FetchedAt, &icon.RefreshAfter); err != nil {
+			return nil, err
+		}
+		icons = append(icons, icon)
+	}
+	return icons, rows.Err()
+}
+
+// FeedIconRefreshAfter returns when the feed's favicon should be refreshed
+// next. 0 means no attempt has been recorded yet.
+func (s *Store) FeedIconRefreshAfter(feedID int64) (int64, error) {
+	var refreshAfter int64
+	err := s.db.QueryRow(`
+		SELECT refresh_after FROM feed_icons WHERE feed_id = :feed_id
+	`, sql.Named("feed_id", feedID)).Scan(&refreshAfter)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return refreshAfter, nil
+}
+
+type UpsertFeedIconParams struct {
+	MimeType     string
+	Data         []byte
+	SourceURL    string
+	FetchedAt    int64
+	RefreshAfter int64
+}
+
+// UpsertFeedIcon stores a discovery result. A failed discovery (empty Data)
+// keeps a previously cached icon and only moves refresh_after forward.
+func (s *Store) UpsertFeedIcon(feedID int64, params UpsertFeedIconParams) error {
+	if len(params.Data) == 0 {
+		_, err := s.db.Exec(`
+			INSERT INTO feed_icons (feed_id, refresh_after, updated_at)
+			VALUES (:feed_id, :refresh_after, unixepoch())
+			ON CONFLICT(feed_id) DO UPDATE SET
+				refresh_after = excluded.refresh_after,
+				updated_at = excluded.updated_at
+		`, sql.Named("feed_id", feedID), sql.Named("refresh_after", params.RefreshAfter))
+		return err
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO feed_icons (feed_id, mime_type, data, source_url, fetched_at, refresh_after, updated_at)
+		VALUES (:feed_id, :mime_type, :data, :source_url, :fetched_at, :refresh_after, unixepoch())
+		ON CONFLICT(feed_id) DO UPDATE SET
+			mime_type = excluded.mime_type,
+			data = excluded.data,
+			source_url = excluded.source_url,
+			fetched_at = excluded.fetched_at,
+			refresh_after = excluded.refresh_after,
+			updated_at = excluded.updated_at
+	`, sql.Named("feed_id", feedID), sql.Named("mime_type", params.MimeType), sql.Named("data", params.Data),
+		sql.Named("source_url", params.SourceURL), sql.Named("fetched_at", params.FetchedAt),
+		sql.Named("refresh_after", params.RefreshAfter))
+	return err
+}
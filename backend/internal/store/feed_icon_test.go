@@ -0,0 +1,89 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestUpsertFeedIcon(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Test Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed", "https://example.com", "")
+
+	if _, err := store.GetFeedIcon(feed.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound before upsert, got %v", err)
+	}
+	refreshAfter, err := store.FeedIconRefreshAfter(feed.ID)
+	if err != nil || refreshAfter != 0 {
+		t.Fatalf("FeedIconRefreshAfter() = %d, %v; want 0, nil", refreshAfter, err)
+	}
+
+	// A failed discovery records only the retry time.
+	if err := store.UpsertFeedIcon(feed.ID, UpsertFeedIconParams{RefreshAfter: 100}); err != nil {
+		t.Fatalf("UpsertFeedIcon() failed: %v", err)
+	}
+	if _, err := store.GetFeedIcon(feed.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for empty icon, got %v", err)
+	}
+	got, err := store.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed() failed: %v", err)
+	}
+	if got.HasIcon {
+		t.Error("expected HasIcon to be false for empty icon")
+	}
+
+	data := []byte("\x89PNG\r\n\x1a\nicon")
+	if err := store.UpsertFeedIcon(feed.ID, UpsertFeedIconParams{
+		MimeType:     "image/png",
+		Data:         data,
+		SourceURL:    "https://example.com/favicon.png",
+		FetchedAt:    200,
+		RefreshAfter: 300,
+	}); err != nil {
+		t.Fatalf("UpsertFeedIcon() failed: %v", err)
+	}
+
+	// A later failure must keep the cached icon.
+	if err := store.UpsertFeedIcon(feed.ID, UpsertFeedIconParams{RefreshAfter: 400}); err != nil {
+		t.Fatalf("UpsertFeedIcon() failed: %v", err)
+	}
+
+	icon, err := store.GetFeedIcon(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeedIcon() failed: %v", err)
+	}
+	if icon.MimeType != "image/png" || !bytes.Equal(icon.Data, data) || icon.FetchedAt != 200 || icon.RefreshAfter != 400 {
+		t.Errorf("unexpected icon: %+v", icon)
+	}
+
+	feeds, err := store.ListFeeds()
+	if err != nil {
+		t.Fatalf("ListFeeds() failed: %v", err)
+	}
+	if len(feeds) != 1 || !feeds[0].HasIcon {
+		t.Error("expected ListFeeds to report HasIcon")
+	}
+
+	icons, err := store.ListFeedIcons()
+	if err != nil {
+		t.Fatalf("ListFeedIcons() failed: %v", err)
+	}
+	if len(icons) != 1 || icons[0].FeedID != feed.ID {
+		t.Fatalf("unexpected icons: %+v", icons)
+	}
+
+	if err := store.DeleteFeed(feed.ID); err != nil {
+		t.Fatalf("DeleteFeed() failed: %v", err)
+	}
+	icons, err = store.ListFeedIcons()
+	if err != nil {
+		t.Fatalf("ListFeedIcons() failed: %v", err)
+	}
+	if len(icons) != 0 {
+		t.Errorf("expected icon to be removed with feed, got %d", len(icons))
+	}
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// RecordFeedRedirect stores where the latest check ended after permanent
+// redirects and returns how many consecutive checks ended there. An empty
+// target clears the tracked redirect and returns 0.
+func (s *Store) RecordFeedRedirect(id int64, target string) (int64, error) {
+	if target == "" {
+		_, err := s.db.Exec(`
+			UPDATE feed_fetch_state
+			SET redirect_url = '', redirect_count = 0, updated_at = unixepoch()
+			WHERE feed_id = :feed_id AND (redirect_url != '' OR redirect_count != 0)
+		`, sql.Named("feed_id", id))
+		return 0, err
+	}
+
+	var count int64
+	err := s.db.QueryRow(`
+		INSERT INTO feed_fetch_state (feed_id, redirect_url, redirect_count, updated_at)
+		VALUES (:feed_id, :redirect_url, 1, unixepoch())
+		ON CONFLICT(feed_id) DO UPDATE SET
+			redirect_count = CASE
+				WHEN feed_fetch_state.redirect_url = excluded.redirect_url THEN feed_fetch_state.redirect_count + 1
+				ELSE 1
+			END,
+			redirect_url = excluded.redirect_url,
+			updated_at = unixepoch()
+		RETURNING redirect_count
+	`, sql.Named("feed_id", id), sql.Named("redirect_url", target)).Scan(&count)
+	return count, err
+}
+
+// MoveFeedLink replaces the feed link with newLink and records the change in
+// feed_link_history. It returns ErrNotFound when the feed no longer has
+// oldLink (e.g. edited meanwhile) and ErrConflict when another feed already
+// uses newLink. Conditional request state is kept because the resource is the
+// same; only the redirect tracking is reset.
+func (s *Store) MoveFeedLink(id int64, oldLink, newLink string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var taken int
+	if err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM feeds WHERE link = :link AND id != :id)
+	`, sql.Named("link", newLink), sql.Named("id", id)).Scan(&taken); err != nil {
+		return err
+	}
+	if taken == 1 {
+		return fmt.Errorf("%w: feed link", ErrConflict)
+	}
+
+	result, err := tx.Exec(`
+		UPDATE feeds SET link = :new_link, updated_at = unixepoch()
+		WHERE id = :id AND link = :old_link
+	`, sql.Named("new_link", newLink), sql.Named("id", id), sql.Named("old_link", oldLink))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: feed", ErrNotFound)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO feed_link_history (feed_id, old_link, new_link)
+		VALUES (:feed_id, :old_link, :new_link)
+	`, sql.Named("feed_id", id), sql.Named("old_link", oldLink), sql.Named("new_link", newLink)); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE feed_fetch_state
+		SET redirect_url = '', redirect_count = 0, updated_at = unixepoch()
+		WHERE feed_id = :feed_id
+	`, sql.Named("feed_id", id)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListFeedLinkHistory returns the link changes of a feed, newest first.
+func (s *Store) ListFeedLinkHistory(feedID int64) ([]*model.FeedLinkChange, error) {
+	rows, err := s.db.Query(`
+		SELECT id, feed_id, old_link, new_link, created_at
+		FROM feed_link_history
+		WHERE feed_id = :feed_id
+		ORDER BY id DESC
+	`, sql.Named("feed_id", feedID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*model.FeedLinkChange{}
+	for rows.Next() {
+		c := &model.FeedLinkChange{}
+		if err := rows.Scan(&c.ID, &c.FeedID, &c.OldLink, &c.NewLink, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// SuspendFeed suspends a feed on behalf of the puller and records why.
+func (s *Store) SuspendFeed(id int64, reason string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE feeds SET suspended = 1, updated_at = unixepoch() WHERE id = :id
+	`, sql.Named("id", id))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: feed", ErrNotFound)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO feed_fetch_state (feed_id, suspend_reason, updated_at)
+		VALUES (:feed_id, :reason, unixepoch())
+		ON CONFLICT(feed_id) DO UPDATE SET suspend_reason = excluded.suspend_reason, updated_at = unixepoch()
+	`, sql.Named("feed_id", id), sql.Named("reason", reason)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// feedScheduleQuery selects what the scheduler needs to compute a feed's due
+// time, without the item aggregates of ListFeeds.
+const feedScheduleQuery = `
+	SELECT f.id, f.suspended, f.pull_interval, f.max_backoff,
+	       COALESCE(fs.last_checked_at, 0), COALESCE(fs.next_check_at, 0), COALESCE(fs.retry_after_until, 0),
+	       COALESCE(fs.last_error_at, 0), COALESCE(fs.consecutive_failures, 0), COALESCE(fs.adaptive_interval, 0),
+	       EXISTS (SELECT 1 FROM websub_subscriptions ws WHERE ws.feed_id = f.id AND ws.state = 'active' AND ws.lease_expires_at > unixepoch())
+	FROM feeds f
+	LEFT JOIN feed_fetch_state fs ON fs.feed_id = f.id
+`
+
+func scanFeedSchedule(row rowScanner) (*model.Feed, error) {
+	f := &model.Feed{}
+	var suspended, webSubActive int
+	if err := row.Scan(&f.ID, &suspended, &f.PullInterval, &f.MaxBackoff,
+		&f.FetchState.LastCheckedAt, &f.FetchState.NextCheckAt, &f.FetchState.RetryAfterUntil,
+		&f.FetchState.LastErrorAt, &f.FetchState.ConsecutiveFailures, &f.FetchState.AdaptiveInterval,
+		&webSubActive); err != nil {
+		return nil, err
+	}
+	f.Suspended = intToBool(suspended)
+	f.WebSubActive = intToBool(webSubActive)
+	return f, nil
+}
+
+// ListFeedSchedules returns the active (non-suspended) feeds with only the
+// fields used for scheduling set: ID, PullInterval, MaxBackoff, WebSubActive
+// and the timing fields of FetchState.
+func (s *Store) ListFeedSchedules() ([]*model.Feed, error) {
+	rows, err := s.db.Query(feedScheduleQuery + ` WHERE f.suspended = 0 ORDER BY f.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []*model.Feed{}
+	for rows.Next() {
+		f, err := scanFeedSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, f)
+	}
+	return feeds, rows.Err()
+}
+
+// GetFeedSchedule returns one feed as ListFeedSchedules does, including
+// suspended feeds so callers can drop them.
+func (s *Store) GetFeedSchedule(id int64) (*model.Feed, error) {
+	f, err := scanFeedSchedule(s.db.QueryRow(feedScheduleQuery+` WHERE f.id = :id`, sql.Named("id", id)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: feed", ErrNotFound)
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// DeferFeedCheck moves a feed's next check to until unless it is already
+// later, without touching the rest of its fetch state.
+func (s *Store) DeferFeedCheck(id, until int64) error {
+	_, err := s.db.Exec(`
+		INSERT INTO feed_fetch_state (feed_id, next_check_at)
+		VALUES (:feed_id, :until)
+		ON CONFLICT(feed_id) DO UPDATE SET next_check_at = MAX(next_check_at, excluded.next_check_at)
+	`, sql.Named("feed_id", id), sql.Named("until", until))
+	return err
+}
+
+// LowerFeedNextCheck moves a feed's next check to until unless it is already
+// earlier, e.g. after its schedule was shortened.
+func (s *Store) LowerFeedNextCheck(id, until int64) error {
+	_, err := s.db.Exec(`
+		UPDATE feed_fetch_state
+		SET next_check_at = :until, updated_at = unixepoch()
+		WHERE feed_id = :feed_id AND next_check_at > :until
+	`, sql.Named("feed_id", id), sql.Named("until", until))
+	return err
+}
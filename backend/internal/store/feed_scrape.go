@@ -0,0 +1,36 @@
+package store
+
+import (
+	"encoding/json"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// encodeScrapeRecipe returns the feeds.scrape value for recipe; nil is stored
+// as an empty string.
+func encodeScrapeRecipe(recipe *model.ScrapeRecipe) (string, error) {
+	if recipe == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(recipe)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeScrapeRecipe fills f.Scrape and f.Kind from the feeds.scrape column.
+// A recipe that no longer decodes keeps the scraper kind with a nil recipe,
+// so pulls fail visibly instead of parsing the page as a feed.
+func decodeScrapeRecipe(f *model.Feed, raw string) {
+	if raw == "" {
+		f.Kind = model.FeedKindFeed
+		return
+	}
+	f.Kind = model.FeedKindScrape
+	recipe := &model.ScrapeRecipe{}
+	if err := json.Unmarshal([]byte(raw), recipe); err != nil {
+		return
+	}
+	f.Scrape = recipe
+}
@@ -3,9 +3,13 @@ package store
 import (
 	"database/sql"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/secretbox"
 )
 
 func TestListFeeds(t *testing.T) {
@@ -71,6 +75,34 @@ func TestGetFeed(t *testing.T) {
 	}
 }
 
+func TestGetFeedByLinkAndListFeedRefs(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Test Group")
+	first := mustCreateFeed(t, store, group.ID, "First", "https://example.com/a.xml", "https://example.com", "")
+	second := mustCreateFeed(t, store, group.ID, "Second", "https://example.com/b.xml", "https://example.org", "")
+
+	feed, err := store.GetFeedByLink(second.Link)
+	if err != nil {
+		t.Fatalf("GetFeedByLink() failed: %v", err)
+	}
+	if feed.ID != second.ID || feed.Name != "Second" {
+		t.Fatalf("GetFeedByLink() = %+v, want feed %d", feed, second.ID)
+	}
+	if _, err := store.GetFeedByLink("https://example.com/missing.xml"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for unknown link, got %v", err)
+	}
+
+	refs, err := store.ListFeedRefs()
+	if err != nil {
+		t.Fatalf("ListFeedRefs() failed: %v", err)
+	}
+	if len(refs) != 2 || refs[0].ID != first.ID || refs[1].SiteURL != "https://example.org" || refs[1].GroupID != group.ID {
+		t.Fatalf("unexpected refs: %+v, %+v", refs[0], refs[1])
+	}
+}
+
 func TestCreateFeed(t *testing.T) {
 	store, _ := setupTestDB(t)
 	defer closeStore(t, store)
@@ -172,6 +204,58 @@ func TestUpdateFeed(t *testing.T) {
 	if updated2.SiteURL != newSiteURL {
 		t.Error("site_url should remain unchanged")
 	}
+
+	pullInterval := int64(3600)
+	maxBackoff := int64(86400)
+	if err := store.UpdateFeed(feed.ID, UpdateFeedParams{PullInterval: &pullInterval, MaxBackoff: &maxBackoff}); err != nil {
+		t.Fatalf("UpdateFeed() failed: %v", err)
+	}
+
+	updated3, err := store.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed() failed: %v", err)
+	}
+	if updated3.PullInterval != pullInterval || updated3.MaxBackoff != maxBackoff {
+		t.Errorf("expected schedule %d/%d, got %d/%d", pullInterval, maxBackoff, updated3.PullInterval, updated3.MaxBackoff)
+	}
+}
+
+func TestLowerFeedNextCheck(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed.xml", "https://example.com", "")
+
+	checkedAt := time.Now().Unix() - 100
+	weekLater := checkedAt + 7*24*3600
+	if err := store.UpdateFeedFetchSuccess(feed.ID, UpdateFeedFetchSuccessParams{CheckedAt: checkedAt, NextCheckAt: weekLater, HTTPStatus: 200}); err != nil {
+		t.Fatalf("UpdateFeedFetchSuccess() failed: %v", err)
+	}
+
+	if err := store.LowerFeedNextCheck(feed.ID, weekLater+3600); err != nil {
+		t.Fatalf("LowerFeedNextCheck() failed: %v", err)
+	}
+	if got := mustFeedNextCheck(t, store, feed.ID); got != weekLater {
+		t.Fatalf("a later bound moved next_check_at to %d, want %d", got, weekLater)
+	}
+
+	if err := store.LowerFeedNextCheck(feed.ID, checkedAt+300); err != nil {
+		t.Fatalf("LowerFeedNextCheck() failed: %v", err)
+	}
+	if got := mustFeedNextCheck(t, store, feed.ID); got != checkedAt+300 {
+		t.Fatalf("next_check_at = %d, want %d", got, checkedAt+300)
+	}
+}
+
+func mustFeedNextCheck(t *testing.T, store *Store, id int64) int64 {
+	t.Helper()
+
+	feed, err := store.GetFeedSchedule(id)
+	if err != nil {
+		t.Fatalf("GetFeedSchedule() failed: %v", err)
+	}
+	return feed.FetchState.NextCheckAt
 }
 
 func TestUpdateFeedLinkResetsFetchState(t *testing.T) {
@@ -216,6 +300,175 @@ func TestUpdateFeedLinkResetsFetchState(t *testing.T) {
 	}
 }
 
+func TestMoveFeedLinkAfterRedirects(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://old.example.com/feed.xml", "https://example.com", "")
+	other := mustCreateFeed(t, store, group.ID, "Other", "https://taken.example.com/feed.xml", "https://example.com", "")
+
+	for i, target := range []string{"https://a.example.com/feed", "https://a.example.com/feed", "https://b.example.com/feed"} {
+		count, err := store.RecordFeedRedirect(feed.ID, target)
+		if err != nil {
+			t.Fatalf("RecordFeedRedirect() failed: %v", err)
+		}
+		if want := []int64{1, 2, 1}[i]; count != want {
+			t.Fatalf("check %d: expected redirect_count %d, got %d", i, want, count)
+		}
+	}
+
+	if err := store.MoveFeedLink(feed.ID, feed.Link, other.Link); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict for a link used by another feed, got %v", err)
+	}
+	if err := store.MoveFeedLink(feed.ID, "https://stale.example.com", "https://b.example.com/feed"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a stale old link, got %v", err)
+	}
+	if err := store.MoveFeedLink(feed.ID, feed.Link, "https://b.example.com/feed"); err != nil {
+		t.Fatalf("MoveFeedLink() failed: %v", err)
+	}
+
+	moved, err := store.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed() failed: %v", err)
+	}
+	if moved.Link != "https://b.example.com/feed" || moved.FetchState.RedirectURL != "" || moved.FetchState.RedirectCount != 0 {
+		t.Fatalf("unexpected feed after move: link=%q state=%+v", moved.Link, moved.FetchState)
+	}
+
+	history, err := store.ListFeedLinkHistory(feed.ID)
+	if err != nil {
+		t.Fatalf("ListFeedLinkHistory() failed: %v", err)
+	}
+	if len(history) != 1 || history[0].OldLink != "https://old.example.com/feed.xml" || history[0].NewLink != moved.Link {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+}
+
+func TestSuspendFeedRecordsReason(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed.xml", "https://example.com", "")
+
+	if err := store.SuspendFeed(feed.ID, "HTTP 410 Gone"); err != nil {
+		t.Fatalf("SuspendFeed() failed: %v", err)
+	}
+	suspended, err := store.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed() failed: %v", err)
+	}
+	if !suspended.Suspended || suspended.FetchState.SuspendReason != "HTTP 410 Gone" {
+		t.Fatalf("expected suspended feed with reason, got suspended=%v reason=%q", suspended.Suspended, suspended.FetchState.SuspendReason)
+	}
+
+	resume := false
+	if err := store.UpdateFeed(feed.ID, UpdateFeedParams{Suspended: &resume}); err != nil {
+		t.Fatalf("UpdateFeed() failed: %v", err)
+	}
+	resumed, err := store.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed() failed: %v", err)
+	}
+	if resumed.Suspended || resumed.FetchState.SuspendReason != "" {
+		t.Fatalf("expected reason cleared on resume, got suspended=%v reason=%q", resumed.Suspended, resumed.FetchState.SuspendReason)
+	}
+
+	if err := store.SuspendFeed(999999, "gone"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUpdateFeedAuthIsSealed(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed.xml", "https://example.com", "")
+
+	auth := &model.FeedAuth{Username: "alice", Password: "hunter2", Headers: map[string]string{"X-Api-Key": "k1"}}
+	if err := store.UpdateFeed(feed.ID, UpdateFeedParams{Auth: auth}); !errors.Is(err, errNoSecretBox) {
+		t.Fatalf("expected errNoSecretBox without a key, got %v", err)
+	}
+
+	box, err := secretbox.New([]byte("test-key"))
+	if err != nil {
+		t.Fatalf("secretbox.New() failed: %v", err)
+	}
+	store.SetSecretBox(box)
+	if err := store.UpdateFeed(feed.ID, UpdateFeedParams{Auth: auth}); err != nil {
+		t.Fatalf("UpdateFeed() failed: %v", err)
+	}
+
+	var stored string
+	if err := store.db.QueryRow(`SELECT auth FROM feeds WHERE id = ?`, feed.ID).Scan(&stored); err != nil {
+		t.Fatalf("query auth: %v", err)
+	}
+	if stored == "" || strings.Contains(stored, "hunter2") || strings.Contains(stored, "alice") {
+		t.Fatalf("auth stored in plaintext: %q", stored)
+	}
+
+	got, err := store.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed() failed: %v", err)
+	}
+	if !got.HasAuth || got.Auth == nil || got.Auth.Password != "hunter2" || got.Auth.Headers["X-Api-Key"] != "k1" {
+		t.Fatalf("unexpected auth: has=%v auth=%+v", got.HasAuth, got.Auth)
+	}
+
+	if err := store.UpdateFeed(feed.ID, UpdateFeedParams{Auth: &model.FeedAuth{}}); err != nil {
+		t.Fatalf("UpdateFeed(clear) failed: %v", err)
+	}
+	feeds, err := store.ListFeeds()
+	if err != nil {
+		t.Fatalf("ListFeeds() failed: %v", err)
+	}
+	if feeds[0].HasAuth || feeds[0].Auth != nil {
+		t.Fatalf("expected auth cleared, got has=%v auth=%+v", feeds[0].HasAuth, feeds[0].Auth)
+	}
+}
+
+func TestCreateFeedWithParamsStoresRequestSettings(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Group")
+	params := CreateFeedParams{
+		GroupID:   group.ID,
+		Name:      "Scraped",
+		Link:      "https://example.com/news",
+		Auth:      &model.FeedAuth{Username: "alice", Password: "hunter2"},
+		UserAgent: "custom/1.0",
+		Scrape:    &model.ScrapeRecipe{Item: "article", Title: "h2"},
+	}
+	if _, err := store.CreateFeedWithParams(params); !errors.Is(err, errNoSecretBox) {
+		t.Fatalf("expected errNoSecretBox without a key, got %v", err)
+	}
+	if _, err := store.GetFeedByLink(params.Link); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected no feed after failed create, got %v", err)
+	}
+
+	box, err := secretbox.New([]byte("test-key"))
+	if err != nil {
+		t.Fatalf("secretbox.New() failed: %v", err)
+	}
+	store.SetSecretBox(box)
+	feed, err := store.CreateFeedWithParams(params)
+	if err != nil {
+		t.Fatalf("CreateFeedWithParams() failed: %v", err)
+	}
+	if !feed.HasAuth || feed.Auth == nil || feed.Auth.Password != "hunter2" {
+		t.Fatalf("unexpected auth: has=%v auth=%+v", feed.HasAuth, feed.Auth)
+	}
+	if feed.UserAgent != "custom/1.0" {
+		t.Fatalf("UserAgent = %q, want %q", feed.UserAgent, "custom/1.0")
+	}
+	if feed.Kind != model.FeedKindScrape || feed.Scrape == nil || feed.Scrape.Item != "article" {
+		t.Fatalf("unexpected scrape: kind=%q recipe=%+v", feed.Kind, feed.Scrape)
+	}
+}
+
 func TestDeleteFeed(t *testing.T) {
 	store, _ := setupTestDB(t)
 	defer closeStore(t, store)
@@ -599,6 +852,11 @@ func TestBatchCreateFeedsHandlesExistingAndInBatchDuplicates(t *testing.T) {
 	if len(result.Errors) != 2 {
 		t.Fatalf("expected 2 duplicate errors, got %d (%v)", len(result.Errors), result.Errors)
 	}
+	for i, wantConflict := range []bool{true, false, true, false} {
+		if got := errors.Is(result.Outcomes[i], ErrConflict); got != wantConflict {
+			t.Errorf("outcome %d conflict = %v, want %v (%v)", i, got, wantConflict, result.Outcomes[i])
+		}
+	}
 
 	feeds, err := store.ListFeeds()
 	if err != nil {
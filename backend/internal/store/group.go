@@ -81,10 +81,14 @@ func (s *Store) UpdateGroup(id int64, name string) error {
 	return nil
 }
 
-// DeleteGroup removes a group and moves all its feeds to the default group (ID=1).
+// DefaultGroupID is the group created by the initial migration. It cannot be
+// deleted and holds feeds that have no other group.
+const DefaultGroupID int64 = 1
+
+// DeleteGroup removes a group and moves all its feeds to the default group.
 // The default group itself cannot be deleted to ensure all feeds have a valid group.
 func (s *Store) DeleteGroup(id int64) error {
-	if id == 1 {
+	if id == DefaultGroupID {
 		return fmt.Errorf("%w: cannot delete default group", ErrInvalid)
 	}
 
@@ -94,7 +98,8 @@ func (s *Store) DeleteGroup(id int64) error {
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.Exec(`UPDATE feeds SET group_id = 1 WHERE group_id = :id`, sql.Named("id", id)); err != nil {
+	if _, err := tx.Exec(`UPDATE feeds SET group_id = :default_id WHERE group_id = :id`,
+		sql.Named("default_id", DefaultGroupID), sql.Named("id", id)); err != nil {
 		return err
 	}
 
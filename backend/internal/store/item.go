@@ -11,26 +11,35 @@ import (
 
 // ListItemsParams specifies filtering and pagination for item queries.
 //
-// Pointer fields (FeedID, GroupID, Unread) are optional filters - nil means "no filter".
+// Pointer fields (FeedID, GroupID, Unread, Bookmarked) are optional filters - nil means "no filter".
+// Since/Until bound pub_date (exclusive Since, inclusive Until) when non-nil.
 // BeforePubDate/BeforeID form an optional cursor: when both are non-nil, only items
 // ordered before that (pub_date, id) position are returned (nil = first page).
+// With SortAsc the order flips to oldest first and the cursor skips items at or
+// before the position instead.
 // OrderBy accepts "pub_date" (default) or "created_at".
+// OmitContent skips loading item bodies for ID-only listings.
+// Media keeps items with an enclosure of that kind: "audio", "video" or "any" ("" = no filter).
 // Limit = 0 means no limit.
 type ListItemsParams struct {
 	FeedID        *int64
 	GroupID       *int64
 	Unread        *bool
+	Bookmarked    *bool
+	Since         *int64
+	Until         *int64
 	Limit         int
 	BeforePubDate *int64
 	BeforeID      *int64
 	OrderBy       string // "pub_date" or "created_at"
+	SortAsc       bool
+	OmitContent   bool
+	Media         string
 }
 
-func (s *Store) ListItems(params ListItemsParams) ([]*model.Item, error) {
-	query := `
-		SELECT items.id, items.feed_id, items.guid, items.title, items.link, items.content, items.pub_date, items.unread, items.created_at
-		FROM items
-	`
+// itemFilter builds the FROM/WHERE part shared by ListItems and CountItems.
+func itemFilter(params ListItemsParams) (string, []any) {
+	query := ` FROM items`
 	args := []any{}
 
 	// Join feeds table if filtering by GroupID
@@ -45,18 +54,68 @@ func (s *Store) ListItems(params ListItemsParams) ([]*model.Item, error) {
 		args = append(args, sql.Named("feed_id", *params.FeedID))
 	}
 	if params.GroupID != nil {
-		query += ` AND feeds.group_id = :group_id`
+		// Items moved by a rule show in their target group only.
+		query += ` AND COALESCE(items.group_id, feeds.group_id) = :group_id`
 		args = append(args, sql.Named("group_id", *params.GroupID))
 	}
 	if params.Unread != nil {
 		query += ` AND items.unread = :unread`
 		args = append(args, sql.Named("unread", boolToInt(*params.Unread)))
 	}
+	if params.Bookmarked != nil {
+		if *params.Bookmarked {
+			query += ` AND EXISTS (SELECT 1 FROM bookmarks b WHERE b.item_id = items.id)`
+		} else {
+			query += ` AND NOT EXISTS (SELECT 1 FROM bookmarks b WHERE b.item_id = items.id)`
+		}
+	}
+	if params.Since != nil {
+		query += ` AND items.pub_date > :since`
+		args = append(args, sql.Named("since", *params.Since))
+	}
+	if params.Until != nil {
+		query += ` AND items.pub_date <= :until`
+		args = append(args, sql.Named("until", *params.Until))
+	}
+	if params.Media != "" {
+		// media is validated by callers; anything but audio/video matches both.
+		typeFilter := `(json_extract(e.value, '$.type') LIKE 'audio/%' OR json_extract(e.value, '$.type') LIKE 'video/%')`
+		switch params.Media {
+		case "audio":
+			typeFilter = `json_extract(e.value, '$.type') LIKE 'audio/%'`
+		case "video":
+			typeFilter = `json_extract(e.value, '$.type') LIKE 'video/%'`
+		}
+		query += ` AND EXISTS (SELECT 1 FROM json_each(items.enclosures) e WHERE ` + typeFilter + `)`
+	}
+
+	return query, args
+}
+
+func (s *Store) ListItems(params ListItemsParams) ([]*model.Item, error) {
+	contentColumn := "items.content"
+	readableColumn := "items.readable_content"
+	if params.OmitContent {
+		contentColumn = "''"
+		readableColumn = "''"
+	}
+
+	filter, args := itemFilter(params)
+	query := `
+		SELECT items.id, items.feed_id, items.guid, items.title, items.link, ` + contentColumn + `,
+			items.author, items.summary, items.categories, items.enclosures, items.duration, items.episode, items.image_url,
+			` + readableColumn + `, COALESCE((SELECT p.position FROM item_playback p WHERE p.item_id = items.id), 0),
+			items.pub_date, items.unread, COALESCE(items.group_id, 0), items.created_at
+	` + filter
 
 	// Cursor pagination: skip items at or before the cursor position, matching
-	// the ORDER BY (pub_date DESC, id DESC) tie-break semantics.
+	// the ORDER BY (pub_date, id) tie-break semantics.
 	if params.BeforePubDate != nil && params.BeforeID != nil {
-		query += ` AND (items.pub_date < :before_pub_date OR (items.pub_date = :before_pub_date AND items.id < :before_id))`
+		if params.SortAsc {
+			query += ` AND (items.pub_date > :before_pub_date OR (items.pub_date = :before_pub_date AND items.id > :before_id))`
+		} else {
+			query += ` AND (items.pub_date < :before_pub_date OR (items.pub_date = :before_pub_date AND items.id < :before_id))`
+		}
 		args = append(args, sql.Named("before_pub_date", *params.BeforePubDate), sql.Named("before_id", *params.BeforeID))
 	}
 
@@ -65,6 +124,9 @@ func (s *Store) ListItems(params ListItemsParams) ([]*model.Item, error) {
 	if params.OrderBy == "created_at" {
 		orderBy = "items.created_at DESC, items.id DESC"
 	}
+	if params.SortAsc {
+		orderBy = strings.ReplaceAll(orderBy, "DESC", "ASC")
+	}
 	query += ` ORDER BY ` + orderBy
 
 	if params.Limit > 0 {
@@ -82,10 +144,16 @@ func (s *Store) ListItems(params ListItemsParams) ([]*model.Item, error) {
 	for rows.Next() {
 		i := &model.Item{}
 		var unread int
-		if err := rows.Scan(&i.ID, &i.FeedID, &i.GUID, &i.Title, &i.Link, &i.Content, &i.PubDate, &unread, &i.CreatedAt); err != nil {
+		var categories, enclosures string
+		if err := rows.Scan(&i.ID, &i.FeedID, &i.GUID, &i.Title, &i.Link, &i.Content,
+			&i.Author, &i.Summary, &categories, &enclosures, &i.Duration, &i.Episode, &i.ImageURL,
+			&i.ReadableContent, &i.PlaybackPosition, &i.PubDate, &unread, &i.GroupID, &i.CreatedAt); err != nil {
 			return nil, err
 		}
 		i.Unread = intToBool(unread)
+		if err := decodeItemMetadata(i, categories, enclosures); err != nil {
+			return nil, err
+		}
 		items = append(items, i)
 	}
 	return items, rows.Err()
@@ -94,11 +162,16 @@ func (s *Store) ListItems(params ListItemsParams) ([]*model.Item, error) {
 func (s *Store) GetItem(id int64) (*model.Item, error) {
 	i := &model.Item{}
 	var unread int
+	var categories, enclosures string
 	err := s.db.QueryRow(`
-		SELECT id, feed_id, guid, title, link, content, pub_date, unread, created_at
+		SELECT id, feed_id, guid, title, link, content, author, summary, categories, enclosures, duration, episode, image_url,
+			readable_content, COALESCE((SELECT p.position FROM item_playback p WHERE p.item_id = items.id), 0),
+			pub_date, unread, COALESCE(group_id, 0), created_at
 		FROM items
 		WHERE id = :id
-	`, sql.Named("id", id)).Scan(&i.ID, &i.FeedID, &i.GUID, &i.Title, &i.Link, &i.Content, &i.PubDate, &unread, &i.CreatedAt)
+	`, sql.Named("id", id)).Scan(&i.ID, &i.FeedID, &i.GUID, &i.Title, &i.Link, &i.Content,
+		&i.Author, &i.Summary, &categories, &enclosures, &i.Duration, &i.Episode, &i.ImageURL,
+		&i.ReadableContent, &i.PlaybackPosition, &i.PubDate, &unread, &i.GroupID, &i.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("%w: item", ErrNotFound)
@@ -107,6 +180,9 @@ func (s *Store) GetItem(id int64) (*model.Item, error) {
 	}
 
 	i.Unread = intToBool(unread)
+	if err := decodeItemMetadata(i, categories, enclosures); err != nil {
+		return nil, err
+	}
 	return i, nil
 }
 
@@ -129,64 +205,163 @@ func (s *Store) CreateItem(feedID int64, guid, title, link, content string, pubD
 }
 
 type BatchCreateItemInput struct {
-	GUID    string
-	Title   string
-	Link    string
-	Content string
-	PubDate int64
+	GUID       string
+	Title      string
+	Link       string
+	Content    string
+	Author     string
+	Summary    string
+	Categories []string
+	Enclosures []model.Enclosure
+	Duration   int64
+	Episode    int64
+	ImageURL   string
+	PubDate    int64
+	// SanitizeVersion is the sanitize policy applied to Content and Summary.
+	SanitizeVersion int64
+
+	// Ingest rule outcome, applied only when the item is new: Drop records a
+	// tombstone instead of the item, Read stores it read, Bookmark saves it
+	// and GroupID shows it in another group. RuleIDs are credited with a hit.
+	Drop     bool
+	Read     bool
+	Bookmark bool
+	GroupID  int64
+	RuleIDs  []int64
 }
 
 // BatchCreateItemsIgnore inserts items in one transaction and ignores duplicates by (feed_id, guid).
+// GUIDs recorded in item_tombstones by retention pruning are skipped as well, and their tombstones
+// are marked as seen so they outlive the item's presence upstream.
 // Returns the number of newly inserted rows.
 func (s *Store) BatchCreateItemsIgnore(feedID int64, inputs []BatchCreateItemInput) (int, error) {
+	result, err := s.BatchUpsertItems(feedID, model.ItemUpdateIgnore, inputs)
+	return result.Created, err
+}
+
+// BatchUpsertItemsResult counts rows changed by BatchUpsertItems. Dropped
+// counts new items discarded by ingest rules.
+type BatchUpsertItemsResult struct {
+	Created int
+	Updated int
+	Dropped int
+}
+
+// BatchUpsertItems inserts new items like BatchCreateItemsIgnore. With
+// model.ItemUpdateSilent or model.ItemUpdateUnread, existing items whose
+// title, link or content changed are rewritten as well; the previous version
+// is kept in item_revisions and ItemUpdateUnread marks the item unread.
+func (s *Store) BatchUpsertItems(feedID int64, mode string, inputs []BatchCreateItemInput) (BatchUpsertItemsResult, error) {
+	var result BatchUpsertItemsResult
 	if len(inputs) == 0 {
-		return 0, nil
+		return result, nil
 	}
 
 	tx, err := s.db.Begin()
 	if err != nil {
-		return 0, err
+		return result, err
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
-		INSERT INTO items (feed_id, guid, title, link, content, pub_date)
-		VALUES (:feed_id, :guid, :title, :link, :content, :pub_date)
+		INSERT INTO items (feed_id, guid, title, link, content, author, summary, categories, enclosures, duration, episode, image_url, pub_date, sanitize_version, content_hash, unread, group_id)
+		SELECT :feed_id, :guid, :title, :link, :content, :author, :summary, :categories, :enclosures, :duration, :episode, :image_url, :pub_date, :sanitize_version, :content_hash, :unread, :group_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM item_tombstones t WHERE t.feed_id = :feed_id AND t.guid = :guid
+		)
 		ON CONFLICT(feed_id, guid) DO NOTHING
 	`)
 	if err != nil {
-		return 0, err
+		return result, err
 	}
 	defer stmt.Close()
 
-	created := 0
+	seenStmt, err := tx.Prepare(`
+		UPDATE item_tombstones SET seen_at = unixepoch()
+		WHERE feed_id = :feed_id AND guid = :guid AND seen_at < unixepoch()
+	`)
+	if err != nil {
+		return result, err
+	}
+	defer seenStmt.Close()
+
 	for _, input := range inputs {
-		result, err := stmt.Exec(
+		if _, err := seenStmt.Exec(sql.Named("feed_id", feedID), sql.Named("guid", input.GUID)); err != nil {
+			return result, err
+		}
+		if input.Drop {
+			dropped, err := dropNewItem(tx, feedID, input)
+			if err != nil {
+				return result, err
+			}
+			if dropped {
+				result.Dropped++
+			}
+			continue
+		}
+
+		categories, err := encodeJSONList(input.Categories)
+		if err != nil {
+			return result, fmt.Errorf("encode item categories: %w", err)
+		}
+		enclosures, err := encodeJSONList(input.Enclosures)
+		if err != nil {
+			return result, fmt.Errorf("encode item enclosures: %w", err)
+		}
+		hash := itemContentHash(input.Title, input.Link, input.Content)
+
+		res, err := stmt.Exec(
 			sql.Named("feed_id", feedID),
 			sql.Named("guid", input.GUID),
 			sql.Named("title", input.Title),
 			sql.Named("link", input.Link),
 			sql.Named("content", input.Content),
+			sql.Named("author", input.Author),
+			sql.Named("summary", input.Summary),
+			sql.Named("categories", categories),
+			sql.Named("enclosures", enclosures),
+			sql.Named("duration", input.Duration),
+			sql.Named("episode", input.Episode),
+			sql.Named("image_url", input.ImageURL),
 			sql.Named("pub_date", input.PubDate),
+			sql.Named("sanitize_version", input.SanitizeVersion),
+			sql.Named("content_hash", hash),
+			sql.Named("unread", boolToInt(!input.Read)),
+			sql.Named("group_id", nullID(input.GroupID)),
 		)
 		if err != nil {
-			return 0, err
+			return result, err
 		}
 
-		affected, err := result.RowsAffected()
+		affected, err := res.RowsAffected()
 		if err != nil {
-			return 0, err
+			return result, err
 		}
 		if affected > 0 {
-			created++
+			result.Created++
+			if err := applyNewItemRules(tx, feedID, res, input); err != nil {
+				return result, err
+			}
+			continue
+		}
+		if mode != model.ItemUpdateSilent && mode != model.ItemUpdateUnread {
+			continue
+		}
+
+		updated, err := updateChangedItem(tx, feedID, input, hash, categories, enclosures, mode == model.ItemUpdateUnread)
+		if err != nil {
+			return result, err
+		}
+		if updated {
+			result.Updated++
 		}
 	}
 
 	if err := tx.Commit(); err != nil {
-		return 0, err
+		return result, err
 	}
 
-	return created, nil
+	return result, nil
 }
 
 func (s *Store) UpdateItemUnread(id int64, unread bool) error {
@@ -258,11 +433,7 @@ func (s *Store) MarkGroupAsRead(groupID int64) error {
 	_, err := s.db.Exec(`
 		UPDATE items
 		SET unread = 0
-		WHERE feed_id IN (
-			SELECT id
-			FROM feeds
-			WHERE group_id = :group_id
-		)
+		WHERE COALESCE(group_id, (SELECT f.group_id FROM feeds f WHERE f.id = items.feed_id)) = :group_id
 	`, sql.Named("group_id", groupID))
 	return err
 }
@@ -281,11 +452,7 @@ func (s *Store) MarkGroupAsReadBefore(groupID, before int64) error {
 	_, err := s.db.Exec(`
 		UPDATE items
 		SET unread = 0
-		WHERE feed_id IN (
-			SELECT id
-			FROM feeds
-			WHERE group_id = :group_id
-		)
+		WHERE COALESCE(group_id, (SELECT f.group_id FROM feeds f WHERE f.id = items.feed_id)) = :group_id
 		  AND (CASE WHEN pub_date > 0 THEN pub_date ELSE created_at END) <= :before
 	`, sql.Named("group_id", groupID), sql.Named("before", before))
 	return err
@@ -300,6 +467,32 @@ func (s *Store) MarkAllAsReadBefore(before int64) error {
 	return err
 }
 
+// ListUnreadCounts returns the number of unread items of every feed that has
+// any, ordered by feed ID.
+func (s *Store) ListUnreadCounts() ([]*model.FeedUnreadCount, error) {
+	rows, err := s.db.Query(`
+		SELECT feed_id, COUNT(*)
+		FROM items
+		WHERE unread = 1
+		GROUP BY feed_id
+		ORDER BY feed_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []*model.FeedUnreadCount{}
+	for rows.Next() {
+		c := &model.FeedUnreadCount{}
+		if err := rows.Scan(&c.FeedID, &c.UnreadCount); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
 func (s *Store) ListUnreadItemIDs() ([]int64, error) {
 	rows, err := s.db.Query(`
 		SELECT id
@@ -334,7 +527,8 @@ type ListFeverItemsParams struct {
 
 func (s *Store) ListFeverItems(params ListFeverItemsParams) ([]*model.Item, error) {
 	query := `
-		SELECT id, feed_id, guid, title, link, content, pub_date, unread, created_at
+		SELECT id, feed_id, guid, title, link, content, author, summary, categories, enclosures, duration, episode, image_url,
+			readable_content, pub_date, unread, created_at
 		FROM items
 		WHERE 1=1
 	`
@@ -381,10 +575,16 @@ func (s *Store) ListFeverItems(params ListFeverItemsParams) ([]*model.Item, erro
 	for rows.Next() {
 		i := &model.Item{}
 		var unread int
-		if err := rows.Scan(&i.ID, &i.FeedID, &i.GUID, &i.Title, &i.Link, &i.Content, &i.PubDate, &unread, &i.CreatedAt); err != nil {
+		var categories, enclosures string
+		if err := rows.Scan(&i.ID, &i.FeedID, &i.GUID, &i.Title, &i.Link, &i.Content,
+			&i.Author, &i.Summary, &categories, &enclosures, &i.Duration, &i.Episode, &i.ImageURL,
+			&i.ReadableContent, &i.PubDate, &unread, &i.CreatedAt); err != nil {
 			return nil, err
 		}
 		i.Unread = intToBool(unread)
+		if err := decodeItemMetadata(i, categories, enclosures); err != nil {
+			return nil, err
+		}
 		items = append(items, i)
 	}
 
@@ -398,6 +598,33 @@ func (s *Store) ItemExists(feedID int64, guid string) (bool, error) {
 	return exists, err
 }
 
+// ListFeedPublishTimes returns publish timestamps of the newest items of a
+// feed, newest first. pub_date falls back to created_at when unset.
+func (s *Store) ListFeedPublishTimes(feedID int64, limit int) ([]int64, error) {
+	rows, err := s.db.Query(`
+		SELECT CASE WHEN pub_date > 0 THEN pub_date ELSE created_at END AS published_at
+		FROM items
+		WHERE feed_id = :feed_id
+		ORDER BY published_at DESC
+		LIMIT :limit
+	`, sql.Named("feed_id", feedID), sql.Named("limit", limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := []int64{}
+	for rows.Next() {
+		var ts int64
+		if err := rows.Scan(&ts); err != nil {
+			return nil, err
+		}
+		times = append(times, ts)
+	}
+
+	return times, rows.Err()
+}
+
 type SearchItemResult struct {
 	ID      int64  `json:"id"`
 	FeedID  int64  `json:"feed_id"`
@@ -480,29 +707,126 @@ func (s *Store) searchItemsLike(query string, limit int) ([]*SearchItemResult, e
 
 // CountItems returns the total count of items matching the filter criteria.
 func (s *Store) CountItems(params ListItemsParams) (int, error) {
-	query := `SELECT COUNT(*) FROM items`
-	args := []any{}
+	filter, args := itemFilter(params)
 
-	if params.GroupID != nil {
-		query += ` INNER JOIN feeds ON items.feed_id = feeds.id`
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*)`+filter, args...).Scan(&count)
+	return count, err
+}
+
+// UpdateItemPlayback saves the media playback position (seconds) of an item.
+func (s *Store) UpdateItemPlayback(id, position int64) error {
+	result, err := s.db.Exec(`
+		INSERT INTO item_playback (item_id, position, updated_at)
+		SELECT id, :position, unixepoch() FROM items WHERE id = :id
+		ON CONFLICT(item_id) DO UPDATE SET position = excluded.position, updated_at = excluded.updated_at
+	`, sql.Named("id", id), sql.Named("position", position))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
 	}
+	if rows == 0 {
+		return fmt.Errorf("%w: item", ErrNotFound)
+	}
+	return nil
+}
 
-	query += ` WHERE 1=1`
+// ListItemsPendingReadable returns the newest items of a feed with a link and
+// no full-text extraction attempt yet. Only ID, FeedID and Link are set.
+func (s *Store) ListItemsPendingReadable(feedID int64, limit int) ([]*model.Item, error) {
+	rows, err := s.db.Query(`
+		SELECT id, feed_id, link
+		FROM items
+		WHERE feed_id = :feed_id AND readable_fetched_at = 0 AND link != ''
+		ORDER BY created_at DESC, id DESC
+		LIMIT :limit
+	`, sql.Named("feed_id", feedID), sql.Named("limit", limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	if params.FeedID != nil {
-		query += ` AND items.feed_id = :feed_id`
-		args = append(args, sql.Named("feed_id", *params.FeedID))
+	items := []*model.Item{}
+	for rows.Next() {
+		i := &model.Item{}
+		if err := rows.Scan(&i.ID, &i.FeedID, &i.Link); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
 	}
-	if params.GroupID != nil {
-		query += ` AND feeds.group_id = :group_id`
-		args = append(args, sql.Named("group_id", *params.GroupID))
+	return items, rows.Err()
+}
+
+// UpdateItemReadable stores the extracted article of an item. An empty content
+// records a failed attempt so background extraction does not retry it.
+func (s *Store) UpdateItemReadable(id int64, content string, fetchedAt int64) error {
+	result, err := s.db.Exec(`
+		UPDATE items SET readable_content = :content, readable_fetched_at = :fetched_at WHERE id = :id
+	`, sql.Named("content", content), sql.Named("fetched_at", fetchedAt), sql.Named("id", id))
+	if err != nil {
+		return err
 	}
-	if params.Unread != nil {
-		query += ` AND items.unread = :unread`
-		args = append(args, sql.Named("unread", boolToInt(*params.Unread)))
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: item", ErrNotFound)
 	}
+	return nil
+}
 
-	var count int
-	err := s.db.QueryRow(query, args...).Scan(&count)
-	return count, err
+// ListItemsBelowSanitizeVersion returns items sanitized with an older policy
+// than version, in id order after afterID. Only ID, Link, Content, Summary and
+// ReadableContent are set.
+func (s *Store) ListItemsBelowSanitizeVersion(version, afterID int64, limit int) ([]*model.Item, error) {
+	rows, err := s.db.Query(`
+		SELECT id, link, content, summary, readable_content
+		FROM items
+		WHERE sanitize_version < :version AND id > :after_id
+		ORDER BY id
+		LIMIT :limit
+	`, sql.Named("version", version), sql.Named("after_id", afterID), sql.Named("limit", limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*model.Item{}
+	for rows.Next() {
+		i := &model.Item{}
+		if err := rows.Scan(&i.ID, &i.Link, &i.Content, &i.Summary, &i.ReadableContent); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	return items, rows.Err()
+}
+
+// UpdateItemSanitized replaces the HTML fields of an item with their
+// re-sanitized versions and records the policy version.
+func (s *Store) UpdateItemSanitized(id int64, content, summary, readableContent string, version int64) error {
+	// The stored hash no longer matches the rewritten content; it is
+	// recomputed on the next comparison.
+	result, err := s.db.Exec(`
+		UPDATE items
+		SET content = :content, summary = :summary, readable_content = :readable_content, sanitize_version = :version,
+		    content_hash = ''
+		WHERE id = :id
+	`, sql.Named("content", content), sql.Named("summary", summary), sql.Named("readable_content", readableContent),
+		sql.Named("version", version), sql.Named("id", id))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: item", ErrNotFound)
+	}
+	return nil
 }
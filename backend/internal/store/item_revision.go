@@ -0,0 +1,117 @@
+package store
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+// maxItemRevisions bounds the stored history per item; older revisions are
+// dropped when an update adds a new one.
+const maxItemRevisions = 10
+
+// itemContentHash identifies the user-visible version of an item.
+func itemContentHash(title, link, content string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + link + "\x00" + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// updateChangedItem rewrites an existing item when its hash differs from the
+// incoming one, recording the previous version. Tombstoned GUIDs have no row
+// and are left alone. Reports whether the item was updated.
+func updateChangedItem(tx *sql.Tx, feedID int64, input BatchCreateItemInput, hash, categories, enclosures string, markUnread bool) (bool, error) {
+	var id int64
+	var title, link, content, storedHash string
+	err := tx.QueryRow(`
+		SELECT id, title, link, content, content_hash FROM items WHERE feed_id = :feed_id AND guid = :guid
+	`, sql.Named("feed_id", feedID), sql.Named("guid", input.GUID)).Scan(&id, &title, &link, &content, &storedHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if storedHash == "" {
+		storedHash = itemContentHash(title, link, content)
+	}
+	if storedHash == hash {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO item_revisions (item_id, title, link, content, content_hash)
+		VALUES (:item_id, :title, :link, :content, :content_hash)
+	`, sql.Named("item_id", id), sql.Named("title", title), sql.Named("link", link),
+		sql.Named("content", content), sql.Named("content_hash", storedHash)); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM item_revisions
+		WHERE item_id = :item_id AND id NOT IN (
+			SELECT id FROM item_revisions WHERE item_id = :item_id ORDER BY id DESC LIMIT :keep
+		)
+	`, sql.Named("item_id", id), sql.Named("keep", maxItemRevisions)); err != nil {
+		return false, err
+	}
+
+	// A new link invalidates the extracted full text; pub_date is kept so
+	// updated items do not jump to the top of the list.
+	if _, err := tx.Exec(`
+		UPDATE items
+		SET title = :title, link = :link, content = :content, author = :author, summary = :summary,
+		    categories = :categories, enclosures = :enclosures, duration = :duration, episode = :episode,
+		    image_url = :image_url, sanitize_version = :sanitize_version, content_hash = :content_hash,
+		    readable_content = CASE WHEN link = :link THEN readable_content ELSE '' END,
+		    readable_fetched_at = CASE WHEN link = :link THEN readable_fetched_at ELSE 0 END,
+		    unread = CASE WHEN :mark_unread = 1 THEN 1 ELSE unread END
+		WHERE id = :id
+	`,
+		sql.Named("title", input.Title),
+		sql.Named("link", input.Link),
+		sql.Named("content", input.Content),
+		sql.Named("author", input.Author),
+		sql.Named("summary", input.Summary),
+		sql.Named("categories", categories),
+		sql.Named("enclosures", enclosures),
+		sql.Named("duration", input.Duration),
+		sql.Named("episode", input.Episode),
+		sql.Named("image_url", input.ImageURL),
+		sql.Named("sanitize_version", input.SanitizeVersion),
+		sql.Named("content_hash", hash),
+		sql.Named("mark_unread", boolToInt(markUnread)),
+		sql.Named("id", id),
+	); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ListItemRevisions returns the recorded previous versions of an item,
+// newest first.
+func (s *Store) ListItemRevisions(itemID int64) ([]*model.ItemRevision, error) {
+	rows, err := s.db.Query(`
+		SELECT id, item_id, title, link, content, created_at
+		FROM item_revisions
+		WHERE item_id = :item_id
+		ORDER BY id DESC
+	`, sql.Named("item_id", itemID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*model.ItemRevision{}
+	for rows.Next() {
+		r := &model.ItemRevision{}
+		if err := rows.Scan(&r.ID, &r.ItemID, &r.Title, &r.Link, &r.Content, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
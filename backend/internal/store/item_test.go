@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/0x2E/fusion/internal/model"
 )
 
 func TestListItems(t *testing.T) {
@@ -223,6 +225,74 @@ func TestListItemsFilterByGroupID(t *testing.T) {
 	}
 }
 
+func TestListItemsBookmarkTimeAndAscendingFilters(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Test Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed", "https://example.com", "")
+
+	item1 := mustCreateItem(t, store, feed.ID, "guid-1", "Item 1", "https://example.com/1", "Content 1", 100)
+	item2 := mustCreateItem(t, store, feed.ID, "guid-2", "Item 2", "https://example.com/2", "Content 2", 200)
+	item3 := mustCreateItem(t, store, feed.ID, "guid-3", "Item 3", "https://example.com/3", "Content 3", 300)
+
+	if _, err := store.CreateBookmark(&item2.ID, &feed.ID, item2.Link, item2.Title, item2.Content, item2.PubDate, feed.Name, 0); err != nil {
+		t.Fatalf("CreateBookmark() failed: %v", err)
+	}
+
+	t.Run("bookmarked only", func(t *testing.T) {
+		bookmarked := true
+		items, err := store.ListItems(ListItemsParams{Bookmarked: &bookmarked})
+		if err != nil {
+			t.Fatalf("ListItems() failed: %v", err)
+		}
+		if len(items) != 1 || items[0].ID != item2.ID {
+			t.Errorf("expected only item2, got %+v", items)
+		}
+
+		count, err := store.CountItems(ListItemsParams{Bookmarked: &bookmarked})
+		if err != nil {
+			t.Fatalf("CountItems() failed: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected count 1, got %d", count)
+		}
+	})
+
+	t.Run("pub_date window", func(t *testing.T) {
+		since, until := int64(100), int64(200)
+		items, err := store.ListItems(ListItemsParams{Since: &since, Until: &until})
+		if err != nil {
+			t.Fatalf("ListItems() failed: %v", err)
+		}
+		if len(items) != 1 || items[0].ID != item2.ID {
+			t.Errorf("expected only item2 in (100, 200], got %+v", items)
+		}
+	})
+
+	t.Run("ascending with cursor", func(t *testing.T) {
+		page1, err := store.ListItems(ListItemsParams{SortAsc: true, Limit: 2, OmitContent: true})
+		if err != nil {
+			t.Fatalf("ListItems() failed: %v", err)
+		}
+		if len(page1) != 2 || page1[0].ID != item1.ID || page1[1].ID != item2.ID {
+			t.Fatalf("expected first ascending page [item1, item2], got %+v", page1)
+		}
+		if page1[0].Content != "" {
+			t.Errorf("expected content to be omitted, got %q", page1[0].Content)
+		}
+
+		last := page1[len(page1)-1]
+		page2, err := store.ListItems(ListItemsParams{SortAsc: true, Limit: 2, BeforePubDate: &last.PubDate, BeforeID: &last.ID})
+		if err != nil {
+			t.Fatalf("ListItems() failed: %v", err)
+		}
+		if len(page2) != 1 || page2[0].ID != item3.ID {
+			t.Errorf("expected second ascending page [item3], got %+v", page2)
+		}
+	})
+}
+
 func TestGetItem(t *testing.T) {
 	store, _ := setupTestDB(t)
 	defer closeStore(t, store)
@@ -280,6 +350,57 @@ func TestCreateItem(t *testing.T) {
 	}
 }
 
+func TestBatchCreateItemsIgnoreStoresMetadata(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Test Group")
+	feed := mustCreateFeed(t, store, group.ID, "Podcast", "https://example.com/podcast", "https://example.com", "")
+
+	enclosure := model.Enclosure{URL: "https://example.com/ep1.mp3", Type: "audio/mpeg", Length: 1234}
+	if _, err := store.BatchCreateItemsIgnore(feed.ID, []BatchCreateItemInput{
+		{
+			GUID:       "ep1",
+			Title:      "Episode 1",
+			Author:     "Jane Doe",
+			Summary:    "First episode",
+			Categories: []string{"tech", "news"},
+			Enclosures: []model.Enclosure{enclosure},
+			PubDate:    100,
+		},
+		{GUID: "plain", Title: "Plain", PubDate: 200},
+	}); err != nil {
+		t.Fatalf("BatchCreateItemsIgnore() failed: %v", err)
+	}
+
+	items, err := store.ListItems(ListItemsParams{FeedID: &feed.ID, SortAsc: true})
+	if err != nil {
+		t.Fatalf("ListItems() failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	got, err := store.GetItem(items[0].ID)
+	if err != nil {
+		t.Fatalf("GetItem() failed: %v", err)
+	}
+	if got.Author != "Jane Doe" || got.Summary != "First episode" {
+		t.Errorf("unexpected author/summary: %q / %q", got.Author, got.Summary)
+	}
+	if len(got.Categories) != 2 || got.Categories[0] != "tech" || got.Categories[1] != "news" {
+		t.Errorf("unexpected categories: %v", got.Categories)
+	}
+	if len(got.Enclosures) != 1 || got.Enclosures[0] != enclosure {
+		t.Errorf("unexpected enclosures: %+v", got.Enclosures)
+	}
+
+	// Items without metadata decode to empty lists rather than null.
+	if plain := items[1]; plain.Categories == nil || len(plain.Categories) != 0 || plain.Enclosures == nil || len(plain.Enclosures) != 0 {
+		t.Errorf("expected empty metadata lists, got categories=%v enclosures=%v", plain.Categories, plain.Enclosures)
+	}
+}
+
 func TestUpdateItemUnread(t *testing.T) {
 	store, _ := setupTestDB(t)
 	defer closeStore(t, store)
@@ -569,3 +690,103 @@ func TestSearchItemsFTSReflectsUpdateAndDelete(t *testing.T) {
 		t.Fatalf("expected 0 results for beta after delete, got %d", len(results))
 	}
 }
+
+func TestBatchUpsertItemsUpdateModes(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Test Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed", "", "")
+
+	original := BatchCreateItemInput{GUID: "g1", Title: "Titel", Link: "https://example.com/1", Content: "v1", PubDate: 100}
+	if _, err := store.BatchUpsertItems(feed.ID, model.ItemUpdateIgnore, []BatchCreateItemInput{original}); err != nil {
+		t.Fatalf("BatchUpsertItems() failed: %v", err)
+	}
+	items, err := store.ListItems(ListItemsParams{FeedID: &feed.ID})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("ListItems() = %d items, %v", len(items), err)
+	}
+	id := items[0].ID
+	if err := store.UpdateItemUnread(id, false); err != nil {
+		t.Fatalf("UpdateItemUnread() failed: %v", err)
+	}
+
+	corrected := original
+	corrected.Title = "Title"
+	// Ignore mode keeps the first version.
+	result, err := store.BatchUpsertItems(feed.ID, model.ItemUpdateIgnore, []BatchCreateItemInput{corrected})
+	if err != nil || result.Updated != 0 {
+		t.Fatalf("ignore mode: result=%+v err=%v", result, err)
+	}
+
+	result, err = store.BatchUpsertItems(feed.ID, model.ItemUpdateSilent, []BatchCreateItemInput{corrected})
+	if err != nil || result.Created != 0 || result.Updated != 1 {
+		t.Fatalf("update mode: result=%+v err=%v", result, err)
+	}
+	got, err := store.GetItem(id)
+	if err != nil {
+		t.Fatalf("GetItem() failed: %v", err)
+	}
+	if got.Title != "Title" || got.Unread || got.PubDate != 100 {
+		t.Fatalf("unexpected silent update: title=%q unread=%v pub_date=%d", got.Title, got.Unread, got.PubDate)
+	}
+
+	// Unchanged content is not rewritten again.
+	result, err = store.BatchUpsertItems(feed.ID, model.ItemUpdateSilent, []BatchCreateItemInput{corrected})
+	if err != nil || result.Updated != 0 {
+		t.Fatalf("unchanged item: result=%+v err=%v", result, err)
+	}
+
+	edited := corrected
+	edited.Content = "v2"
+	result, err = store.BatchUpsertItems(feed.ID, model.ItemUpdateUnread, []BatchCreateItemInput{edited})
+	if err != nil || result.Updated != 1 {
+		t.Fatalf("update_unread mode: result=%+v err=%v", result, err)
+	}
+	got, err = store.GetItem(id)
+	if err != nil {
+		t.Fatalf("GetItem() failed: %v", err)
+	}
+	if got.Content != "v2" || !got.Unread {
+		t.Fatalf("expected updated unread item, got content=%q unread=%v", got.Content, got.Unread)
+	}
+	if results, err := store.SearchItems("v2", 10); err != nil || len(results) != 1 {
+		t.Fatalf("expected FTS to index the update, got %d results (%v)", len(results), err)
+	}
+
+	revisions, err := store.ListItemRevisions(id)
+	if err != nil {
+		t.Fatalf("ListItemRevisions() failed: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Content != "v1" || revisions[0].Title != "Title" || revisions[1].Title != "Titel" {
+		t.Fatalf("unexpected revisions: %+v", revisions)
+	}
+}
+
+func TestBatchUpsertItemsBoundsRevisions(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Test Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed", "", "")
+	// Items created before hashing existed are compared by their stored fields.
+	item := mustCreateItem(t, store, feed.ID, "changelog", "Changelog", "https://example.com/changelog", "r0", 100)
+
+	for i := 1; i <= maxItemRevisions+5; i++ {
+		input := BatchCreateItemInput{GUID: "changelog", Title: "Changelog", Link: "https://example.com/changelog", Content: fmt.Sprintf("r%d", i)}
+		if _, err := store.BatchUpsertItems(feed.ID, model.ItemUpdateSilent, []BatchCreateItemInput{input}); err != nil {
+			t.Fatalf("BatchUpsertItems() failed: %v", err)
+		}
+	}
+
+	revisions, err := store.ListItemRevisions(item.ID)
+	if err != nil {
+		t.Fatalf("ListItemRevisions() failed: %v", err)
+	}
+	if len(revisions) != maxItemRevisions {
+		t.Fatalf("expected %d revisions, got %d", maxItemRevisions, len(revisions))
+	}
+	if want := fmt.Sprintf("r%d", maxItemRevisions+4); revisions[0].Content != want {
+		t.Fatalf("newest revision = %q, want %q", revisions[0].Content, want)
+	}
+}
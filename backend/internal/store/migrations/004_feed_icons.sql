@@ -0,0 +1,12 @@
+-- Cache feed favicons discovered from the site or feed. A row is also written
+-- when discovery fails (empty data) so refresh_after throttles retries.
+
+CREATE TABLE IF NOT EXISTS feed_icons (
+	feed_id       INTEGER PRIMARY KEY REFERENCES feeds(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	mime_type     TEXT NOT NULL DEFAULT '',
+	data          BLOB NOT NULL DEFAULT x'',
+	source_url    TEXT NOT NULL DEFAULT '',
+	fetched_at    INTEGER NOT NULL DEFAULT 0,
+	refresh_after INTEGER NOT NULL DEFAULT 0,
+	updated_at    INTEGER NOT NULL DEFAULT (unixepoch())
+);
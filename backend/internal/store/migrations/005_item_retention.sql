@@ -0,0 +1,13 @@
+-- Per-feed retention overrides. -1 inherits the global default
+-- (FUSION_RETENTION_DAYS / FUSION_RETENTION_MAX_ITEMS), 0 keeps items forever.
+ALTER TABLE feeds ADD COLUMN retention_days INTEGER NOT NULL DEFAULT -1;
+ALTER TABLE feeds ADD COLUMN retention_max_items INTEGER NOT NULL DEFAULT -1;
+
+-- GUIDs of pruned items. BatchCreateItemsIgnore skips them so entries still
+-- present in the upstream feed are not re-inserted as unread.
+CREATE TABLE IF NOT EXISTS item_tombstones (
+	feed_id   INTEGER NOT NULL REFERENCES feeds(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	guid      TEXT NOT NULL,
+	pruned_at INTEGER NOT NULL DEFAULT (unixepoch()),
+	PRIMARY KEY (feed_id, guid)
+);
@@ -0,0 +1,4 @@
+-- Per-feed pull schedule overrides in seconds. 0 inherits the global default
+-- (FUSION_PULL_INTERVAL / FUSION_PULL_MAX_BACKOFF).
+ALTER TABLE feeds ADD COLUMN pull_interval INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feeds ADD COLUMN max_backoff INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Adaptive polling cadence. adaptive_interval is the interval (seconds) used
+-- for the latest schedule, publish_interval the median gap between recent
+-- items, and not_modified_ratio the smoothed share of unchanged checks.
+ALTER TABLE feed_fetch_state ADD COLUMN adaptive_interval INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feed_fetch_state ADD COLUMN publish_interval INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feed_fetch_state ADD COLUMN not_modified_ratio REAL NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- WebSub (PubSubHubbub) subscriptions, one per feed. state is one of
+-- 'pending' (awaiting intent verification), 'active' or 'failed'.
+CREATE TABLE IF NOT EXISTS websub_subscriptions (
+	feed_id          INTEGER PRIMARY KEY REFERENCES feeds(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	hub_url          TEXT NOT NULL,
+	topic_url        TEXT NOT NULL,
+	secret           TEXT NOT NULL,
+	state            TEXT NOT NULL DEFAULT 'pending',
+	lease_seconds    INTEGER NOT NULL DEFAULT 0,
+	lease_expires_at INTEGER NOT NULL DEFAULT 0,
+	last_error       TEXT NOT NULL DEFAULT '',
+	created_at       INTEGER NOT NULL DEFAULT (unixepoch()),
+	updated_at       INTEGER NOT NULL DEFAULT (unixepoch())
+);
+
+CREATE INDEX IF NOT EXISTS idx_websub_subscriptions_lease ON websub_subscriptions(state, lease_expires_at);
@@ -0,0 +1,6 @@
+-- Item metadata captured from feeds. categories and enclosures are JSON arrays
+-- (["tag", ...] and [{"url", "type", "length"}, ...]).
+ALTER TABLE items ADD COLUMN author TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN summary TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN categories TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE items ADD COLUMN enclosures TEXT NOT NULL DEFAULT '[]';
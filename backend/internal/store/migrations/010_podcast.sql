@@ -0,0 +1,13 @@
+-- Podcast metadata from the iTunes namespace (JSON Feed attachments provide
+-- the duration as well). duration is in seconds, 0 when unknown.
+ALTER TABLE items ADD COLUMN duration INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE items ADD COLUMN episode INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE items ADD COLUMN image_url TEXT NOT NULL DEFAULT '';
+
+-- Playback positions live outside items so frequent progress updates do not
+-- rewrite the full-text index.
+CREATE TABLE IF NOT EXISTS item_playback (
+	item_id    INTEGER PRIMARY KEY REFERENCES items(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	position   INTEGER NOT NULL DEFAULT 0,
+	updated_at INTEGER NOT NULL DEFAULT (unixepoch())
+);
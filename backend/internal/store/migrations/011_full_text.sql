@@ -0,0 +1,6 @@
+-- Per-feed opt-in full-text extraction. Extracted article bodies are stored
+-- next to the feed-provided content; readable_fetched_at > 0 marks items that
+-- were attempted (an empty readable_content then means extraction failed).
+ALTER TABLE feeds ADD COLUMN full_text INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE items ADD COLUMN readable_content TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN readable_fetched_at INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Version of the HTML sanitization policy applied to stored content. Rows
+-- below the current version are re-sanitized in the background; 0 marks
+-- content stored before server-side sanitization existed.
+ALTER TABLE items ADD COLUMN sanitize_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE bookmarks ADD COLUMN sanitize_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,21 @@
+-- Per-feed handling of items that change upstream after ingestion:
+-- 'ignore' keeps the first version, 'update' rewrites the row silently and
+-- 'update_unread' also marks it unread again.
+ALTER TABLE feeds ADD COLUMN update_mode TEXT NOT NULL DEFAULT 'ignore';
+
+-- Hash of the stored title, link and content; '' for rows ingested before
+-- hashing existed or rewritten by re-sanitization (computed on demand).
+ALTER TABLE items ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+
+-- Previous versions of updated items, bounded per item by the store.
+CREATE TABLE IF NOT EXISTS item_revisions (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_id      INTEGER NOT NULL REFERENCES items(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	title        TEXT NOT NULL,
+	link         TEXT NOT NULL,
+	content      TEXT NOT NULL,
+	content_hash TEXT NOT NULL,
+	created_at   INTEGER NOT NULL DEFAULT (unixepoch())
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_revisions_item_id ON item_revisions(item_id, id);
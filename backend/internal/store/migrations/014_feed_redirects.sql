@@ -0,0 +1,18 @@
+-- Permanent redirect tracking: the final URL of the latest all-301/308 chain
+-- and how many consecutive checks ended there.
+ALTER TABLE feed_fetch_state ADD COLUMN redirect_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE feed_fetch_state ADD COLUMN redirect_count INTEGER NOT NULL DEFAULT 0;
+
+-- Why the puller suspended the feed (e.g. HTTP 410); '' for manual suspends.
+ALTER TABLE feed_fetch_state ADD COLUMN suspend_reason TEXT NOT NULL DEFAULT '';
+
+-- Links replaced after a stable permanent redirect.
+CREATE TABLE IF NOT EXISTS feed_link_history (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	feed_id    INTEGER NOT NULL REFERENCES feeds(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	old_link   TEXT NOT NULL,
+	new_link   TEXT NOT NULL,
+	created_at INTEGER NOT NULL DEFAULT (unixepoch())
+);
+
+CREATE INDEX IF NOT EXISTS idx_feed_link_history_feed_id ON feed_link_history(feed_id, id);
@@ -0,0 +1,3 @@
+-- Per-feed request credentials and headers as a sealed (AES-GCM) JSON
+-- document; '' when the feed needs none.
+ALTER TABLE feeds ADD COLUMN auth TEXT NOT NULL DEFAULT '';
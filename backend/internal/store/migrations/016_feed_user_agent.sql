@@ -0,0 +1,2 @@
+-- Per-feed User-Agent override; '' uses FUSION_USER_AGENT.
+ALTER TABLE feeds ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
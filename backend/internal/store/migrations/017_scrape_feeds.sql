@@ -0,0 +1,2 @@
+-- Scraping recipe (JSON) of HTML scraper feeds; '' for regular feeds.
+ALTER TABLE feeds ADD COLUMN scrape TEXT NOT NULL DEFAULT '';
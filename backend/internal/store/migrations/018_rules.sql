@@ -0,0 +1,23 @@
+-- Ingest rules. feed_id or group_id scopes a rule; both NULL makes it global.
+CREATE TABLE IF NOT EXISTS rules (
+	id              INTEGER PRIMARY KEY,
+	name            TEXT NOT NULL,
+	feed_id         INTEGER REFERENCES feeds(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	group_id        INTEGER REFERENCES groups(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	field           TEXT NOT NULL,
+	match_type      TEXT NOT NULL,
+	pattern         TEXT NOT NULL,
+	action          TEXT NOT NULL,
+	target_group_id INTEGER REFERENCES groups(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	enabled         INTEGER NOT NULL DEFAULT 1,
+	hit_count       INTEGER NOT NULL DEFAULT 0,
+	last_hit_at     INTEGER NOT NULL DEFAULT 0,
+	created_at      INTEGER NOT NULL DEFAULT (unixepoch()),
+	updated_at      INTEGER NOT NULL DEFAULT (unixepoch())
+);
+
+CREATE INDEX IF NOT EXISTS idx_rules_feed_id ON rules(feed_id);
+CREATE INDEX IF NOT EXISTS idx_rules_group_id ON rules(group_id);
+
+-- Group an item was moved to by a rule; NULL shows it in its feed's group.
+ALTER TABLE items ADD COLUMN group_id INTEGER REFERENCES groups(id) ON UPDATE CASCADE ON DELETE SET NULL;
@@ -0,0 +1,14 @@
+-- One row per pull attempt; trimmed to the newest entries per feed on insert.
+CREATE TABLE IF NOT EXISTS feed_fetch_log (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	feed_id     INTEGER NOT NULL REFERENCES feeds(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	fetched_at  INTEGER NOT NULL,
+	duration_ms INTEGER NOT NULL DEFAULT 0,
+	http_status INTEGER NOT NULL DEFAULT 0,
+	bytes       INTEGER NOT NULL DEFAULT 0,
+	new_items   INTEGER NOT NULL DEFAULT 0,
+	error_class TEXT NOT NULL DEFAULT '',
+	error       TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_feed_fetch_log_feed_id ON feed_fetch_log(feed_id, id);
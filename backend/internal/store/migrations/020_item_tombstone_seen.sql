@@ -0,0 +1,4 @@
+-- Last time a pull still listed a tombstoned GUID. Tombstones expire a
+-- configured time after the item stopped appearing upstream.
+ALTER TABLE item_tombstones ADD COLUMN seen_at INTEGER NOT NULL DEFAULT 0;
+UPDATE item_tombstones SET seen_at = pruned_at;
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"database/sql"
+)
+
+// PruneFeedItemsParams selects read items to prune from one feed.
+//
+// Before prunes items with pub_date older than this Unix time; 0 disables the age rule.
+// KeepNewest prunes items ranked beyond the newest N by (pub_date, id); 0 disables the count rule.
+// Unread and bookmarked items are never pruned, but still count toward KeepNewest.
+// TombstonesBefore expires the feed's tombstones last recorded or seen upstream before this Unix
+// time; 0 keeps them.
+type PruneFeedItemsParams struct {
+	Before           int64
+	KeepNewest       int64
+	PrunedAt         int64
+	TombstonesBefore int64
+}
+
+// pruneCandidatesQuery selects ids of items eligible for pruning. It is used
+// twice in PruneFeedItems (tombstones, then delete) inside one transaction.
+const pruneCandidatesQuery = `
+	SELECT id FROM (
+		SELECT i.id, i.unread, i.pub_date,
+		       ROW_NUMBER() OVER (ORDER BY i.pub_date DESC, i.id DESC) AS position
+		FROM items i
+		WHERE i.feed_id = :feed_id
+	) ranked
+	WHERE ranked.unread = 0
+	  AND NOT EXISTS (SELECT 1 FROM bookmarks b WHERE b.item_id = ranked.id)
+	  AND (
+		(:before > 0 AND ranked.pub_date < :before)
+		OR (:keep_newest > 0 AND ranked.position > :keep_newest)
+	  )
+`
+
+// PruneFeedItems deletes items selected by params and records their GUIDs as
+// tombstones so later pulls do not re-insert them. Tombstones neither recorded
+// nor seen upstream since params.TombstonesBefore are expired in the same
+// transaction. Returns the
+// number of deleted items.
+func (s *Store) PruneFeedItems(feedID int64, params PruneFeedItemsParams) (int, error) {
+	if params.Before <= 0 && params.KeepNewest <= 0 && params.TombstonesBefore <= 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	args := []any{
+		sql.Named("feed_id", feedID),
+		sql.Named("before", params.Before),
+		sql.Named("keep_newest", params.KeepNewest),
+		sql.Named("pruned_at", params.PrunedAt),
+	}
+
+	if params.TombstonesBefore > 0 {
+		if _, err := tx.Exec(`
+			DELETE FROM item_tombstones
+			WHERE feed_id = :feed_id AND MAX(pruned_at, seen_at) < :tombstones_before
+		`, sql.Named("feed_id", feedID), sql.Named("tombstones_before", params.TombstonesBefore)); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO item_tombstones (feed_id, guid, pruned_at, seen_at)
+		SELECT feed_id, guid, :pruned_at, :pruned_at FROM items
+		WHERE id IN (`+pruneCandidatesQuery+`)
+		ON CONFLICT(feed_id, guid) DO UPDATE SET pruned_at = excluded.pruned_at, seen_at = excluded.seen_at
+	`, args...); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM items WHERE id IN (`+pruneCandidatesQuery+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
+// OptimizeStorage merges the FTS index and reclaims free pages after a large
+// prune. VACUUM needs an exclusive lock and relies on busy_timeout to wait for
+// in-flight writers.
+func (s *Store) OptimizeStorage() error {
+	if _, err := s.db.Exec(`INSERT INTO items_fts(items_fts) VALUES('optimize')`); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`VACUUM`)
+	return err
+}
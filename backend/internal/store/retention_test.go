@@ -0,0 +1,169 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestPruneFeedItems(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Test Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed", "", "")
+	other := mustCreateFeed(t, store, group.ID, "Other", "https://example.com/other", "", "")
+
+	oldRead := mustCreateItem(t, store, feed.ID, "old-read", "Old read", "https://example.com/1", "", 100)
+	oldUnread := mustCreateItem(t, store, feed.ID, "old-unread", "Old unread", "https://example.com/2", "", 110)
+	oldBookmarked := mustCreateItem(t, store, feed.ID, "old-bookmarked", "Old bookmarked", "https://example.com/3", "", 120)
+	midRead := mustCreateItem(t, store, feed.ID, "mid-read", "Mid read", "https://example.com/4", "", 1000)
+	newRead := mustCreateItem(t, store, feed.ID, "new-read", "New read", "https://example.com/5", "", 2000)
+	otherRead := mustCreateItem(t, store, other.ID, "old-read", "Other feed", "https://example.com/6", "", 100)
+
+	if err := store.BatchUpdateItemsUnread([]int64{oldRead.ID, oldBookmarked.ID, midRead.ID, newRead.ID, otherRead.ID}, false); err != nil {
+		t.Fatalf("BatchUpdateItemsUnread() failed: %v", err)
+	}
+	mustCreateBookmark(t, store, &oldBookmarked.ID, &feed.ID, oldBookmarked.Link, oldBookmarked.Title, "", oldBookmarked.PubDate, feed.Name)
+
+	// Age rule: only the old read item qualifies; unread and bookmarked are kept.
+	pruned, err := store.PruneFeedItems(feed.ID, PruneFeedItemsParams{Before: 500, PrunedAt: 3000})
+	if err != nil {
+		t.Fatalf("PruneFeedItems() failed: %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("expected 1 pruned item, got %d", pruned)
+	}
+	if _, err := store.GetItem(oldRead.ID); err == nil {
+		t.Error("expected old read item to be pruned")
+	}
+	for _, id := range []int64{oldUnread.ID, oldBookmarked.ID, otherRead.ID} {
+		if _, err := store.GetItem(id); err != nil {
+			t.Errorf("expected item %d to be kept: %v", id, err)
+		}
+	}
+
+	// Count rule: keep the newest 2 (new-read, mid-read); older ones are
+	// unread or bookmarked and must survive.
+	pruned, err = store.PruneFeedItems(feed.ID, PruneFeedItemsParams{KeepNewest: 2, PrunedAt: 3000})
+	if err != nil {
+		t.Fatalf("PruneFeedItems() failed: %v", err)
+	}
+	if pruned != 0 {
+		t.Fatalf("expected nothing pruned, got %d", pruned)
+	}
+
+	pruned, err = store.PruneFeedItems(feed.ID, PruneFeedItemsParams{KeepNewest: 1, PrunedAt: 3000})
+	if err != nil {
+		t.Fatalf("PruneFeedItems() failed: %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("expected 1 pruned item, got %d", pruned)
+	}
+	if _, err := store.GetItem(midRead.ID); err == nil {
+		t.Error("expected mid read item to be pruned by count rule")
+	}
+
+	// Tombstoned GUIDs must not be re-inserted by the next pull.
+	created, err := store.BatchCreateItemsIgnore(feed.ID, []BatchCreateItemInput{
+		{GUID: "old-read", Title: "Old read", PubDate: 100},
+		{GUID: "mid-read", Title: "Mid read", PubDate: 1000},
+		{GUID: "brand-new", Title: "Brand new", PubDate: 4000},
+	})
+	if err != nil {
+		t.Fatalf("BatchCreateItemsIgnore() failed: %v", err)
+	}
+	if created != 1 {
+		t.Fatalf("expected only the new GUID to be inserted, got %d", created)
+	}
+
+	// Tombstones are per feed.
+	created, err = store.BatchCreateItemsIgnore(other.ID, []BatchCreateItemInput{{GUID: "mid-read", PubDate: 1000}})
+	if err != nil {
+		t.Fatalf("BatchCreateItemsIgnore() failed: %v", err)
+	}
+	if created != 1 {
+		t.Fatalf("expected GUID to be inserted into another feed, got %d", created)
+	}
+
+	if err := store.OptimizeStorage(); err != nil {
+		t.Fatalf("OptimizeStorage() failed: %v", err)
+	}
+}
+
+func TestPruneFeedItemsExpiresTombstones(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Test Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed", "", "")
+
+	oldRead := mustCreateItem(t, store, feed.ID, "old-read", "Old read", "https://example.com/1", "", 100)
+	newRead := mustCreateItem(t, store, feed.ID, "new-read", "New read", "https://example.com/2", "", 2000)
+	if err := store.BatchUpdateItemsUnread([]int64{oldRead.ID, newRead.ID}, false); err != nil {
+		t.Fatalf("BatchUpdateItemsUnread() failed: %v", err)
+	}
+	if _, err := store.PruneFeedItems(feed.ID, PruneFeedItemsParams{Before: 500, PrunedAt: 1000}); err != nil {
+		t.Fatalf("PruneFeedItems() failed: %v", err)
+	}
+	if _, err := store.PruneFeedItems(feed.ID, PruneFeedItemsParams{Before: 5000, PrunedAt: 3000}); err != nil {
+		t.Fatalf("PruneFeedItems() failed: %v", err)
+	}
+
+	// Only the horizon is set: nothing is pruned, the older tombstone expires.
+	pruned, err := store.PruneFeedItems(feed.ID, PruneFeedItemsParams{TombstonesBefore: 2000})
+	if err != nil {
+		t.Fatalf("PruneFeedItems() failed: %v", err)
+	}
+	if pruned != 0 {
+		t.Fatalf("expected nothing pruned, got %d", pruned)
+	}
+
+	var guids []string
+	rows, err := store.db.Query(`SELECT guid FROM item_tombstones WHERE feed_id = :feed_id`, sql.Named("feed_id", feed.ID))
+	if err != nil {
+		t.Fatalf("query tombstones: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var guid string
+		if err := rows.Scan(&guid); err != nil {
+			t.Fatalf("scan tombstone: %v", err)
+		}
+		guids = append(guids, guid)
+	}
+	if len(guids) != 1 || guids[0] != "new-read" {
+		t.Fatalf("expected only the recent tombstone to remain, got %v", guids)
+	}
+}
+
+func TestSeenTombstonesOutliveHorizon(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Test Group")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed", "", "")
+
+	old := mustCreateItem(t, store, feed.ID, "old", "Old", "https://example.com/1", "", 100)
+	mustCreateItem(t, store, feed.ID, "new", "New", "https://example.com/2", "", 200)
+	if err := store.BatchUpdateItemsUnread([]int64{old.ID}, false); err != nil {
+		t.Fatalf("BatchUpdateItemsUnread() failed: %v", err)
+	}
+	// Count-only retention: the old item is pruned while still listed upstream.
+	if pruned, err := store.PruneFeedItems(feed.ID, PruneFeedItemsParams{KeepNewest: 1, PrunedAt: 1000}); err != nil || pruned != 1 {
+		t.Fatalf("PruneFeedItems() = %d, %v; want 1 pruned", pruned, err)
+	}
+
+	// A later pull still returns the GUID: it is skipped and marked as seen.
+	input := BatchCreateItemInput{GUID: "old", Title: "Old", Link: "https://example.com/1", PubDate: 100}
+	if created, err := store.BatchCreateItemsIgnore(feed.ID, []BatchCreateItemInput{input}); err != nil || created != 0 {
+		t.Fatalf("BatchCreateItemsIgnore() = %d, %v; want 0 created", created, err)
+	}
+
+	// A horizon past the prune but before the sighting keeps the tombstone.
+	if _, err := store.PruneFeedItems(feed.ID, PruneFeedItemsParams{TombstonesBefore: 2000}); err != nil {
+		t.Fatalf("PruneFeedItems() failed: %v", err)
+	}
+	if created, err := store.BatchCreateItemsIgnore(feed.ID, []BatchCreateItemInput{input}); err != nil || created != 0 {
+		t.Fatalf("pruned item re-inserted after expiry pass: created=%d err=%v", created, err)
+	}
+}
@@ -0,0 +1,206 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+const ruleColumns = `id, name, COALESCE(feed_id, 0), COALESCE(group_id, 0), field, match_type, pattern, action,
+		COALESCE(target_group_id, 0), enabled, hit_count, last_hit_at, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRule(row rowScanner) (*model.Rule, error) {
+	r := &model.Rule{}
+	var enabled int
+	if err := row.Scan(&r.ID, &r.Name, &r.FeedID, &r.GroupID, &r.Field, &r.MatchType, &r.Pattern, &r.Action,
+		&r.TargetGroupID, &enabled, &r.HitCount, &r.LastHitAt, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		return nil, err
+	}
+	r.Enabled = intToBool(enabled)
+	return r, nil
+}
+
+// nullID maps the zero ID to NULL for optional foreign keys.
+func nullID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func (s *Store) queryRules(query string, args ...any) ([]*model.Rule, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*model.Rule{}
+	for rows.Next() {
+		r, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// ListRules returns all rules in evaluation order.
+func (s *Store) ListRules() ([]*model.Rule, error) {
+	return s.queryRules(`SELECT ` + ruleColumns + ` FROM rules ORDER BY id`)
+}
+
+// ListRulesForFeed returns the enabled rules that apply to a feed in the given
+// group: global rules, then group rules, then feed rules, each by ID.
+func (s *Store) ListRulesForFeed(feedID, groupID int64) ([]*model.Rule, error) {
+	return s.queryRules(`
+		SELECT `+ruleColumns+`
+		FROM rules
+		WHERE enabled = 1
+		  AND ((feed_id IS NULL AND group_id IS NULL) OR group_id = :group_id OR feed_id = :feed_id)
+		ORDER BY CASE WHEN feed_id IS NOT NULL THEN 2 WHEN group_id IS NOT NULL THEN 1 ELSE 0 END, id
+	`, sql.Named("feed_id", feedID), sql.Named("group_id", groupID))
+}
+
+func (s *Store) GetRule(id int64) (*model.Rule, error) {
+	r, err := scanRule(s.db.QueryRow(`SELECT `+ruleColumns+` FROM rules WHERE id = :id`, sql.Named("id", id)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: rule", ErrNotFound)
+		}
+		return nil, fmt.Errorf("get rule: %w", err)
+	}
+	return r, nil
+}
+
+// CreateRule stores a validated rule; counters start at zero.
+func (s *Store) CreateRule(rule *model.Rule) (*model.Rule, error) {
+	result, err := s.db.Exec(`
+		INSERT INTO rules (name, feed_id, group_id, field, match_type, pattern, action, target_group_id, enabled)
+		VALUES (:name, :feed_id, :group_id, :field, :match_type, :pattern, :action, :target_group_id, :enabled)
+	`, ruleArgs(rule)...)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRule(id)
+}
+
+// UpdateRule replaces the definition of a rule, keeping its counters.
+func (s *Store) UpdateRule(id int64, rule *model.Rule) error {
+	args := append(ruleArgs(rule), sql.Named("id", id))
+	result, err := s.db.Exec(`
+		UPDATE rules
+		SET name = :name, feed_id = :feed_id, group_id = :group_id, field = :field, match_type = :match_type,
+		    pattern = :pattern, action = :action, target_group_id = :target_group_id, enabled = :enabled,
+		    updated_at = unixepoch()
+		WHERE id = :id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: rule", ErrNotFound)
+	}
+	return nil
+}
+
+func (s *Store) DeleteRule(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM rules WHERE id = :id`, sql.Named("id", id))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: rule", ErrNotFound)
+	}
+	return nil
+}
+
+func ruleArgs(rule *model.Rule) []any {
+	return []any{
+		sql.Named("name", rule.Name),
+		sql.Named("feed_id", nullID(rule.FeedID)),
+		sql.Named("group_id", nullID(rule.GroupID)),
+		sql.Named("field", rule.Field),
+		sql.Named("match_type", rule.MatchType),
+		sql.Named("pattern", rule.Pattern),
+		sql.Named("action", rule.Action),
+		sql.Named("target_group_id", nullID(rule.TargetGroupID)),
+		sql.Named("enabled", boolToInt(rule.Enabled)),
+	}
+}
+
+// recordRuleHits bumps the hit counters of ruleIDs inside tx.
+func recordRuleHits(tx *sql.Tx, ruleIDs []int64) error {
+	for _, id := range ruleIDs {
+		if _, err := tx.Exec(`
+			UPDATE rules SET hit_count = hit_count + 1, last_hit_at = unixepoch() WHERE id = :id
+		`, sql.Named("id", id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropNewItem tombstones a GUID dropped by a rule so later pulls skip it.
+// Known items are left alone. Reports whether the item was new.
+func dropNewItem(tx *sql.Tx, feedID int64, input BatchCreateItemInput) (bool, error) {
+	res, err := tx.Exec(`
+		INSERT INTO item_tombstones (feed_id, guid, seen_at)
+		SELECT :feed_id, :guid, unixepoch()
+		WHERE NOT EXISTS (SELECT 1 FROM items WHERE feed_id = :feed_id AND guid = :guid)
+		ON CONFLICT(feed_id, guid) DO NOTHING
+	`, sql.Named("feed_id", feedID), sql.Named("guid", input.GUID))
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	return true, recordRuleHits(tx, input.RuleIDs)
+}
+
+// applyNewItemRules bookmarks a just-inserted item when requested and credits
+// the matching rules. Read and GroupID are part of the insert itself.
+func applyNewItemRules(tx *sql.Tx, feedID int64, inserted sql.Result, input BatchCreateItemInput) error {
+	if input.Bookmark && input.Link != "" {
+		itemID, err := inserted.LastInsertId()
+		if err != nil {
+			return err
+		}
+		// Links already bookmarked keep their existing snapshot.
+		if _, err := tx.Exec(`
+			INSERT INTO bookmarks (item_id, feed_id, link, title, content, pub_date, feed_name, sanitize_version)
+			SELECT :item_id, f.id, :link, :title, :content, :pub_date, f.name, :sanitize_version
+			FROM feeds f
+			WHERE f.id = :feed_id
+			ON CONFLICT(link) DO NOTHING
+		`, sql.Named("item_id", itemID), sql.Named("feed_id", feedID), sql.Named("link", input.Link),
+			sql.Named("title", input.Title), sql.Named("content", input.Content), sql.Named("pub_date", input.PubDate),
+			sql.Named("sanitize_version", input.SanitizeVersion)); err != nil {
+			return fmt.Errorf("bookmark item: %w", err)
+		}
+	}
+	return recordRuleHits(tx, input.RuleIDs)
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+func mustCreateRule(t *testing.T, store *Store, rule *model.Rule) *model.Rule {
+	t.Helper()
+
+	created, err := store.CreateRule(rule)
+	if err != nil {
+		t.Fatalf("CreateRule() failed: %v", err)
+	}
+
+	return created
+}
+
+func TestRuleCRUDAndScope(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "News")
+	feed := mustCreateFeed(t, store, group.ID, "Feed", "https://example.com/feed", "", "")
+	other := mustCreateFeed(t, store, 1, "Other", "https://example.com/other", "", "")
+
+	feedRule := mustCreateRule(t, store, &model.Rule{Name: "feed", FeedID: feed.ID, Field: model.RuleFieldTitle, MatchType: model.RuleMatchContains, Pattern: "a", Action: model.RuleActionRead, Enabled: true})
+	groupRule := mustCreateRule(t, store, &model.Rule{Name: "group", GroupID: group.ID, Field: model.RuleFieldTitle, MatchType: model.RuleMatchContains, Pattern: "b", Action: model.RuleActionRead, Enabled: true})
+	globalRule := mustCreateRule(t, store, &model.Rule{Name: "global", Field: model.RuleFieldAny, MatchType: model.RuleMatchRegex, Pattern: "c", Action: model.RuleActionMove, TargetGroupID: group.ID, Enabled: true})
+	mustCreateRule(t, store, &model.Rule{Name: "disabled", Field: model.RuleFieldTitle, MatchType: model.RuleMatchContains, Pattern: "d", Action: model.RuleActionDrop})
+
+	if globalRule.TargetGroupID != group.ID || globalRule.FeedID != 0 || !globalRule.Enabled {
+		t.Fatalf("unexpected created rule: %+v", globalRule)
+	}
+
+	applicable, err := store.ListRulesForFeed(feed.ID, group.ID)
+	if err != nil {
+		t.Fatalf("ListRulesForFeed() failed: %v", err)
+	}
+	if len(applicable) != 3 || applicable[0].ID != globalRule.ID || applicable[1].ID != groupRule.ID || applicable[2].ID != feedRule.ID {
+		t.Fatalf("expected global, group, feed rules in order, got %+v", applicable)
+	}
+
+	applicable, err = store.ListRulesForFeed(other.ID, 1)
+	if err != nil {
+		t.Fatalf("ListRulesForFeed() failed: %v", err)
+	}
+	if len(applicable) != 1 || applicable[0].ID != globalRule.ID {
+		t.Fatalf("expected only the global rule, got %+v", applicable)
+	}
+
+	feedRule.Pattern = "z"
+	feedRule.Enabled = false
+	if err := store.UpdateRule(feedRule.ID, feedRule); err != nil {
+		t.Fatalf("UpdateRule() failed: %v", err)
+	}
+	got, err := store.GetRule(feedRule.ID)
+	if err != nil || got.Pattern != "z" || got.Enabled {
+		t.Fatalf("rule not updated: %+v, %v", got, err)
+	}
+
+	// Deleting a feed removes its rules.
+	if err := store.DeleteFeed(feed.ID); err != nil {
+		t.Fatalf("DeleteFeed() failed: %v", err)
+	}
+	if _, err := store.GetRule(feedRule.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected feed rule to be deleted, got %v", err)
+	}
+
+	if err := store.DeleteRule(groupRule.ID); err != nil {
+		t.Fatalf("DeleteRule() failed: %v", err)
+	}
+	if err := store.DeleteRule(groupRule.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestBatchUpsertItemsAppliesRuleOutcome(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer closeStore(t, store)
+
+	group := mustCreateGroup(t, store, "Elsewhere")
+	feed := mustCreateFeed(t, store, 1, "Feed", "https://example.com/feed", "", "")
+	rule := mustCreateRule(t, store, &model.Rule{Name: "r", Field: model.RuleFieldTitle, MatchType: model.RuleMatchContains, Pattern: "x", Action: model.RuleActionRead, Enabled: true})
+
+	inputs := []BatchCreateItemInput{
+		{GUID: "drop", Title: "Drop", Link: "https://example.com/drop", Drop: true, RuleIDs: []int64{rule.ID}},
+		{GUID: "read", Title: "Read", Link: "https://example.com/read", Read: true, Bookmark: true, GroupID: group.ID, RuleIDs: []int64{rule.ID}},
+		{GUID: "plain", Title: "Plain", Link: "https://example.com/plain"},
+	}
+	result, err := store.BatchUpsertItems(feed.ID, model.ItemUpdateIgnore, inputs)
+	if err != nil {
+		t.Fatalf("BatchUpsertItems() failed: %v", err)
+	}
+	if result.Created != 2 || result.Dropped != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// A second pull of the same items neither re-inserts nor re-counts.
+	result, err = store.BatchUpsertItems(feed.ID, model.ItemUpdateIgnore, inputs)
+	if err != nil {
+		t.Fatalf("BatchUpsertItems() failed: %v", err)
+	}
+	if result.Created != 0 || result.Dropped != 0 {
+		t.Fatalf("unexpected second result: %+v", result)
+	}
+
+	if exists, err := store.ItemExists(feed.ID, "drop"); err != nil || exists {
+		t.Fatalf("dropped item stored: %v, %v", exists, err)
+	}
+
+	moved, err := store.ListItems(ListItemsParams{GroupID: &group.ID})
+	if err != nil {
+		t.Fatalf("ListItems() failed: %v", err)
+	}
+	if len(moved) != 1 || moved[0].GUID != "read" || moved[0].Unread || moved[0].GroupID != group.ID {
+		t.Fatalf("expected read item in target group, got %+v", moved)
+	}
+	defaultGroup := int64(1)
+	remaining, err := store.ListItems(ListItemsParams{GroupID: &defaultGroup})
+	if err != nil {
+		t.Fatalf("ListItems() failed: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].GUID != "plain" {
+		t.Fatalf("expected only the plain item in the feed's group, got %+v", remaining)
+	}
+
+	bookmarks, err := store.ListBookmarks(ListBookmarksParams{FeedID: &feed.ID})
+	if err != nil {
+		t.Fatalf("ListBookmarks() failed: %v", err)
+	}
+	if len(bookmarks) != 1 || bookmarks[0].Link != "https://example.com/read" || bookmarks[0].FeedName != "Feed" {
+		t.Fatalf("expected bookmark for read item, got %+v", bookmarks)
+	}
+
+	got, err := store.GetRule(rule.ID)
+	if err != nil {
+		t.Fatalf("GetRule() failed: %v", err)
+	}
+	if got.HitCount != 2 || got.LastHitAt == 0 {
+		t.Fatalf("expected 2 hits, got %+v", got)
+	}
+}
@@ -11,11 +11,13 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/0x2E/fusion/internal/pkg/secretbox"
 	"modernc.org/sqlite"
 )
 
 type Store struct {
-	db *sql.DB
+	db      *sql.DB
+	secrets *secretbox.Box // nil until SetSecretBox; needed for feed credentials
 }
 
 var sqliteHookOnce sync.Once
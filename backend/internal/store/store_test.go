@@ -64,7 +64,7 @@ func mustCreateItem(t *testing.T, store *Store, feedID int64, guid, title, link,
 func mustCreateBookmark(t *testing.T, store *Store, itemID *int64, feedID *int64, link, title, content string, pubDate int64, feedName string) *model.Bookmark {
 	t.Helper()
 
-	bookmark, err := store.CreateBookmark(itemID, feedID, link, title, content, pubDate, feedName)
+	bookmark, err := store.CreateBookmark(itemID, feedID, link, title, content, pubDate, feedName, 0)
 	if err != nil {
 		t.Fatalf("CreateBookmark() failed: %v", err)
 	}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/0x2E/fusion/internal/model"
+)
+
+const webSubColumns = `feed_id, hub_url, topic_url, secret, state, lease_seconds, lease_expires_at, last_error, created_at, updated_at`
+
+func (s *Store) GetWebSubSubscription(feedID int64) (*model.WebSubSubscription, error) {
+	sub := &model.WebSubSubscription{}
+	err := s.db.QueryRow(`
+		SELECT `+webSubColumns+`
+		FROM websub_subscriptions
+		WHERE feed_id = :feed_id
+	`, sql.Named("feed_id", feedID)).Scan(
+		&sub.FeedID, &sub.HubURL, &sub.TopicURL, &sub.Secret, &sub.State,
+		&sub.LeaseSeconds, &sub.LeaseExpiresAt, &sub.LastError, &sub.CreatedAt, &sub.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: websub subscription", ErrNotFound)
+		}
+		return nil, fmt.Errorf("get websub subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// ListWebSubSubscriptionsDue returns active subscriptions whose lease ends
+// within renewWindow seconds of now, or within half of the granted lease when
+// that is shorter.
+func (s *Store) ListWebSubSubscriptionsDue(now, renewWindow int64) ([]*model.WebSubSubscription, error) {
+	rows, err := s.db.Query(`
+		SELECT `+webSubColumns+`
+		FROM websub_subscriptions
+		WHERE state = 'active'
+		  AND lease_expires_at - :now < MIN(:renew_window, lease_seconds / 2)
+		ORDER BY lease_expires_at
+	`, sql.Named("now", now), sql.Named("renew_window", renewWindow))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []*model.WebSubSubscription{}
+	for rows.Next() {
+		sub := &model.WebSubSubscription{}
+		if err := rows.Scan(
+			&sub.FeedID, &sub.HubURL, &sub.TopicURL, &sub.Secret, &sub.State,
+			&sub.LeaseSeconds, &sub.LeaseExpiresAt, &sub.LastError, &sub.CreatedAt, &sub.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+type UpsertWebSubSubscriptionParams struct {
+	HubURL   string
+	TopicURL string
+	Secret   string
+	State    string
+}
+
+// UpsertWebSubSubscription records a subscription request. The lease is kept
+// so that a renewal does not interrupt an active subscription.
+func (s *Store) UpsertWebSubSubscription(feedID int64, params UpsertWebSubSubscriptionParams) error {
+	_, err := s.db.Exec(`
+		INSERT INTO websub_subscriptions (feed_id, hub_url, topic_url, secret, state, updated_at)
+		VALUES (:feed_id, :hub_url, :topic_url, :secret, :state, unixepoch())
+		ON CONFLICT(feed_id) DO UPDATE SET
+			hub_url = excluded.hub_url,
+			topic_url = excluded.topic_url,
+			secret = excluded.secret,
+			state = excluded.state,
+			last_error = '',
+			updated_at = excluded.updated_at
+	`, sql.Named("feed_id", feedID), sql.Named("hub_url", params.HubURL), sql.Named("topic_url", params.TopicURL),
+		sql.Named("secret", params.Secret), sql.Named("state", params.State))
+	return err
+}
+
+// ActivateWebSubSubscription marks a subscription verified by the hub.
+func (s *Store) ActivateWebSubSubscription(feedID, leaseSeconds, leaseExpiresAt int64) error {
+	return s.updateWebSubSubscription(feedID, `
+		UPDATE websub_subscriptions
+		SET state = 'active', lease_seconds = :lease_seconds, lease_expires_at = :lease_expires_at,
+		    last_error = '', updated_at = unixepoch()
+		WHERE feed_id = :feed_id
+	`, sql.Named("feed_id", feedID), sql.Named("lease_seconds", leaseSeconds), sql.Named("lease_expires_at", leaseExpiresAt))
+}
+
+// FailWebSubSubscription marks a subscription as failed so the feed falls back
+// to regular polling.
+func (s *Store) FailWebSubSubscription(feedID int64, reason string) error {
+	return s.updateWebSubSubscription(feedID, `
+		UPDATE websub_subscriptions
+		SET state = 'failed', lease_expires_at = 0, last_error = :last_error, updated_at = unixepoch()
+		WHERE feed_id = :feed_id
+	`, sql.Named("feed_id", feedID), sql.Named("last_error", reason))
+}
+
+func (s *Store) updateWebSubSubscription(feedID int64, query string, args ...any) error {
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: websub subscription", ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+// Package websub implements the subscriber side of WebSub (PubSubHubbub):
+// subscription requests, intent verification, content signature checks and
+// lease renewal. Pushed content is ingested by the puller.
+package websub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/pkg/httpc"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+const (
+	// requestedLeaseSeconds is sent as hub.lease_seconds; hubs may grant less.
+	requestedLeaseSeconds = 7 * 24 * 3600
+	// renewWindow renews active leases this many seconds before expiry, or at
+	// half of the granted lease when that is shorter.
+	renewWindow = 24 * 3600
+	// pendingTimeout re-sends a request the hub never verified.
+	pendingTimeout = 3600
+	// retryDelay throttles new attempts after a failed or denied subscription.
+	retryDelay = 24 * 3600
+
+	renewCheckInterval = 10 * time.Minute
+)
+
+var (
+	ErrUnknownSubscription = errors.New("unknown websub subscription")
+	ErrInvalidSignature    = errors.New("invalid websub signature")
+)
+
+type Subscriber struct {
+	store   *store.Store
+	config  *config.Config
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func New(st *store.Store, cfg *config.Config) *Subscriber {
+	return &Subscriber{
+		store:   st,
+		config:  cfg,
+		logger:  slog.Default(),
+		timeout: time.Duration(cfg.PullTimeout) * time.Second,
+	}
+}
+
+// CallbackURL is the public endpoint hubs use for verification and delivery.
+func (s *Subscriber) CallbackURL(feedID int64) string {
+	return s.config.PublicURL + "/api/websub/callback/" + strconv.FormatInt(feedID, 10)
+}
+
+// Start renews expiring leases periodically. Blocks until context is cancelled.
+func (s *Subscriber) Start(ctx context.Context) error {
+	s.logger.Info("websub service started", "callback_base", s.config.PublicURL)
+
+	ticker := time.NewTicker(renewCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("websub service stopping")
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := s.RenewDue(ctx, time.Now().Unix()); err != nil {
+				s.logger.Error("websub renewal failed", "error", err)
+			}
+		}
+	}
+}
+
+// RenewDue re-subscribes active subscriptions whose lease is about to expire.
+// Returns the number of renewal requests accepted by hubs.
+func (s *Subscriber) RenewDue(ctx context.Context, now int64) (int, error) {
+	subs, err := s.store.ListWebSubSubscriptionsDue(now, renewWindow)
+	if err != nil {
+		return 0, err
+	}
+
+	renewed := 0
+	for _, sub := range subs {
+		if err := ctx.Err(); err != nil {
+			return renewed, err
+		}
+		if err := s.subscribe(ctx, sub.FeedID, sub.HubURL, sub.TopicURL, sub.Secret, model.WebSubStateActive); err != nil {
+			s.logger.Warn("failed to renew websub lease", "feed_id", sub.FeedID, "hub", sub.HubURL, "error", err)
+			continue
+		}
+		renewed++
+	}
+
+	return renewed, nil
+}
+
+// Ensure subscribes the feed to hubURL unless an equivalent subscription is
+// active, still awaiting verification, or failed recently.
+func (s *Subscriber) Ensure(ctx context.Context, feedID int64, hubURL, topicURL string, now int64) error {
+	sub, err := s.store.GetWebSubSubscription(feedID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	secret := ""
+	state := model.WebSubStatePending
+	if sub != nil && sub.HubURL == hubURL && sub.TopicURL == topicURL {
+		switch sub.State {
+		case model.WebSubStateActive:
+			if sub.LeaseExpiresAt-now >= min(renewWindow, sub.LeaseSeconds/2) {
+				return nil
+			}
+			// Keep the secret and the active state while the renewal is verified.
+			secret = sub.Secret
+			state = model.WebSubStateActive
+		case model.WebSubStatePending:
+			if now-sub.UpdatedAt < pendingTimeout {
+				return nil
+			}
+		case model.WebSubStateFailed:
+			if now-sub.UpdatedAt < retryDelay {
+				return nil
+			}
+		}
+	}
+
+	if secret == "" {
+		secret, err = newSecret()
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.subscribe(ctx, feedID, hubURL, topicURL, secret, state)
+}
+
+// subscribe records the request before contacting the hub, because hubs may
+// verify intent before answering.
+func (s *Subscriber) subscribe(ctx context.Context, feedID int64, hubURL, topicURL, secret, state string) error {
+	if err := s.store.UpsertWebSubSubscription(feedID, store.UpsertWebSubSubscriptionParams{
+		HubURL:   hubURL,
+		TopicURL: topicURL,
+		Secret:   secret,
+		State:    state,
+	}); err != nil {
+		return err
+	}
+
+	if err := s.requestSubscription(ctx, feedID, hubURL, topicURL, secret); err != nil {
+		if failErr := s.store.FailWebSubSubscription(feedID, err.Error()); failErr != nil {
+			s.logger.Error("failed to record websub failure", "feed_id", feedID, "error", failErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *Subscriber) requestSubscription(ctx context.Context, feedID int64, hubURL, topicURL, secret string) error {
+	if err := httpc.ValidateRequestURL(ctx, hubURL, s.config.AllowPrivateFeeds); err != nil {
+		return fmt.Errorf("validate hub url: %w", err)
+	}
+
+	client, err := httpc.NewClient(s.timeout, "", s.config.AllowPrivateFeeds)
+	if err != nil {
+		return fmt.Errorf("create client: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("hub.mode", "subscribe")
+	form.Set("hub.topic", topicURL)
+	form.Set("hub.callback", s.CallbackURL(feedID))
+	form.Set("hub.secret", secret)
+	form.Set("hub.lease_seconds", strconv.Itoa(requestedLeaseSeconds))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hubURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	httpc.SetDefaultHeaders(req)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request hub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("hub responded HTTP %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// VerifyIntent answers a hub verification request. For "subscribe" it returns
+// the challenge to echo and activates the subscription; for "denied" it marks
+// the subscription failed. Unsubscribe intents are never confirmed because
+// subscriptions are only left to expire.
+func (s *Subscriber) VerifyIntent(feedID int64, mode, topicURL, challenge string, leaseSeconds int64, reason string, now int64) (string, error) {
+	sub, err := s.store.GetWebSubSubscription(feedID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return "", ErrUnknownSubscription
+		}
+		return "", err
+	}
+	if sub.TopicURL != topicURL {
+		return "", ErrUnknownSubscription
+	}
+
+	switch mode {
+	case "subscribe":
+		if sub.State == model.WebSubStateFailed {
+			return "", ErrUnknownSubscription
+		}
+		if challenge == "" {
+			return "", fmt.Errorf("missing hub.challenge")
+		}
+		if leaseSeconds <= 0 {
+			leaseSeconds = requestedLeaseSeconds
+		}
+		if err := s.store.ActivateWebSubSubscription(feedID, leaseSeconds, now+leaseSeconds); err != nil {
+			return "", err
+		}
+		s.logger.Info("websub subscription verified", "feed_id", feedID, "hub", sub.HubURL, "lease_seconds", leaseSeconds)
+		return challenge, nil
+	case "denied":
+		if reason == "" {
+			reason = "subscription denied by hub"
+		}
+		if err := s.store.FailWebSubSubscription(feedID, reason); err != nil {
+			return "", err
+		}
+		s.logger.Warn("websub subscription denied", "feed_id", feedID, "hub", sub.HubURL, "reason", reason)
+		return "", nil
+	default:
+		return "", ErrUnknownSubscription
+	}
+}
+
+// VerifySignature checks the X-Hub-Signature header ("method=hexdigest") of a
+// content distribution request against the subscription secret.
+func (s *Subscriber) VerifySignature(feedID int64, body []byte, signature string) error {
+	sub, err := s.store.GetWebSubSubscription(feedID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrUnknownSubscription
+		}
+		return err
+	}
+
+	method, digest, ok := strings.Cut(strings.TrimSpace(signature), "=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	newHash := signatureHash(strings.ToLower(method))
+	if newHash == nil {
+		return ErrInvalidSignature
+	}
+	want, err := hex.DecodeString(digest)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(newHash, []byte(sub.Secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), want) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func signatureHash(method string) func() hash.Hash {
+	switch method {
+	case "sha1":
+		return sha1.New
+	case "sha256":
+		return sha256.New
+	case "sha384":
+		return sha512.New384
+	case "sha512":
+		return sha512.New
+	default:
+		return nil
+	}
+}
+
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate websub secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,239 @@
+package websub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0x2E/fusion/internal/config"
+	"github.com/0x2E/fusion/internal/model"
+	"github.com/0x2E/fusion/internal/store"
+)
+
+// hubStub is a minimal WebSub hub. It records subscription requests and, like
+// most hubs, verifies intent before answering by calling back the subscriber.
+type hubStub struct {
+	t        *testing.T
+	sub      *Subscriber
+	status   int
+	lease    int64
+	mu       sync.Mutex
+	requests []url.Values
+}
+
+func (h *hubStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.t.Errorf("parse hub request: %v", err)
+	}
+	h.mu.Lock()
+	h.requests = append(h.requests, r.PostForm)
+	h.mu.Unlock()
+
+	if h.status != 0 {
+		w.WriteHeader(h.status)
+		return
+	}
+
+	callback, err := url.Parse(r.PostForm.Get("hub.callback"))
+	if err != nil {
+		h.t.Errorf("parse callback: %v", err)
+	}
+	feedID, err := strconv.ParseInt(path.Base(callback.Path), 10, 64)
+	if err != nil {
+		h.t.Errorf("parse callback feed id: %v", err)
+	}
+	challenge, err := h.sub.VerifyIntent(feedID, "subscribe", r.PostForm.Get("hub.topic"), "challenge-123", h.lease, "", time.Now().Unix())
+	if err != nil || challenge != "challenge-123" {
+		h.t.Errorf("VerifyIntent() = %q, %v", challenge, err)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *hubStub) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.requests)
+}
+
+func setup(t *testing.T) (*Subscriber, *store.Store, *model.Feed) {
+	t.Helper()
+
+	st, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	feed, err := st.CreateFeed(1, "Feed", "https://example.com/feed.xml", "", "")
+	if err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	sub := New(st, &config.Config{
+		PullTimeout:       5,
+		AllowPrivateFeeds: true,
+		PublicURL:         "https://reader.example.com",
+	})
+	return sub, st, feed
+}
+
+func TestEnsureSubscribesAndVerifiesIntent(t *testing.T) {
+	sub, st, feed := setup(t)
+	hub := &hubStub{t: t, sub: sub, lease: 3600}
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	now := time.Now().Unix()
+	if err := sub.Ensure(context.Background(), feed.ID, server.URL, "https://example.com/self.xml", now); err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+
+	if hub.count() != 1 {
+		t.Fatalf("expected 1 hub request, got %d", hub.count())
+	}
+	form := hub.requests[0]
+	if form.Get("hub.mode") != "subscribe" || form.Get("hub.topic") != "https://example.com/self.xml" {
+		t.Errorf("unexpected hub request: %v", form)
+	}
+	if want := sub.CallbackURL(feed.ID); form.Get("hub.callback") != want {
+		t.Errorf("callback = %q, want %q", form.Get("hub.callback"), want)
+	}
+	if form.Get("hub.secret") == "" {
+		t.Error("expected hub.secret to be sent")
+	}
+
+	got, err := st.GetWebSubSubscription(feed.ID)
+	if err != nil {
+		t.Fatalf("GetWebSubSubscription() failed: %v", err)
+	}
+	if got.State != model.WebSubStateActive || got.LeaseSeconds != 3600 {
+		t.Fatalf("unexpected subscription: state=%s lease=%d", got.State, got.LeaseSeconds)
+	}
+	updatedFeed, err := st.GetFeed(feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed() failed: %v", err)
+	}
+	if !updatedFeed.WebSubActive {
+		t.Error("expected feed to report an active websub subscription")
+	}
+
+	// An active subscription with a fresh lease is not requested again.
+	if err := sub.Ensure(context.Background(), feed.ID, server.URL, "https://example.com/self.xml", now); err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	if hub.count() != 1 {
+		t.Fatalf("expected no new hub request, got %d", hub.count())
+	}
+
+	// Past half of the lease the subscription is renewed with the same secret.
+	renewed, err := sub.RenewDue(context.Background(), now+2000)
+	if err != nil {
+		t.Fatalf("RenewDue() failed: %v", err)
+	}
+	if renewed != 1 || hub.count() != 2 {
+		t.Fatalf("expected one renewal, got renewed=%d requests=%d", renewed, hub.count())
+	}
+	if hub.requests[1].Get("hub.secret") != form.Get("hub.secret") {
+		t.Error("expected renewal to keep the secret")
+	}
+}
+
+func TestEnsureRecordsHubFailure(t *testing.T) {
+	sub, st, feed := setup(t)
+	hub := &hubStub{t: t, sub: sub, status: http.StatusInternalServerError}
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	now := time.Now().Unix()
+	if err := sub.Ensure(context.Background(), feed.ID, server.URL, feed.Link, now); err == nil {
+		t.Fatal("expected Ensure() to fail")
+	}
+
+	got, err := st.GetWebSubSubscription(feed.ID)
+	if err != nil {
+		t.Fatalf("GetWebSubSubscription() failed: %v", err)
+	}
+	if got.State != model.WebSubStateFailed || got.LastError == "" {
+		t.Fatalf("unexpected subscription: state=%s last_error=%q", got.State, got.LastError)
+	}
+
+	// Failed subscriptions are retried only after retryDelay.
+	if err := sub.Ensure(context.Background(), feed.ID, server.URL, feed.Link, now); err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+	if hub.count() != 1 {
+		t.Fatalf("expected no retry before retryDelay, got %d requests", hub.count())
+	}
+}
+
+func TestVerifyIntentRejectsUnknownTopicAndHandlesDenial(t *testing.T) {
+	sub, st, feed := setup(t)
+	if err := st.UpsertWebSubSubscription(feed.ID, store.UpsertWebSubSubscriptionParams{
+		HubURL:   "https://hub.example.com",
+		TopicURL: feed.Link,
+		Secret:   "s3cret",
+		State:    model.WebSubStatePending,
+	}); err != nil {
+		t.Fatalf("UpsertWebSubSubscription() failed: %v", err)
+	}
+
+	now := time.Now().Unix()
+	if _, err := sub.VerifyIntent(feed.ID, "subscribe", "https://other.example.com/feed", "c", 60, "", now); !errors.Is(err, ErrUnknownSubscription) {
+		t.Fatalf("expected ErrUnknownSubscription for topic mismatch, got %v", err)
+	}
+	if _, err := sub.VerifyIntent(feed.ID, "unsubscribe", feed.Link, "c", 0, "", now); !errors.Is(err, ErrUnknownSubscription) {
+		t.Fatalf("expected unsubscribe intent to be rejected, got %v", err)
+	}
+
+	if _, err := sub.VerifyIntent(feed.ID, "denied", feed.Link, "", 0, "blocked", now); err != nil {
+		t.Fatalf("VerifyIntent(denied) failed: %v", err)
+	}
+	got, err := st.GetWebSubSubscription(feed.ID)
+	if err != nil {
+		t.Fatalf("GetWebSubSubscription() failed: %v", err)
+	}
+	if got.State != model.WebSubStateFailed || got.LastError != "blocked" {
+		t.Fatalf("unexpected subscription: state=%s last_error=%q", got.State, got.LastError)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	sub, st, feed := setup(t)
+	if err := st.UpsertWebSubSubscription(feed.ID, store.UpsertWebSubSubscriptionParams{
+		HubURL:   "https://hub.example.com",
+		TopicURL: feed.Link,
+		Secret:   "s3cret",
+		State:    model.WebSubStateActive,
+	}); err != nil {
+		t.Fatalf("UpsertWebSubSubscription() failed: %v", err)
+	}
+
+	body := []byte("<feed/>")
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if err := sub.VerifySignature(feed.ID, body, valid); err != nil {
+		t.Fatalf("VerifySignature() failed: %v", err)
+	}
+	for _, signature := range []string{"", "sha256=00", "md5=" + valid[7:], valid + "ff"} {
+		if err := sub.VerifySignature(feed.ID, body, signature); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifySignature(%q) = %v, want ErrInvalidSignature", signature, err)
+		}
+	}
+	if err := sub.VerifySignature(feed.ID+1, body, valid); !errors.Is(err, ErrUnknownSubscription) {
+		t.Errorf("expected ErrUnknownSubscription for unknown feed, got %v", err)
+	}
+}
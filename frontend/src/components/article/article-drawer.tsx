@@ -27,7 +27,7 @@ import { useArticleNavigation } from "@/hooks/use-keyboard";
 import { useI18n } from "@/lib/i18n";
 import { cn, formatDate } from "@/lib/utils";
 import { processArticleContent } from "@/lib/content";
-import { getFaviconUrl } from "@/lib/api/favicon";
+import { getFeedIconUrl } from "@/lib/api/favicon";
 import { FeedFavicon } from "@/components/feed/feed-favicon";
 import { toSafeExternalUrl } from "@/lib/safe-url";
 
@@ -237,7 +237,7 @@ export function ArticleDrawer() {
                       >
                         {feed && (
                           <FeedFavicon
-                            src={getFaviconUrl(feed.link, feed.site_url)}
+                            src={getFeedIconUrl(feed)}
                             className="h-3.5 w-3.5 rounded-sm"
                           />
                         )}
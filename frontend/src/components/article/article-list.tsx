@@ -14,7 +14,7 @@ import { useMarkItemsRead, useMarkItemsUnread } from "@/queries/items";
 import { useFeedLookup } from "@/queries/feeds";
 import { useGroups } from "@/queries/groups";
 import { useCreateBookmark, useDeleteBookmark } from "@/queries/bookmarks";
-import { getFaviconUrl } from "@/lib/api/favicon";
+import { getFeedIconUrl } from "@/lib/api/favicon";
 import { useI18n } from "@/lib/i18n";
 import type { Item } from "@/lib/api";
 
@@ -206,7 +206,7 @@ export function ArticleList() {
                       isStarred={isItemStarred(article.id)}
                       feedName={feed?.name ?? bookmark?.feed_name ?? t("common.unknown")}
                       feedFaviconUrl={
-                        feed ? getFaviconUrl(feed.link, feed.site_url) : null
+                        feed ? getFeedIconUrl(feed) : null
                       }
                     />
                   );
@@ -7,7 +7,7 @@ import {
   TooltipContent,
   TooltipTrigger,
 } from "@/components/ui/tooltip";
-import { getFaviconUrl } from "@/lib/api/favicon";
+import { getFeedIconUrl } from "@/lib/api/favicon";
 import { getFeedErrorPreview } from "@/lib/feed-error";
 import type { TranslationKey } from "@/lib/i18n";
 import { cn, formatDate } from "@/lib/utils";
@@ -194,7 +194,7 @@ export function FeedGroupCard({
             >
               <div className="flex min-w-0 flex-1 items-center gap-2.5">
                 <FeedFavicon
-                  src={getFaviconUrl(feed.link, feed.site_url)}
+                  src={getFeedIconUrl(feed)}
                   className="h-5 w-5"
                 />
                 <div className="min-w-0">
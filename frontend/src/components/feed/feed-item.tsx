@@ -1,7 +1,7 @@
 import { cn } from "@/lib/utils";
 import { useUrlState } from "@/hooks/use-url-state";
 import { useUIStore } from "@/store";
-import { getFaviconUrl } from "@/lib/api/favicon";
+import { getFeedIconUrl } from "@/lib/api/favicon";
 import type { Feed } from "@/lib/api";
 import { FeedFavicon } from "@/components/feed/feed-favicon";
 import { Settings } from "lucide-react";
@@ -18,7 +18,7 @@ export function FeedItem({ feed }: FeedItemProps) {
   const { setEditFeedOpen } = useUIStore();
 
   const isSelected = selectedFeedId === feed.id;
-  const faviconUrl = getFaviconUrl(feed.link, feed.site_url);
+  const faviconUrl = getFeedIconUrl(feed);
 
   const handleSettingsClick = () => {
     setEditFeedOpen(true, feed);
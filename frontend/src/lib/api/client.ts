@@ -1,4 +1,4 @@
-const API_BASE = import.meta.env.VITE_API_BASE_URL || "/api";
+export const API_BASE = import.meta.env.VITE_API_BASE_URL || "/api";
 
 export class APIError extends Error {
   status: number;
@@ -82,6 +82,13 @@ async function patch<T>(endpoint: string, data?: unknown): Promise<T> {
   });
 }
 
+async function put<T>(endpoint: string, data?: unknown): Promise<T> {
+  return request<T>(endpoint, {
+    method: "PUT",
+    body: data ? JSON.stringify(data) : undefined,
+  });
+}
+
 async function del<T>(endpoint: string): Promise<T> {
   return request<T>(endpoint, { method: "DELETE" });
 }
@@ -90,5 +97,6 @@ export const api = {
   get,
   post,
   patch,
+  put,
   delete: del,
 };
@@ -1,3 +1,6 @@
+import { API_BASE } from "./client";
+import type { Feed } from "./types";
+
 // RSSHub path prefix to actual domain mapping
 const rssHubMap: Record<string, string> = {
   arxiv: "arxiv.org",
@@ -18,6 +21,20 @@ const rssHubMap: Record<string, string> = {
   sspai: "sspai.com",
 };
 
+/**
+ * Get icon URL for a subscribed feed.
+ * Prefers the icon cached by the backend, falling back to getFaviconUrl.
+ */
+export function getFeedIconUrl(
+  feed: Pick<Feed, "id" | "link" | "site_url" | "has_icon">,
+): string {
+  if (feed.has_icon) {
+    return `${API_BASE}/feeds/${feed.id}/icon`;
+  }
+
+  return getFaviconUrl(feed.link, feed.site_url);
+}
+
 /**
  * Get favicon URL for a feed.
  * Uses site_url if available, otherwise extracts domain from feed link.
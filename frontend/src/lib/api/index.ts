@@ -1,11 +1,16 @@
-import { api } from "./client";
+import { API_BASE, api } from "./client";
 import type {
   APIResponse,
   ListAPIResponse,
   LoginRequest,
   Group,
   Feed,
+  FeedLinkChange,
+  FeedFetchLog,
+  FeedHealth,
   Item,
+  ItemReadable,
+  ItemRevision,
   Bookmark,
   CreateGroupRequest,
   UpdateGroupRequest,
@@ -13,8 +18,16 @@ import type {
   UpdateFeedRequest,
   ValidateFeedRequest,
   ValidateFeedResponse,
+  ScrapePreviewRequest,
+  ScrapePreviewResponse,
+  Rule,
+  CreateRuleRequest,
+  UpdateRuleRequest,
+  DryRunRuleRequest,
+  DryRunRuleResponse,
   CreateBookmarkRequest,
   MarkItemsReadRequest,
+  UpdatePlaybackRequest,
   ListItemsParams,
   ListBookmarksParams,
   BatchCreateFeedsRequest,
@@ -22,6 +35,8 @@ import type {
   SearchResponse,
   OIDCStatusResponse,
   OIDCLoginResponse,
+  PullStatus,
+  Job,
 } from "./types";
 
 // Session APIs
@@ -68,10 +83,21 @@ export const feedAPI = {
 
   delete: (id: number) => api.delete<void>(`/feeds/${id}`),
 
+  links: (id: number) =>
+    api.get<APIResponse<FeedLinkChange[]>>(`/feeds/${id}/links`),
+
+  history: (id: number) =>
+    api.get<APIResponse<FeedFetchLog[]>>(`/feeds/${id}/history`),
+
+  health: () => api.get<ListAPIResponse<FeedHealth>>("/feeds/health"),
+
   validate: (data: ValidateFeedRequest) =>
     api.post<APIResponse<ValidateFeedResponse>>("/feeds/validate", data),
 
-  refresh: () => api.post<void>("/feeds/refresh"),
+  scrapePreview: (data: ScrapePreviewRequest) =>
+    api.post<APIResponse<ScrapePreviewResponse>>("/feeds/scrape/preview", data),
+
+  refresh: () => api.post<APIResponse<Job>>("/feeds/refresh"),
 
   batchCreate: (data: BatchCreateFeedsRequest) =>
     api.post<APIResponse<BatchCreateFeedsResponse>>("/feeds/batch", data),
@@ -88,6 +114,7 @@ export const itemAPI = {
     if (params?.limit) query.set("limit", params.limit.toString());
     if (params?.before) query.set("before", params.before);
     if (params?.order_by) query.set("order_by", params.order_by);
+    if (params?.media) query.set("media", params.media);
 
     const queryString = query.toString();
     return api.get<ListAPIResponse<Item>>(
@@ -97,11 +124,20 @@ export const itemAPI = {
 
   get: (id: number) => api.get<APIResponse<Item>>(`/items/${id}`),
 
+  readable: (id: number) =>
+    api.get<APIResponse<ItemReadable>>(`/items/${id}/readable`),
+
+  revisions: (id: number) =>
+    api.get<APIResponse<ItemRevision[]>>(`/items/${id}/revisions`),
+
   markRead: (data: MarkItemsReadRequest) =>
     api.patch<void>("/items/-/read", data),
 
   markUnread: (data: MarkItemsReadRequest) =>
     api.patch<void>("/items/-/unread", data),
+
+  updatePlayback: (id: number, data: UpdatePlaybackRequest) =>
+    api.put<void>(`/items/${id}/playback`, data),
 };
 
 // Bookmark APIs
@@ -123,6 +159,24 @@ export const bookmarkAPI = {
   delete: (id: number) => api.delete<void>(`/bookmarks/${id}`),
 };
 
+// Rule APIs
+export const ruleAPI = {
+  list: () => api.get<ListAPIResponse<Rule>>("/rules"),
+
+  get: (id: number) => api.get<APIResponse<Rule>>(`/rules/${id}`),
+
+  create: (data: CreateRuleRequest) =>
+    api.post<APIResponse<Rule>>("/rules", data),
+
+  update: (id: number, data: UpdateRuleRequest) =>
+    api.patch<APIResponse<Rule>>(`/rules/${id}`, data),
+
+  delete: (id: number) => api.delete<void>(`/rules/${id}`),
+
+  dryRun: (data: DryRunRuleRequest) =>
+    api.post<APIResponse<DryRunRuleResponse>>("/rules/dry-run", data),
+};
+
 // Search APIs
 export const searchAPI = {
   search: (q: string, limit = 10) =>
@@ -131,5 +185,23 @@ export const searchAPI = {
     ),
 };
 
+// Event stream (Server-Sent Events); listen per event type, e.g.
+// source.addEventListener("pull.failed", ...). EventSource reconnects on its own.
+export const eventsAPI = {
+  open: () => new EventSource(`${API_BASE}/events`, { withCredentials: true }),
+};
+
+// Background job APIs
+export const jobAPI = {
+  list: () => api.get<ListAPIResponse<Job>>("/jobs"),
+  get: (id: number) => api.get<APIResponse<Job>>(`/jobs/${id}`),
+  cancel: (id: number) => api.delete<void>(`/jobs/${id}`),
+};
+
+// Admin APIs
+export const adminAPI = {
+  pullStatus: () => api.get<APIResponse<PullStatus>>("/admin/pull-status"),
+};
+
 export * from "./types";
 export { APIError, setUnauthorizedCallback } from "./client";
@@ -14,11 +14,35 @@ export interface Feed {
   site_url?: string;
   suspended: boolean;
   proxy?: string;
+  user_agent?: string;
+  kind: FeedKind;
+  scrape?: ScrapeRecipe;
   created_at: number;
   updated_at: number;
+  retention_days: number;
+  retention_max_items: number;
+  pull_interval: number;
+  max_backoff: number;
+  full_text: boolean;
+  update_mode: ItemUpdateMode;
+  has_auth: boolean;
   fetch_state: FeedFetchState;
   unread_count: number;
   item_count: number;
+  has_icon: boolean;
+  websub_active: boolean;
+}
+
+export type FeedKind = "feed" | "scrape";
+
+// CSS selectors inside each item element; "sel@attr" reads an attribute.
+export interface ScrapeRecipe {
+  item: string;
+  title: string;
+  link?: string;
+  date?: string;
+  content?: string;
+  date_layout?: string;
 }
 
 export interface FeedFetchState {
@@ -34,6 +58,112 @@ export interface FeedFetchState {
   last_error_at: number;
   last_error?: string;
   consecutive_failures: number;
+  adaptive_interval: number;
+  publish_interval: number;
+  not_modified_ratio: number;
+  redirect_url?: string;
+  redirect_count: number;
+  suspend_reason?: string;
+}
+
+export interface FeedLinkChange {
+  id: number;
+  feed_id: number;
+  old_link: string;
+  new_link: string;
+  created_at: number;
+}
+
+export type FetchErrorClass =
+  | ""
+  | "timeout"
+  | "network"
+  | "http"
+  | "parse"
+  | "internal";
+
+export interface FeedFetchLog {
+  id: number;
+  feed_id: number;
+  fetched_at: number;
+  duration_ms: number;
+  http_status: number;
+  bytes: number;
+  new_items: number;
+  error_class: FetchErrorClass;
+  error?: string;
+}
+
+export interface FeedHealth {
+  feed_id: number;
+  name: string;
+  attempts: number;
+  successes: number;
+  avg_duration_ms: number;
+  bytes: number;
+  success_rate: number;
+  items_per_day: number;
+  last_new_item_at: number;
+  last_fetch_at: number;
+  last_error_class: FetchErrorClass;
+}
+
+export type EventType =
+  | "pull.started"
+  | "pull.not_modified"
+  | "pull.succeeded"
+  | "pull.failed"
+  | "refresh_all.progress"
+  | "items.created"
+  | "unread.counts";
+
+export interface PullEvent {
+  feed_id: number;
+  feed_name: string;
+  http_status?: number;
+  new_items?: number;
+  error_class?: FetchErrorClass;
+  error?: string;
+}
+
+export interface RefreshProgressEvent {
+  done: number;
+  total: number;
+  failed: number;
+  skipped: number;
+}
+
+export interface ItemsCreatedEvent {
+  feed_id: number;
+  count: number;
+  source: "pull" | "websub";
+}
+
+export interface FeedUnreadCount {
+  feed_id: number;
+  unread_count: number;
+}
+
+export interface UnreadCountsEvent {
+  total: number;
+  feeds: FeedUnreadCount[];
+}
+
+export interface PullHostStatus {
+  host: string;
+  in_flight: number;
+  waiting: number;
+  requests: number;
+  throttled: number;
+  deferred: number;
+  last_request_at: number;
+  blocked_until: number;
+}
+
+export interface PullStatus {
+  host_concurrency: number;
+  host_delay: number;
+  hosts: PullHostStatus[];
 }
 
 export interface Item {
@@ -43,11 +173,44 @@ export interface Item {
   title: string;
   link: string;
   content: string;
+  author: string;
+  summary: string;
+  categories: string[];
+  enclosures: Enclosure[];
+  duration: number;
+  episode: number;
+  image_url: string;
+  readable_content: string;
+  playback_position: number;
   pub_date: number;
   unread: boolean;
+  // Set when a move rule placed the item in another group.
+  group_id?: number;
+  created_at: number;
+}
+
+export interface Enclosure {
+  url: string;
+  type: string;
+  length: number;
+}
+
+export type ItemUpdateMode = "ignore" | "update" | "update_unread";
+
+export interface ItemRevision {
+  id: number;
+  item_id: number;
+  title: string;
+  link: string;
+  content: string;
   created_at: number;
 }
 
+export interface ItemReadable {
+  item_id: number;
+  content: string;
+}
+
 export interface Bookmark {
   id: number;
   item_id: number | null;
@@ -92,6 +255,18 @@ export interface CreateFeedRequest {
   link: string;
   site_url?: string;
   proxy?: string;
+  user_agent?: string;
+  auth?: FeedAuth;
+  scrape?: ScrapeRecipe;
+}
+
+// Write-only; feeds only report has_auth.
+export interface FeedAuth {
+  username?: string;
+  password?: string;
+  token?: string;
+  cookie?: string;
+  headers?: Record<string, string>;
 }
 
 export interface UpdateFeedRequest {
@@ -101,21 +276,129 @@ export interface UpdateFeedRequest {
   site_url?: string;
   suspended?: boolean;
   proxy?: string;
+  user_agent?: string;
+  retention_days?: number;
+  retention_max_items?: number;
+  pull_interval?: number;
+  max_backoff?: number;
+  full_text?: boolean;
+  update_mode?: ItemUpdateMode;
+  auth?: FeedAuth;
+  scrape?: ScrapeRecipe;
 }
 
 export interface ValidateFeedRequest {
   url: string;
+  auth?: FeedAuth;
+  user_agent?: string;
 }
 
+export type DiscoveredFeedType =
+  | "youtube_channel"
+  | "youtube_playlist"
+  | "youtube_user"
+  | "reddit_subreddit"
+  | "reddit_user"
+  | "github_releases"
+  | "github_commits"
+  | "github_tags"
+  | "fediverse_profile"
+  | "medium_user"
+  | "medium_publication"
+  | "medium_tag"
+  | "substack";
+
 export interface DiscoveredFeed {
   title: string;
   link: string;
+  // Set when a service rule (YouTube, GitHub, ...) produced the feed.
+  type?: DiscoveredFeedType;
 }
 
 export interface ValidateFeedResponse {
   feeds: DiscoveredFeed[];
 }
 
+export interface ScrapePreviewRequest {
+  url: string;
+  scrape: ScrapeRecipe;
+  auth?: FeedAuth;
+  user_agent?: string;
+}
+
+export interface ScrapePreviewItem {
+  title: string;
+  link: string;
+  content: string;
+  pub_date: number;
+}
+
+export interface ScrapePreviewResponse {
+  site_url: string;
+  items: ScrapePreviewItem[];
+}
+
+export type RuleField = "title" | "content" | "link" | "author" | "any";
+export type RuleMatchType = "contains" | "regex";
+export type RuleAction = "drop" | "read" | "bookmark" | "move";
+
+export interface Rule {
+  id: number;
+  name: string;
+  feed_id?: number;
+  group_id?: number;
+  field: RuleField;
+  match_type: RuleMatchType;
+  pattern: string;
+  action: RuleAction;
+  target_group_id?: number;
+  enabled: boolean;
+  hit_count: number;
+  last_hit_at: number;
+  created_at: number;
+  updated_at: number;
+}
+
+export interface CreateRuleRequest {
+  name: string;
+  feed_id?: number;
+  group_id?: number;
+  field: RuleField;
+  match_type: RuleMatchType;
+  pattern: string;
+  action: RuleAction;
+  target_group_id?: number;
+  enabled?: boolean;
+}
+
+export type UpdateRuleRequest = Partial<CreateRuleRequest>;
+
+export interface DryRunRuleRequest {
+  rule_id?: number;
+  feed_id?: number;
+  group_id?: number;
+  field?: RuleField;
+  match_type?: RuleMatchType;
+  pattern?: string;
+  action?: RuleAction;
+  target_group_id?: number;
+  limit?: number;
+}
+
+export interface DryRunRuleMatch {
+  id: number;
+  feed_id: number;
+  title: string;
+  link: string;
+  pub_date: number;
+}
+
+export interface DryRunRuleResponse {
+  scanned: number;
+  matched: number;
+  items: DryRunRuleMatch[];
+}
+
 export interface CreateBookmarkRequest {
   item_id?: number;
   link: string;
@@ -129,6 +412,10 @@ export interface MarkItemsReadRequest {
   ids: number[];
 }
 
+export interface UpdatePlaybackRequest {
+  position: number;
+}
+
 export interface ListItemsParams {
   feed_id?: number;
   group_id?: number;
@@ -136,6 +423,7 @@ export interface ListItemsParams {
   limit?: number;
   before?: string;
   order_by?: string;
+  media?: "audio" | "video" | "any";
 }
 
 export interface ListBookmarksParams {
@@ -177,6 +465,31 @@ export interface BatchCreateFeedsResponse {
   created: number;
   failed: number;
   errors?: string[];
+  job_id?: number;
+}
+
+// Background jobs
+export type JobKind = "refresh_all" | "import" | "initial_pull";
+
+export type JobState =
+  | "queued"
+  | "running"
+  | "succeeded"
+  | "failed"
+  | "cancelled";
+
+export interface Job {
+  id: number;
+  kind: JobKind;
+  state: JobState;
+  total: number;
+  done: number;
+  failed: number;
+  errors: string[];
+  error?: string;
+  created_at: number;
+  started_at: number;
+  finished_at: number;
 }
 
 // OIDC
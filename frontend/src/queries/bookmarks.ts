@@ -130,6 +130,15 @@ export function useStarredItems(
         title: bookmark.title,
         link: bookmark.link,
         content: bookmark.content,
+        author: "",
+        summary: "",
+        categories: [],
+        enclosures: [],
+        duration: 0,
+        episode: 0,
+        image_url: "",
+        readable_content: "",
+        playback_position: 0,
         pub_date: bookmark.pub_date,
         unread: bookmark.unread,
         created_at: bookmark.created_at,